@@ -22,6 +22,7 @@ const STATUS_CONFIG: Record<string, { label: string; dot: string; badge: string
   unreachable: { label: "Unreachable", dot: "bg-status-failed", badge: "text-status-failed bg-status-failed/10 border-status-failed/20" },
   // SSL
   valid: { label: "Valid", dot: "bg-status-healthy", badge: "text-status-healthy bg-status-healthy/10 border-status-healthy/20" },
+  none: { label: "No TLS", dot: "bg-status-idle", badge: "text-status-idle bg-status-idle/10 border-status-idle/20" },
   // ArgoCD
   Synced: { label: "Synced", dot: "bg-status-healthy", badge: "text-status-healthy bg-status-healthy/10 border-status-healthy/20" },
   OutOfSync: { label: "Out of Sync", dot: "bg-status-degraded", badge: "text-status-degraded bg-status-degraded/10 border-status-degraded/20" },
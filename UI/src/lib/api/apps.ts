@@ -6,7 +6,7 @@ import {
   fetchBuildLogs,
   fetchRuntimeLogs,
 } from "../mock/apps";
-import { App, Build, Deployment } from "../schemas";
+import { App, Build, Deployment, Promotion } from "../schemas";
 import { api } from "./client";
 import { MOCK_MODE } from "./index";
 
@@ -72,3 +72,50 @@ export async function triggerRedeploy(id: string): Promise<void> {
   }
   await api.post(`/api/apps/${id}/redeploy`, {});
 }
+
+export async function rollbackApp(id: string, revision?: number): Promise<void> {
+  if (MOCK_MODE) {
+    return new Promise((resolve) => setTimeout(resolve, 800));
+  }
+  await api.post(`/api/apps/${id}/rollback`, revision ? { revision } : {});
+}
+
+export async function promoteRollout(id: string): Promise<void> {
+  if (MOCK_MODE) {
+    return new Promise((resolve) => setTimeout(resolve, 800));
+  }
+  await api.post(`/api/apps/${id}/rollout/promote`, {});
+}
+
+export async function abortRollout(id: string): Promise<void> {
+  if (MOCK_MODE) {
+    return new Promise((resolve) => setTimeout(resolve, 800));
+  }
+  await api.post(`/api/apps/${id}/rollout/abort`, {});
+}
+
+export async function getPromotions(id: string): Promise<Promotion[]> {
+  if (MOCK_MODE) return [];
+  return api.get<Promotion[]>(`/api/apps/${id}/promotions`);
+}
+
+export async function promoteApp(id: string, revision?: number): Promise<Promotion> {
+  if (MOCK_MODE) {
+    throw new Error("promoteApp not available in mock mode");
+  }
+  return api.post<Promotion>(`/api/apps/${id}/promote`, revision ? { revision } : {});
+}
+
+export async function approvePromotion(id: string, promotionId: string): Promise<void> {
+  if (MOCK_MODE) {
+    return new Promise((resolve) => setTimeout(resolve, 800));
+  }
+  await api.post(`/api/apps/${id}/promotions/${promotionId}/approve`, {});
+}
+
+export async function rejectPromotion(id: string, promotionId: string): Promise<void> {
+  if (MOCK_MODE) {
+    return new Promise((resolve) => setTimeout(resolve, 800));
+  }
+  await api.post(`/api/apps/${id}/promotions/${promotionId}/reject`, {});
+}
@@ -11,6 +11,7 @@ import {
   Integration,
   NotificationRule,
   TeamMember,
+  TeamRole,
 } from "../schemas";
 import { api } from "./client";
 import { MOCK_MODE } from "./index";
@@ -20,16 +21,79 @@ export async function getTeamMembers(): Promise<TeamMember[]> {
   return api.get<TeamMember[]>("/api/settings/team");
 }
 
+export interface InviteMemberInput {
+  email: string;
+  name?: string;
+  role: TeamRole;
+  /** Namespaces or "namespace/app" pairs; omit for access to every App. */
+  scopes?: string[];
+  password?: string;
+}
+
+/** The temporary password is only returned when none was supplied. */
+export type InvitedMember = TeamMember & { temporaryPassword?: string };
+
+export async function inviteTeamMember(input: InviteMemberInput): Promise<InvitedMember> {
+  if (MOCK_MODE) {
+    throw new Error("inviteTeamMember not available in mock mode");
+  }
+  return api.post<InvitedMember>("/api/settings/team", input);
+}
+
+export async function updateTeamMemberRole(id: string, role: TeamRole): Promise<TeamMember> {
+  if (MOCK_MODE) {
+    throw new Error("updateTeamMemberRole not available in mock mode");
+  }
+  return api.patch<TeamMember>(`/api/settings/team/${id}`, { role });
+}
+
+export async function removeTeamMember(id: string): Promise<void> {
+  if (MOCK_MODE) {
+    throw new Error("removeTeamMember not available in mock mode");
+  }
+  return api.delete(`/api/settings/team/${id}`);
+}
+
 export async function getGeneralSettings(): Promise<GeneralSettings> {
   if (MOCK_MODE) return fetchGeneralSettings();
   return api.get<GeneralSettings>("/api/settings/general");
 }
 
+/** Admins get every token; other members only their own personal tokens. */
 export async function getCredentials(): Promise<Credential[]> {
   if (MOCK_MODE) return fetchCredentials();
   return api.get<Credential[]>("/api/settings/credentials");
 }
 
+export interface CreateApiTokenInput {
+  name: string;
+  /** Defaults to the caller's role; cannot exceed it. */
+  role?: TeamRole;
+  /** Namespaces or "namespace/app" pairs; omit for access to every App. */
+  scopes?: string[];
+  /** RFC 3339 time; omit for a token that never expires. */
+  expiresAt?: string;
+  /** Service tokens act for no one and need an Admin; personal tokens act for the caller. */
+  service?: boolean;
+}
+
+/** The token itself is only returned here, never again. */
+export type CreatedApiToken = Credential & { token: string };
+
+export async function createApiToken(input: CreateApiTokenInput): Promise<CreatedApiToken> {
+  if (MOCK_MODE) {
+    throw new Error("createApiToken not available in mock mode");
+  }
+  return api.post<CreatedApiToken>("/api/settings/credentials", input);
+}
+
+export async function revokeApiToken(id: string): Promise<void> {
+  if (MOCK_MODE) {
+    throw new Error("revokeApiToken not available in mock mode");
+  }
+  return api.delete(`/api/settings/credentials/${id}`);
+}
+
 export async function getIntegrations(): Promise<Integration[]> {
   if (MOCK_MODE) return fetchIntegrations();
   return api.get<Integration[]>("/api/settings/integrations");
@@ -10,7 +10,7 @@ export type ArgoSyncStatus = z.infer<typeof ArgoSyncStatusSchema>;
 export const ArgoHealthStatusSchema = z.enum(["Healthy", "Progressing", "Degraded", "Suspended", "Missing", "Unknown"]);
 export type ArgoHealthStatus = z.infer<typeof ArgoHealthStatusSchema>;
 
-export const SslStatusSchema = z.enum(["valid", "pending", "failed"]);
+export const SslStatusSchema = z.enum(["valid", "pending", "failed", "none"]);
 export type SslStatus = z.infer<typeof SslStatusSchema>;
 
 export const DeploymentStatusSchema = z.enum(["success", "failed", "in_progress", "rolled_back"]);
@@ -33,6 +33,8 @@ export const DomainSchema = z.object({
   id: z.string(),
   domain: z.string(),
   sslStatus: SslStatusSchema,
+  expiresAt: z.string().optional(),
+  message: z.string().optional(),
 });
 export type Domain = z.infer<typeof DomainSchema>;
 
@@ -45,6 +47,21 @@ export const EnvVarSchema = z.object({
 });
 export type EnvVar = z.infer<typeof EnvVarSchema>;
 
+// ─── Rollout Schema ───────────────────────────────────────────────────────────
+export const RolloutSchema = z.object({
+  strategy: z.enum(["Canary", "BlueGreen"]),
+  phase: AppStatusSchema,
+  step: z.number(),
+  totalSteps: z.number(),
+  weight: z.number(),
+  paused: z.boolean(),
+  promoting: z.boolean(),
+  stableImage: z.string(),
+  newImage: z.string(),
+  message: z.string(),
+});
+export type Rollout = z.infer<typeof RolloutSchema>;
+
 // ─── App Schema ───────────────────────────────────────────────────────────────
 export const AppSchema = z.object({
   id: z.string(),
@@ -60,6 +77,7 @@ export const AppSchema = z.object({
   argoHealthStatus: ArgoHealthStatusSchema,
   domains: z.array(DomainSchema),
   envVars: z.array(EnvVarSchema),
+  rollout: RolloutSchema.optional(),
 });
 export type App = z.infer<typeof AppSchema>;
 
@@ -78,6 +96,32 @@ export const DeploymentSchema = z.object({
 });
 export type Deployment = z.infer<typeof DeploymentSchema>;
 
+// ─── Promotion Schema ─────────────────────────────────────────────────────────
+export const PromotionStatusSchema = z.enum([
+  "pending",
+  "pending_approval",
+  "promoted",
+  "rejected",
+  "failed",
+]);
+export type PromotionStatus = z.infer<typeof PromotionStatusSchema>;
+
+export const PromotionSchema = z.object({
+  id: z.string(),
+  appId: z.string(),
+  source: z.string().optional(),
+  revision: z.number().optional(),
+  image: z.string(),
+  commitSha: z.string().optional(),
+  status: PromotionStatusSchema,
+  requestedBy: z.string(),
+  decidedBy: z.string().optional(),
+  message: z.string().optional(),
+  createdAt: z.string(),
+  promotedAt: z.string().optional(),
+});
+export type Promotion = z.infer<typeof PromotionSchema>;
+
 // ─── Build Schema ─────────────────────────────────────────────────────────────
 export const BuildSchema = z.object({
   id: z.string(),
@@ -183,6 +227,7 @@ export const TeamMemberSchema = z.object({
   name: z.string(),
   email: z.string().email(),
   role: TeamRoleSchema,
+  scopes: z.array(z.string()).optional(),
   joinedAt: z.string(),
   avatarUrl: z.string().optional(),
 });
@@ -202,6 +247,12 @@ export const CredentialSchema = z.object({
   type: z.enum(["ssh_key", "registry_secret", "api_token"]),
   value: z.string(),
   createdAt: z.string(),
+  role: TeamRoleSchema.optional(),
+  scopes: z.array(z.string()).optional(),
+  owner: z.string().optional(),
+  expiresAt: z.string().optional(),
+  expired: z.boolean().optional(),
+  lastUsedAt: z.string().optional(),
 });
 export type Credential = z.infer<typeof CredentialSchema>;
 
@@ -98,7 +98,7 @@ export default function DomainsPage() {
               <div className="flex items-center gap-2 justify-between sm:justify-end">
                 <StatusBadge status={domain.sslStatus} />
                 <div className="flex items-center gap-1">
-                  {domain.sslStatus !== "valid" && (
+                  {domain.sslStatus !== "valid" && domain.sslStatus !== "none" && (
                     <Button variant="ghost" size="icon" className="h-7 w-7 rounded-lg" title="Retry SSL">
                       <RefreshCw className="h-3.5 w-3.5 text-muted-foreground" />
                     </Button>
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// API tokens let CI jobs and scripts call the API without a password. They
+// are stored as Secrets next to the users, holding only a hash of the token,
+// which is shown once when it is created.
+//
+// A personal token acts for the member who created it: it never gets more
+// than their current role and dies with them. A service token belongs to no
+// one and keeps its role until revoked or expired.
+const (
+	apiTokenLabel        = "platform.flowcd.io/api-token"
+	apiTokenOwnerLabel   = "platform.flowcd.io/api-token-owner"
+	apiTokenSecretType   = corev1.SecretType("platform.flowcd.io/api-token")
+	apiTokenSecretPrefix = "flowcd-api-token-"
+	apiTokenLastUsedAnn  = "platform.flowcd.io/last-used"
+
+	apiTokenNameKey    = "name"
+	apiTokenRoleKey    = "role"
+	apiTokenScopesKey  = "scopes"
+	apiTokenOwnerKey   = "owner"
+	apiTokenHashKey    = "token-hash"
+	apiTokenExpiresKey = "expires-at"
+
+	// apiTokenPrefix marks API tokens in the Authorization header, telling
+	// them apart from session JWTs.
+	apiTokenPrefix = "fcd_"
+	// apiTokenLastUsedInterval limits how often use is written back, so a
+	// busy CI job does not update its Secret on every request.
+	apiTokenLastUsedInterval = time.Minute
+)
+
+var (
+	errAPITokenInvalid  = errors.New("API token is invalid, expired or revoked")
+	errAPITokenNotFound = errors.New("API token not found")
+)
+
+type apiToken struct {
+	ID     string
+	Name   string
+	Role   Role
+	Scopes []string
+	// Owner is the email of the member a personal token acts for; empty for
+	// service tokens.
+	Owner      string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+}
+
+func (t *apiToken) expired() bool {
+	return !t.ExpiresAt.IsZero() && time.Now().After(t.ExpiresAt)
+}
+
+// apiTokens is nil until ConfigureAuth runs, which leaves API tokens refused.
+var apiTokens *apiTokenStore
+
+type apiTokenStore struct {
+	clientset kubernetes.Interface
+	namespace string
+}
+
+func newAPITokenStore(cs kubernetes.Interface) *apiTokenStore {
+	return &apiTokenStore{clientset: cs, namespace: newUserStore(cs).namespace}
+}
+
+// create stores tok and returns it with the token to hand to the client.
+func (s *apiTokenStore) create(ctx context.Context, tok apiToken) (*apiToken, string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("generate token id: %w", err)
+	}
+	id := hex.EncodeToString(b)
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	raw := apiTokenPrefix + id + "_" + secret
+	obj := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      apiTokenSecretPrefix + id,
+			Namespace: s.namespace,
+			Labels:    map[string]string{apiTokenLabel: "true"},
+		},
+		Type: apiTokenSecretType,
+		Data: map[string][]byte{
+			apiTokenNameKey:   []byte(tok.Name),
+			apiTokenRoleKey:   []byte(tok.Role),
+			apiTokenScopesKey: []byte(strings.Join(tok.Scopes, ",")),
+			apiTokenOwnerKey:  []byte(tok.Owner),
+			apiTokenHashKey:   tokenHash(raw),
+		},
+	}
+	if tok.Owner != "" {
+		obj.Labels[apiTokenOwnerLabel] = userID(tok.Owner)
+	}
+	if !tok.ExpiresAt.IsZero() {
+		obj.Data[apiTokenExpiresKey] = []byte(tok.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	created, err := s.clientset.CoreV1().Secrets(s.namespace).Create(ctx, obj, metav1.CreateOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	return apiTokenFromSecret(created), raw, nil
+}
+
+// list returns every API token, oldest first.
+func (s *apiTokenStore) list(ctx context.Context) ([]apiToken, error) {
+	return s.listMatching(ctx, apiTokenLabel+"=true")
+}
+
+// listForOwner returns the personal tokens of the member with email, oldest
+// first.
+func (s *apiTokenStore) listForOwner(ctx context.Context, email string) ([]apiToken, error) {
+	if email == "" {
+		return []apiToken{}, nil
+	}
+	tokens, err := s.listMatching(ctx, apiTokenLabel+"=true,"+apiTokenOwnerLabel+"="+userID(email))
+	if err != nil {
+		return nil, err
+	}
+	// The label holds a hash of the email; the stored owner is authoritative.
+	owned := tokens[:0]
+	for _, t := range tokens {
+		if normalizeEmail(t.Owner) == normalizeEmail(email) {
+			owned = append(owned, t)
+		}
+	}
+	return owned, nil
+}
+
+func (s *apiTokenStore) listMatching(ctx context.Context, selector string) ([]apiToken, error) {
+	secrets, err := s.clientset.CoreV1().Secrets(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]apiToken, 0, len(secrets.Items))
+	for i := range secrets.Items {
+		if secrets.Items[i].Type == apiTokenSecretType {
+			tokens = append(tokens, *apiTokenFromSecret(&secrets.Items[i]))
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].Name < tokens[j].Name
+	})
+	return tokens, nil
+}
+
+func (s *apiTokenStore) get(ctx context.Context, id string) (*apiToken, error) {
+	obj, err := s.clientset.CoreV1().Secrets(s.namespace).Get(ctx, apiTokenSecretPrefix+id, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || err == nil && obj.Type != apiTokenSecretType {
+		return nil, errAPITokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return apiTokenFromSecret(obj), nil
+}
+
+func (s *apiTokenStore) delete(ctx context.Context, id string) error {
+	secrets := s.clientset.CoreV1().Secrets(s.namespace)
+	obj, err := secrets.Get(ctx, apiTokenSecretPrefix+id, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || err == nil && obj.Type != apiTokenSecretType {
+		return errAPITokenNotFound
+	}
+	if err != nil {
+		return err
+	}
+	err = secrets.Delete(ctx, obj.Name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return errAPITokenNotFound
+	}
+	return err
+}
+
+// deleteForOwner revokes every personal token of the member with email.
+func (s *apiTokenStore) deleteForOwner(ctx context.Context, email string) error {
+	return s.clientset.CoreV1().Secrets(s.namespace).DeleteCollection(ctx, metav1.DeleteOptions{},
+		metav1.ListOptions{LabelSelector: apiTokenLabel + "=true," + apiTokenOwnerLabel + "=" + userID(email)})
+}
+
+// authenticate returns the stored token matching raw and records its use.
+func (s *apiTokenStore) authenticate(ctx context.Context, raw string) (*apiToken, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(raw, apiTokenPrefix), "_")
+	if !ok || id == "" || secret == "" {
+		return nil, errAPITokenInvalid
+	}
+	obj, err := s.clientset.CoreV1().Secrets(s.namespace).Get(ctx, apiTokenSecretPrefix+id, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || err == nil && obj.Type != apiTokenSecretType {
+		return nil, errAPITokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(obj.Data[apiTokenHashKey], tokenHash(raw)) != 1 {
+		return nil, errAPITokenInvalid
+	}
+	tok := apiTokenFromSecret(obj)
+	if tok.expired() {
+		return nil, errAPITokenInvalid
+	}
+	if time.Since(tok.LastUsedAt) >= apiTokenLastUsedInterval {
+		// Failing to record use should not fail the request.
+		if err := s.touch(ctx, obj.Name); err != nil {
+			log.Printf("api token %s: record last use: %v", id, err)
+		}
+	}
+	return tok, nil
+}
+
+func (s *apiTokenStore) touch(ctx context.Context, name string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{apiTokenLastUsedAnn: time.Now().UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.clientset.CoreV1().Secrets(s.namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func apiTokenFromSecret(obj *corev1.Secret) *apiToken {
+	role, ok := parseRole(string(obj.Data[apiTokenRoleKey]))
+	if !ok {
+		role = RoleViewer
+	}
+	var scopes []string
+	if s := string(obj.Data[apiTokenScopesKey]); s != "" {
+		scopes = strings.Split(s, ",")
+	}
+	tok := &apiToken{
+		ID:        strings.TrimPrefix(obj.Name, apiTokenSecretPrefix),
+		Name:      string(obj.Data[apiTokenNameKey]),
+		Role:      role,
+		Scopes:    scopes,
+		Owner:     string(obj.Data[apiTokenOwnerKey]),
+		CreatedAt: obj.CreationTimestamp.UTC(),
+	}
+	if v := obj.Data[apiTokenExpiresKey]; len(v) > 0 {
+		// An unreadable expiry makes the token unusable rather than eternal.
+		if tok.ExpiresAt, _ = time.Parse(time.RFC3339, string(v)); tok.ExpiresAt.IsZero() {
+			tok.ExpiresAt = time.Unix(0, 0)
+		}
+	}
+	if v := obj.Annotations[apiTokenLastUsedAnn]; v != "" {
+		tok.LastUsedAt, _ = time.Parse(time.RFC3339, v)
+	}
+	return tok
+}
+
+// apiTokenCaller authenticates an API token from the Authorization header and
+// returns the caller it acts as: the token's own role and scopes, further
+// capped for personal tokens by their owner's current access.
+func apiTokenCaller(ctx context.Context, raw string) (actor string, role Role, scopes []string, err error) {
+	if apiTokens == nil {
+		return "", "", nil, errAPITokenInvalid
+	}
+	tok, err := apiTokens.authenticate(ctx, raw)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if tok.Owner == "" {
+		return "api-token:" + tok.Name, tok.Role, tok.Scopes, nil
+	}
+	owner, err := newUserStore(apiTokens.clientset).byEmail(ctx, tok.Owner)
+	if errors.Is(err, errUserNotFound) {
+		return "", "", nil, errAPITokenInvalid
+	}
+	if err != nil {
+		return "", "", nil, err
+	}
+	if !scopesWithin(tok.Scopes, owner.Scopes) {
+		return "", "", nil, errAPITokenInvalid
+	}
+	role = tok.Role
+	if roleRank[owner.Role] < roleRank[role] {
+		role = owner.Role
+	}
+	return owner.Email, role, tok.Scopes, nil
+}
+
+// scopesWithin reports whether scopes grant nothing beyond allowed. No
+// scopes means every App, so only unrestricted callers may hand that out.
+func scopesWithin(scopes, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	if len(scopes) == 0 {
+		return false
+	}
+	for _, s := range scopes {
+		ns, name, _ := strings.Cut(s, "/")
+		if !inScope(allowed, ns, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func toCredentialResp(t *apiToken) CredentialResp {
+	resp := CredentialResp{
+		ID:        t.ID,
+		Name:      t.Name,
+		Type:      "api_token",
+		Value:     apiTokenPrefix + t.ID + "_…",
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+		Role:      string(t.Role),
+		Scopes:    t.Scopes,
+		Owner:     t.Owner,
+		Expired:   t.expired(),
+	}
+	if !t.ExpiresAt.IsZero() {
+		resp.ExpiresAt = t.ExpiresAt.Format(time.RFC3339)
+	}
+	if !t.LastUsedAt.IsZero() {
+		resp.LastUsedAt = t.LastUsedAt.Format(time.RFC3339)
+	}
+	return resp
+}
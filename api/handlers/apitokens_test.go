@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newAPITokenTestServer is newAuthTestServer with API tokens accepted and the
+// App routes mounted behind ValidateJWT.
+func newAPITokenTestServer(t *testing.T, cs kubernetes.Interface) http.Handler {
+	t.Helper()
+	auth := newAuthTestServer(t, cs)
+	saved := apiTokens
+	apiTokens = newAPITokenStore(cs)
+	t.Cleanup(func() { apiTokens = saved })
+
+	other := appWithHistory()
+	other.ObjectMeta = metav1.ObjectMeta{Name: "api", Namespace: "default"}
+	apps, _ := newAppsTestServer(t, cs, appWithHistory(), other)
+	mux := http.NewServeMux()
+	mux.Handle("/api/apps/", ValidateJWT(apps))
+	mux.Handle("/", auth)
+	return mux
+}
+
+// createAPIToken creates a token as the caller with token and returns it,
+// failing the test on any status but 201.
+func createAPIToken(t *testing.T, srv http.Handler, token, body string) APITokenResp {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/credentials", token, body))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create token status = %d, want 201 (body %s)", rec.Code, rec.Body.String())
+	}
+	var resp APITokenResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+func TestServiceAPIToken(t *testing.T) {
+	cs := k8sfake.NewClientset()
+	srv := newAPITokenTestServer(t, cs)
+	admin := login(t, srv, adminEmail, adminPassword).Token
+
+	ci := createAPIToken(t, srv, admin, `{"name":"ci","role":"Developer","scopes":["default/web"],"service":true}`)
+	if !strings.HasPrefix(ci.Token, apiTokenPrefix) || ci.Role != "Developer" || ci.Owner != "" {
+		t.Fatalf("token = %+v, want a Developer service token", ci)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/apps/web/redeploy", ci.Token, ""))
+	if rec.Code != http.StatusOK {
+		t.Errorf("in-scope redeploy status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/apps/api/redeploy", ci.Token, ""))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("out-of-scope redeploy status = %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/settings/credentials", ci.Token, ""))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("Developer token listing credentials = %d %s, want no tokens", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/credentials", ci.Token, `{"name":"more","service":true}`))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Developer token creating a service token status = %d, want 403", rec.Code)
+	}
+
+	var listed []CredentialResp
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/settings/credentials", admin, ""))
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(listed) != 1 || listed[0].LastUsedAt == "" || strings.Contains(rec.Body.String(), ci.Token) {
+		t.Errorf("credentials = %s, want the token with its last use and without its secret", rec.Body.String())
+	}
+	secret, err := cs.CoreV1().Secrets("flowcd").Get(context.Background(), apiTokenSecretPrefix+ci.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("token not stored: %v", err)
+	}
+	if strings.Contains(string(secret.Data[apiTokenHashKey]), ci.Token) {
+		t.Error("token stored in plaintext")
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodDelete, "/api/settings/credentials/"+ci.ID, admin, ""))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke status = %d, want 204 (body %s)", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/apps/web/redeploy", ci.Token, ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("redeploy with revoked token status = %d, want 401", rec.Code)
+	}
+}
+
+func TestPersonalAPIToken(t *testing.T) {
+	cs := k8sfake.NewClientset()
+	srv := newAPITokenTestServer(t, cs)
+	admin := login(t, srv, adminEmail, adminPassword).Token
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/team", admin, `{"email":"ops@example.com","role":"Admin"}`))
+	var ops InviteResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &ops); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("invite = %d %v (body %s)", rec.Code, err, rec.Body.String())
+	}
+	opsToken := login(t, srv, "ops@example.com", ops.TemporaryPassword).Token
+	personal := createAPIToken(t, srv, opsToken, `{"name":"laptop"}`)
+	if personal.Owner != "ops@example.com" || personal.Role != "Admin" {
+		t.Fatalf("token = %+v, want an Admin token owned by ops@example.com", personal)
+	}
+
+	// The token follows its owner down to Viewer.
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPatch, "/api/settings/team/"+ops.ID, admin, `{"role":"Viewer"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d (body %s)", rec.Code, rec.Body.String())
+	}
+	var me map[string]string
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/auth/me", personal.Token, ""))
+	_ = json.Unmarshal(rec.Body.Bytes(), &me)
+	if rec.Code != http.StatusOK || me["role"] != "Viewer" || me["email"] != "ops@example.com" {
+		t.Errorf("me = %d %v, want ops@example.com as Viewer", rec.Code, me)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodDelete, "/api/settings/team/"+ops.ID, admin, ""))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("remove status = %d (body %s)", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/auth/me", personal.Token, ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("me after owner removal status = %d, want 401", rec.Code)
+	}
+	// The fake clientset does not implement DeleteCollection, so check that
+	// it was asked to.
+	revoked := false
+	for _, a := range cs.Actions() {
+		if dc, ok := a.(k8stesting.DeleteCollectionAction); ok &&
+			dc.GetListRestrictions().Labels.String() == apiTokenLabel+"=true,"+apiTokenOwnerLabel+"="+ops.ID {
+			revoked = true
+		}
+	}
+	if !revoked {
+		t.Error("personal tokens not revoked with their owner")
+	}
+}
+
+func TestMemberManagesOwnAPITokens(t *testing.T) {
+	cs := k8sfake.NewClientset()
+	srv := newAPITokenTestServer(t, cs)
+	admin := login(t, srv, adminEmail, adminPassword).Token
+	adminTok := createAPIToken(t, srv, admin, `{"name":"admin-laptop"}`)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/team", admin, `{"email":"dev@example.com","role":"Developer","scopes":["default/web"]}`))
+	var dev InviteResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &dev); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("invite = %d %v (body %s)", rec.Code, err, rec.Body.String())
+	}
+	devToken := login(t, srv, "dev@example.com", dev.TemporaryPassword).Token
+
+	ci := createAPIToken(t, srv, devToken, `{"name":"ci","scopes":["default/web"]}`)
+	if ci.Owner != "dev@example.com" || ci.Role != "Developer" {
+		t.Fatalf("token = %+v, want a Developer token owned by dev@example.com", ci)
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/apps/web/redeploy", ci.Token, ""))
+	if rec.Code != http.StatusOK {
+		t.Errorf("redeploy with personal token status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/credentials", devToken, `{"name":"svc","service":true}`))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Developer creating a service token status = %d, want 403", rec.Code)
+	}
+
+	var listed []CredentialResp
+	getJSON(t, withToken(srv, devToken), "/api/settings/credentials", &listed)
+	if len(listed) != 1 || listed[0].ID != ci.ID {
+		t.Errorf("Developer sees %+v, want only their own token", listed)
+	}
+	getJSON(t, withToken(srv, admin), "/api/settings/credentials", &listed)
+	if len(listed) != 2 {
+		t.Errorf("Admin sees %d tokens, want 2", len(listed))
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodDelete, "/api/settings/credentials/"+adminTok.ID, devToken, ""))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Developer revoking another member's token status = %d, want 404", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodDelete, "/api/settings/credentials/"+ci.ID, devToken, ""))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Developer revoking their own token status = %d, want 204", rec.Code)
+	}
+}
+
+// withToken sends every request through srv with token.
+func withToken(srv http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+		srv.ServeHTTP(w, r)
+	})
+}
+
+func TestAPITokenCannotCreateTokens(t *testing.T) {
+	srv := newAPITokenTestServer(t, k8sfake.NewClientset())
+	admin := login(t, srv, adminEmail, adminPassword).Token
+	expiry := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	service := createAPIToken(t, srv, admin, `{"name":"deployer","service":true}`)
+	personal := createAPIToken(t, srv, admin, `{"name":"ci","expiresAt":"`+expiry+`"}`)
+
+	for _, tok := range []APITokenResp{service, personal} {
+		for _, body := range []string{`{"name":"forever"}`, `{"name":"forever","service":true}`} {
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/credentials", tok.Token, body))
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("%s token creating %s: status = %d, want 403", tok.Name, body, rec.Code)
+			}
+		}
+	}
+}
+
+func TestAPITokenExpiry(t *testing.T) {
+	cs := k8sfake.NewClientset()
+	srv := newAPITokenTestServer(t, cs)
+	admin := login(t, srv, adminEmail, adminPassword).Token
+
+	rec := httptest.NewRecorder()
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/credentials", admin, `{"name":"old","expiresAt":"`+past+`"}`))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("create with past expiry status = %d, want 400", rec.Code)
+	}
+
+	tok := createAPIToken(t, srv, admin, `{"name":"short","service":true,"expiresAt":"`+time.Now().Add(time.Hour).UTC().Format(time.RFC3339)+`"}`)
+	if tok.ExpiresAt == "" {
+		t.Errorf("token = %+v, want an expiry", tok)
+	}
+	secrets := cs.CoreV1().Secrets("flowcd")
+	secret, err := secrets.Get(context.Background(), apiTokenSecretPrefix+tok.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	secret.Data[apiTokenExpiresKey] = []byte(past)
+	if _, err := secrets.Update(context.Background(), secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/auth/me", tok.Token, ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("me with expired token status = %d, want 401", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/auth/me", apiTokenPrefix+tok.ID+"_forged", ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("me with forged token status = %d, want 401", rec.Code)
+	}
+}
+
+func TestScopesWithin(t *testing.T) {
+	tests := []struct {
+		scopes, allowed []string
+		want            bool
+	}{
+		{scopes: nil, allowed: nil, want: true},
+		{scopes: []string{"team-a/web"}, allowed: []string{"team-a"}, want: true},
+		{scopes: []string{"team-a"}, allowed: []string{"team-a/web"}, want: false},
+		{scopes: []string{"team-b"}, allowed: []string{"team-a"}, want: false},
+		{scopes: nil, allowed: []string{"team-a"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := scopesWithin(tt.scopes, tt.allowed); got != tt.want {
+			t.Errorf("scopesWithin(%v, %v) = %v, want %v", tt.scopes, tt.allowed, got, tt.want)
+		}
+	}
+}
@@ -3,28 +3,62 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type AppsHandler struct{ client client.Client }
+// Annotations shared with the operator's App controller.
+const (
+	redeployAtAnnotation  = "platform.flowcd.io/redeploy-at"
+	commitSHAAnnotation   = "platform.flowcd.io/commit-sha"
+	triggeredByAnnotation = "platform.flowcd.io/triggered-by"
+	rollbackToAnnotation  = "platform.flowcd.io/rollback-to"
 
-func NewAppsHandler(c client.Client) *AppsHandler { return &AppsHandler{client: c} }
+	promoteRequestedAnnotation = "platform.flowcd.io/promote-requested-at"
+	abortRequestedAnnotation   = "platform.flowcd.io/abort-requested-at"
+
+	// outOfSyncCondition is set when the operator had to correct children
+	// edited outside the App.
+	outOfSyncCondition = "OutOfSync"
+)
+
+type AppsHandler struct {
+	client    client.Client
+	clientset kubernetes.Interface
+}
+
+func NewAppsHandler(c client.Client, cs kubernetes.Interface) *AppsHandler {
+	return &AppsHandler{client: c, clientset: cs}
+}
 
 func (h *AppsHandler) Routes(r chi.Router) {
-	r.Get("/", h.list)
-	r.Post("/", h.create)
-	r.Get("/{id}", h.get)
-	r.Delete("/{id}", h.delete)
-	r.Post("/{id}/redeploy", h.redeploy)
-	r.Get("/{id}/deployments", h.deployments)
-	r.Get("/{id}/builds", h.builds)
-	r.Get("/{id}/logs", h.logs)
+	r.With(Authorize(PermAppsRead)).Get("/", h.list)
+	r.With(Authorize(PermAppsWrite)).Post("/", h.create)
+	r.With(authorizeApp(PermAppsRead)).Get("/{id}", h.get)
+	r.With(authorizeApp(PermAppsDelete)).Delete("/{id}", h.delete)
+	r.With(authorizeApp(PermAppsDeploy)).Post("/{id}/redeploy", h.redeploy)
+	r.With(authorizeApp(PermAppsRead)).Get("/{id}/deployments", h.deployments)
+	r.With(authorizeApp(PermAppsDeploy)).Post("/{id}/rollback", h.rollback)
+	r.With(authorizeApp(PermAppsDeploy)).Post("/{id}/rollout/promote", h.promoteRollout)
+	r.With(authorizeApp(PermAppsDeploy)).Post("/{id}/rollout/abort", h.abortRollout)
+	r.With(authorizeApp(PermAppsDeploy)).Post("/{id}/promote", h.promote)
+	r.With(authorizeApp(PermAppsRead)).Get("/{id}/promotions", h.promotions)
+	r.With(authorizeApp(PermPromotionsApprove)).Post("/{id}/promotions/{promotion}/approve", h.approvePromotion)
+	r.With(authorizeApp(PermPromotionsApprove)).Post("/{id}/promotions/{promotion}/reject", h.rejectPromotion)
+	r.With(authorizeApp(PermAppsRead)).Get("/{id}/builds", h.builds)
+	r.With(authorizeApp(PermAppsRead)).Get("/{id}/logs", h.logs)
 }
 
 func (h *AppsHandler) list(w http.ResponseWriter, r *http.Request) {
@@ -33,9 +67,12 @@ func (h *AppsHandler) list(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	scopes := callerScopes(r)
 	resp := make([]AppResp, 0, len(list.Items))
 	for _, a := range list.Items {
-		resp = append(resp, toAppResp(&a))
+		if inScope(scopes, a.Namespace, a.Name) {
+			resp = append(resp, toAppResp(&a))
+		}
 	}
 	jsonOK(w, resp)
 }
@@ -69,6 +106,9 @@ func (h *AppsHandler) create(w http.ResponseWriter, r *http.Request) {
 	if branch == "" {
 		branch = "main"
 	}
+	if !checkScope(w, r, PermAppsWrite, "default", body.Name) {
+		return
+	}
 	app := &k8stypes.App{}
 	app.Name = body.Name
 	app.Namespace = "default"
@@ -109,7 +149,7 @@ func (h *AppsHandler) redeploy(w http.ResponseWriter, r *http.Request) {
 	if app.Annotations == nil {
 		app.Annotations = map[string]string{}
 	}
-	app.Annotations["platform.flowcd.io/redeploy-at"] = time.Now().UTC().Format(time.RFC3339)
+	app.Annotations[redeployAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
 	if err := h.client.Patch(r.Context(), app, patch); err != nil {
 		jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -118,30 +158,177 @@ func (h *AppsHandler) redeploy(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AppsHandler) deployments(w http.ResponseWriter, r *http.Request) {
-	jsonOK(w, []interface{}{})
+	id := chi.URLParam(r, "id")
+	app, err := h.fetchApp(r.Context(), id)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	jsonOK(w, toDeploymentResps(app))
 }
 
-func (h *AppsHandler) builds(w http.ResponseWriter, r *http.Request) {
-	jsonOK(w, []interface{}{})
+// rollback re-applies a recorded revision's image, environment and replica
+// count. Without an explicit revision it restores the newest revision before
+// the current one that has not itself been rolled back.
+func (h *AppsHandler) rollback(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	app, err := h.fetchApp(r.Context(), id)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var body struct {
+		Revision int64 `json:"revision"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		jsonError(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	target := rollbackTarget(app, body.Revision)
+	if target == nil {
+		if body.Revision != 0 {
+			jsonError(w, fmt.Sprintf("revision %d not found", body.Revision), http.StatusNotFound)
+		} else {
+			jsonError(w, "no previous revision to roll back to", http.StatusConflict)
+		}
+		return
+	}
+	if target.Revision == app.Status.CurrentRevision {
+		jsonError(w, fmt.Sprintf("revision %d is already deployed", target.Revision), http.StatusConflict)
+		return
+	}
+
+	actor, _ := r.Context().Value(contextKeyEmail).(string)
+	patch := client.MergeFrom(app.DeepCopy())
+	app.Spec.Image = target.Image
+	app.Spec.Env = target.Env
+	if target.Replicas > 0 {
+		replicas := target.Replicas
+		app.Spec.Replicas = &replicas
+	}
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	app.Annotations[commitSHAAnnotation] = target.CommitSHA
+	app.Annotations[triggeredByAnnotation] = actor
+	app.Annotations[rollbackToAnnotation] = strconv.FormatInt(target.Revision, 10)
+	if err := h.client.Patch(r.Context(), app, patch); err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonOK(w, map[string]any{"status": "rollback triggered", "revision": target.Revision})
+}
+
+// promoteRollout moves a Canary rollout on to its next step, or switches a
+// BlueGreen rollout's traffic to the preview, without waiting for the step's
+// pause to elapse.
+func (h *AppsHandler) promoteRollout(w http.ResponseWriter, r *http.Request) {
+	h.requestRollout(w, r, promoteRequestedAnnotation, "promotion requested")
 }
 
-func (h *AppsHandler) logs(w http.ResponseWriter, r *http.Request) {
-	jsonOK(w, []string{})
+// abortRollout stops the current rollout; the stable version keeps serving
+// and the aborted image is not retried until spec.image changes.
+func (h *AppsHandler) abortRollout(w http.ResponseWriter, r *http.Request) {
+	h.requestRollout(w, r, abortRequestedAnnotation, "abort requested")
+}
+
+// requestRollout records a promote or abort request for the operator by
+// stamping annotation with the current time.
+func (h *AppsHandler) requestRollout(w http.ResponseWriter, r *http.Request, annotation, status string) {
+	id := chi.URLParam(r, "id")
+	app, err := h.fetchApp(r.Context(), id)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	ro := app.Status.Rollout
+	if ro == nil || ro.NewImage == "" {
+		jsonError(w, "no rollout in progress", http.StatusConflict)
+		return
+	}
+	if annotation == promoteRequestedAnnotation && ro.Promoting {
+		jsonError(w, "rollout is already being promoted", http.StatusConflict)
+		return
+	}
+
+	patch := client.MergeFrom(app.DeepCopy())
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	app.Annotations[annotation] = time.Now().UTC().Format(time.RFC3339Nano)
+	if err := h.client.Patch(r.Context(), app, patch); err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonOK(w, map[string]string{"status": status})
+}
+
+// rollbackTarget returns the revision to restore, or nil when there is none.
+func rollbackTarget(a *k8stypes.App, revision int64) *k8stypes.AppRevision {
+	history := a.Status.History
+	for i := len(history) - 1; i >= 0; i-- {
+		rev := &history[i]
+		if revision != 0 {
+			if rev.Revision == revision {
+				return rev
+			}
+			continue
+		}
+		if rev.Revision < a.Status.CurrentRevision && rev.Status != k8stypes.AppRevisionRolledBack {
+			return rev
+		}
+	}
+	return nil
+}
+
+// builds lists the App's pipeline runs, newest first. Only the newest build
+// carries its logs; older ones are fetched through /api/builds/{id}/logs.
+func (h *AppsHandler) builds(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	app, err := h.fetchApp(r.Context(), id)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	runs, err := appRuns(r.Context(), h.client, app)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := make([]BuildResp, 0, len(runs))
+	for i := range runs {
+		b := toBuildResp(app, &runs[i])
+		if i == 0 {
+			logs, err := buildLogs(r.Context(), h.clientset, &runs[i])
+			if err != nil {
+				logs = []string{"failed to fetch build logs: " + err.Error()}
+			}
+			b.Logs = logs
+		}
+		resp = append(resp, b)
+	}
+	jsonOK(w, resp)
 }
 
 func (h *AppsHandler) fetchApp(ctx context.Context, nameOrNSN string) (*k8stypes.App, error) {
 	app := &k8stypes.App{}
 	// Support "namespace/name" or plain "name" (defaults to "default").
-	ns, name := "default", nameOrNSN
-	if parts := strings.SplitN(nameOrNSN, "/", 2); len(parts) == 2 {
-		ns, name = parts[0], parts[1]
-	}
+	ns, name := splitAppID(nameOrNSN)
 	if err := h.client.Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, app); err != nil {
 		return nil, err
 	}
 	return app, nil
 }
 
+// appNamespace returns the namespace the App's workload runs in.
+func appNamespace(a *k8stypes.App) string {
+	if a.Spec.Destination != nil && a.Spec.Destination.Namespace != "" {
+		return a.Spec.Destination.Namespace
+	}
+	return a.Namespace
+}
+
 // ─── mapping ─────────────────────────────────────────────────────────────────
 
 func toAppResp(a *k8stypes.App) AppResp {
@@ -155,7 +342,7 @@ func toAppResp(a *k8stypes.App) AppResp {
 		LastBuildAt:      timeOrZero((*time.Time)(nil)),
 		URL:              a.Status.URL,
 		ImageTag:         a.Status.ImageTag,
-		ArgoSyncStatus:   phaseToArgoSync(a.Status.Phase),
+		ArgoSyncStatus:   argoSyncStatus(a),
 		ArgoHealthStatus: phaseToArgoHealth(a.Status.Phase),
 		Domains:          make([]DomainResp, 0),
 		EnvVars:          make([]EnvVarResp, 0),
@@ -168,12 +355,9 @@ func toAppResp(a *k8stypes.App) AppResp {
 	if resp.ID == "" {
 		resp.ID = a.Name
 	}
+	resp.Rollout = toRolloutResp(a)
 	for _, d := range a.Spec.Domains {
-		resp.Domains = append(resp.Domains, DomainResp{
-			ID:        d,
-			Domain:    d,
-			SslStatus: "pending",
-		})
+		resp.Domains = append(resp.Domains, toDomainResp(a, d))
 	}
 	for i, e := range a.Spec.Env {
 		resp.EnvVars = append(resp.EnvVars, EnvVarResp{
@@ -185,26 +369,143 @@ func toAppResp(a *k8stypes.App) AppResp {
 	return resp
 }
 
+// toRolloutResp describes the App's Canary or BlueGreen rollout, or returns
+// nil when the App rolls out in place.
+func toRolloutResp(a *k8stypes.App) *RolloutResp {
+	s := a.Spec.Strategy
+	if s == nil || s.Type == "" || s.Type == k8stypes.AppStrategyRollingUpdate {
+		return nil
+	}
+	resp := &RolloutResp{Strategy: string(s.Type), Phase: phaseToStatus(a.Status.Phase)}
+	if s.Type == k8stypes.AppStrategyCanary && s.Canary != nil {
+		resp.TotalSteps = int32(len(s.Canary.Steps))
+	}
+	if ro := a.Status.Rollout; ro != nil {
+		resp.Weight = ro.Weight
+		resp.Paused = ro.Paused
+		resp.Promoting = ro.Promoting
+		resp.StableImage = ro.StableImage
+		resp.NewImage = ro.NewImage
+		resp.Message = ro.Message
+		if ro.NewImage != "" && resp.TotalSteps > 0 {
+			resp.Step = ro.CurrentStep + 1
+		}
+	}
+	return resp
+}
+
+// toDeploymentResps lists an App's rollouts newest first, starting with the
+// rollout in progress, if any.
+func toDeploymentResps(a *k8stypes.App) []DeploymentResp {
+	appID := string(a.UID)
+	if appID == "" {
+		appID = a.Name
+	}
+	resp := make([]DeploymentResp, 0, len(a.Status.History)+1)
+	if a.Status.RolloutStartedAt != nil && a.Spec.Image != "" {
+		status := "in_progress"
+		if a.Status.Phase == k8stypes.AppPhaseFailed || a.Status.Phase == k8stypes.AppPhaseAborted {
+			status = "failed"
+		}
+		revision := a.Status.CurrentRevision + 1
+		resp = append(resp, DeploymentResp{
+			ID:          fmt.Sprintf("%s-%d", a.Name, revision),
+			AppID:       appID,
+			Version:     fmt.Sprintf("v%d", revision),
+			CommitSha:   a.Annotations[commitSHAAnnotation],
+			Status:      status,
+			TriggeredBy: a.Annotations[triggeredByAnnotation],
+			DeployedAt:  a.Status.RolloutStartedAt.UTC().Format(time.RFC3339),
+			Duration:    time.Since(a.Status.RolloutStartedAt.Time).Seconds(),
+			ImageTag:    imageTag(a.Spec.Image),
+		})
+	}
+	for i := len(a.Status.History) - 1; i >= 0; i-- {
+		rev := a.Status.History[i]
+		status := "success"
+		if rev.Status == k8stypes.AppRevisionRolledBack {
+			status = "rolled_back"
+		}
+		resp = append(resp, DeploymentResp{
+			ID:          fmt.Sprintf("%s-%d", a.Name, rev.Revision),
+			AppID:       appID,
+			Version:     fmt.Sprintf("v%d", rev.Revision),
+			CommitSha:   rev.CommitSHA,
+			Status:      status,
+			TriggeredBy: rev.DeployedBy,
+			DeployedAt:  rev.DeployedAt.UTC().Format(time.RFC3339),
+			Duration:    float64(rev.Duration),
+			ImageTag:    imageTag(rev.Image),
+		})
+	}
+	return resp
+}
+
+// imageTag extracts the tag portion of an image reference.
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
+
+// toDomainResp reports the certificate state the operator observed for
+// domain. Domains it has not checked yet are pending unless the App has no
+// TLS at all.
+func toDomainResp(a *k8stypes.App, domain string) DomainResp {
+	resp := DomainResp{ID: domain, Domain: domain, SslStatus: "pending"}
+	if a.Spec.TLS == nil {
+		resp.SslStatus = "none"
+	}
+	for _, st := range a.Status.Domains {
+		if st.Domain != domain {
+			continue
+		}
+		resp.SslStatus = strings.ToLower(string(st.SSLStatus))
+		resp.Message = st.Message
+		if st.ExpiresAt != nil {
+			resp.ExpiresAt = st.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return resp
+}
+
 func phaseToStatus(phase k8stypes.AppPhase) string {
 	switch phase {
 	case k8stypes.AppPhaseHealthy:
 		return "healthy"
 	case k8stypes.AppPhaseBuilding:
 		return "building"
-	case k8stypes.AppPhaseDeploying:
+	case k8stypes.AppPhaseDeploying, k8stypes.AppPhaseCanary, k8stypes.AppPhasePreview,
+		k8stypes.AppPhasePaused, k8stypes.AppPhasePromoting:
 		return "deploying"
-	case k8stypes.AppPhaseDegraded, k8stypes.AppPhaseFailed:
+	case k8stypes.AppPhaseDegraded, k8stypes.AppPhaseFailed, k8stypes.AppPhaseAborted:
 		return "degraded"
 	default:
 		return "idle"
 	}
 }
 
+// argoSyncStatus reports the operator's OutOfSync condition, falling back to
+// the phase for Apps the operator has not synced yet.
+func argoSyncStatus(a *k8stypes.App) string {
+	if cond := meta.FindStatusCondition(a.Status.Conditions, outOfSyncCondition); cond != nil {
+		switch cond.Status {
+		case metav1.ConditionTrue:
+			return "OutOfSync"
+		case metav1.ConditionFalse:
+			return "Synced"
+		}
+	}
+	return phaseToArgoSync(a.Status.Phase)
+}
+
 func phaseToArgoSync(phase k8stypes.AppPhase) string {
 	switch phase {
 	case k8stypes.AppPhaseHealthy:
 		return "Synced"
-	case k8stypes.AppPhaseDeploying:
+	case k8stypes.AppPhaseDeploying, k8stypes.AppPhaseCanary, k8stypes.AppPhasePreview,
+		k8stypes.AppPhasePaused, k8stypes.AppPhasePromoting, k8stypes.AppPhaseSleeping:
 		return "Synced"
 	default:
 		return "Unknown"
@@ -215,11 +516,14 @@ func phaseToArgoHealth(phase k8stypes.AppPhase) string {
 	switch phase {
 	case k8stypes.AppPhaseHealthy:
 		return "Healthy"
-	case k8stypes.AppPhaseDeploying:
+	case k8stypes.AppPhaseDeploying, k8stypes.AppPhaseCanary, k8stypes.AppPhasePreview,
+		k8stypes.AppPhasePromoting:
 		return "Progressing"
-	case k8stypes.AppPhaseDegraded, k8stypes.AppPhaseFailed:
+	case k8stypes.AppPhasePaused:
+		return "Suspended"
+	case k8stypes.AppPhaseDegraded, k8stypes.AppPhaseFailed, k8stypes.AppPhaseAborted:
 		return "Degraded"
-	case k8stypes.AppPhaseSuspended:
+	case k8stypes.AppPhaseSuspended, k8stypes.AppPhaseSleeping:
 		return "Suspended"
 	default:
 		return "Unknown"
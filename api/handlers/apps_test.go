@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newAppsTestServer(t *testing.T, cs kubernetes.Interface, objs ...client.Object) (http.Handler, client.Client) {
+	t.Helper()
+	scheme, err := k8stypes.NewScheme()
+	if err != nil {
+		t.Fatalf("scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	r := chi.NewRouter()
+	r.Use(asCaller(RoleAdmin))
+	r.Route("/api/apps", NewAppsHandler(c, cs).Routes)
+	return r, c
+}
+
+func appWithHistory() *k8stypes.App {
+	replicas := int32(3)
+	return &k8stypes.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: k8stypes.AppSpec{
+			RepoUrl:  "https://github.com/acme/web",
+			Image:    "ghcr.io/acme/web:v3",
+			Replicas: &replicas,
+			Env:      []k8stypes.AppEnvVar{{Name: "MODE", Value: "new"}},
+		},
+		Status: k8stypes.AppStatus{
+			Phase:           k8stypes.AppPhaseHealthy,
+			CurrentRevision: 3,
+			History: []k8stypes.AppRevision{
+				{Revision: 1, Image: "ghcr.io/acme/web:v1", Replicas: 1, Status: k8stypes.AppRevisionSucceeded},
+				{Revision: 2, Image: "ghcr.io/acme/web:v2", CommitSHA: "bbb", Replicas: 2,
+					Env: []k8stypes.AppEnvVar{{Name: "MODE", Value: "old"}}, Status: k8stypes.AppRevisionSucceeded},
+				{Revision: 3, Image: "ghcr.io/acme/web:v3", Replicas: 3, DeployedBy: "alice",
+					Env: []k8stypes.AppEnvVar{{Name: "MODE", Value: "new"}}, Status: k8stypes.AppRevisionSucceeded},
+			},
+		},
+	}
+}
+
+func TestAppsDeployments(t *testing.T) {
+	app := appWithHistory()
+	app.Status.History[1].Status = k8stypes.AppRevisionRolledBack
+	srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), app)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/apps/web/deployments", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+	}
+	var got []DeploymentResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("deployments = %d, want 3", len(got))
+	}
+	if got[0].Version != "v3" || got[0].ImageTag != "v3" || got[0].TriggeredBy != "alice" {
+		t.Errorf("newest deployment = %+v", got[0])
+	}
+	if got[1].Status != "rolled_back" {
+		t.Errorf("revision 2 status = %q, want rolled_back", got[1].Status)
+	}
+}
+
+func TestAppsRollback(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		wantStatus   int
+		wantImage    string
+		wantReplicas int32
+		wantRevision string
+	}{
+		{
+			name:         "defaults to the previous revision",
+			body:         "",
+			wantStatus:   http.StatusOK,
+			wantImage:    "ghcr.io/acme/web:v2",
+			wantReplicas: 2,
+			wantRevision: "2",
+		},
+		{
+			name:         "explicit revision",
+			body:         `{"revision":1}`,
+			wantStatus:   http.StatusOK,
+			wantImage:    "ghcr.io/acme/web:v1",
+			wantReplicas: 1,
+			wantRevision: "1",
+		},
+		{
+			name:       "current revision is rejected",
+			body:       `{"revision":3}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "unknown revision",
+			body:       `{"revision":9}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newAppsTestServer(t, k8sfake.NewClientset(), appWithHistory())
+			req := httptest.NewRequest(http.MethodPost, "/api/apps/web/rollback", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			app := &k8stypes.App{}
+			if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "web"}, app); err != nil {
+				t.Fatalf("get app: %v", err)
+			}
+			if app.Spec.Image != tt.wantImage {
+				t.Errorf("image = %q, want %q", app.Spec.Image, tt.wantImage)
+			}
+			if app.Spec.Replicas == nil || *app.Spec.Replicas != tt.wantReplicas {
+				t.Errorf("replicas = %v, want %d", app.Spec.Replicas, tt.wantReplicas)
+			}
+			if got := app.Annotations[rollbackToAnnotation]; got != tt.wantRevision {
+				t.Errorf("rollback-to annotation = %q, want %q", got, tt.wantRevision)
+			}
+		})
+	}
+}
+
+func TestAppsRolloutRequests(t *testing.T) {
+	canaryApp := func(ro *k8stypes.AppRolloutStatus) *k8stypes.App {
+		app := appWithHistory()
+		app.Spec.Strategy = &k8stypes.AppStrategy{
+			Type:   k8stypes.AppStrategyCanary,
+			Canary: &k8stypes.CanaryStrategy{Steps: []k8stypes.CanaryStep{{Weight: 10}, {Weight: 50}}},
+		}
+		app.Status.Phase = k8stypes.AppPhasePaused
+		app.Status.Rollout = ro
+		return app
+	}
+	active := &k8stypes.AppRolloutStatus{
+		StableImage: "ghcr.io/acme/web:v3", NewImage: "ghcr.io/acme/web:v4", CurrentStep: 1, Weight: 50, Paused: true,
+	}
+
+	tests := []struct {
+		name           string
+		rollout        *k8stypes.AppRolloutStatus
+		path           string
+		wantStatus     int
+		wantAnnotation string
+	}{
+		{"promote", active, "promote", http.StatusOK, promoteRequestedAnnotation},
+		{"abort", active, "abort", http.StatusOK, abortRequestedAnnotation},
+		{"no rollout in progress", &k8stypes.AppRolloutStatus{StableImage: "ghcr.io/acme/web:v3"}, "promote", http.StatusConflict, ""},
+		{"already promoting", &k8stypes.AppRolloutStatus{NewImage: "ghcr.io/acme/web:v4", Promoting: true}, "promote", http.StatusConflict, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newAppsTestServer(t, k8sfake.NewClientset(), canaryApp(tt.rollout))
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/apps/web/rollout/"+tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantAnnotation == "" {
+				return
+			}
+			app := &k8stypes.App{}
+			if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "web"}, app); err != nil {
+				t.Fatalf("get app: %v", err)
+			}
+			if app.Annotations[tt.wantAnnotation] == "" {
+				t.Errorf("annotation %s not set", tt.wantAnnotation)
+			}
+		})
+	}
+
+	srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), canaryApp(active))
+	var got AppResp
+	getJSON(t, srv, "/api/apps/web", &got)
+	want := RolloutResp{
+		Strategy: "Canary", Phase: "deploying", Step: 2, TotalSteps: 2, Weight: 50, Paused: true,
+		StableImage: "ghcr.io/acme/web:v3", NewImage: "ghcr.io/acme/web:v4",
+	}
+	if got.Rollout == nil || *got.Rollout != want {
+		t.Errorf("rollout = %+v, want %+v", got.Rollout, want)
+	}
+}
+
+func TestAppsDomainSSLStatus(t *testing.T) {
+	expires := metav1.NewTime(time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC))
+	app := appWithHistory()
+	app.Spec.Domains = []string{"web.acme.dev", "www.acme.dev", "new.acme.dev"}
+	app.Spec.TLS = &k8stypes.AppTLS{Issuer: &k8stypes.AppIssuerRef{Name: "letsencrypt"}}
+	app.Status.Domains = []k8stypes.AppDomainStatus{
+		{Domain: "web.acme.dev", SSLStatus: k8stypes.AppSSLStatusValid, ExpiresAt: &expires},
+		{Domain: "www.acme.dev", SSLStatus: k8stypes.AppSSLStatusFailed, Message: "Certificate issuance failed: rate limited"},
+	}
+
+	srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), app)
+	var got AppResp
+	getJSON(t, srv, "/api/apps/web", &got)
+	want := []DomainResp{
+		{ID: "web.acme.dev", Domain: "web.acme.dev", SslStatus: "valid", ExpiresAt: "2027-01-15T00:00:00Z"},
+		{ID: "www.acme.dev", Domain: "www.acme.dev", SslStatus: "failed", Message: "Certificate issuance failed: rate limited"},
+		{ID: "new.acme.dev", Domain: "new.acme.dev", SslStatus: "pending"},
+	}
+	if len(got.Domains) != len(want) {
+		t.Fatalf("domains = %+v, want %+v", got.Domains, want)
+	}
+	for i := range want {
+		if got.Domains[i] != want[i] {
+			t.Errorf("domains[%d] = %+v, want %+v", i, got.Domains[i], want[i])
+		}
+	}
+}
+
+func TestAppsSyncStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		conditions []metav1.Condition
+		want       string
+	}{
+		{name: "no condition yet", want: "Synced"},
+		{name: "drift corrected", want: "OutOfSync", conditions: []metav1.Condition{{
+			Type: "OutOfSync", Status: metav1.ConditionTrue, Reason: "DriftCorrected",
+			Message: "Corrected manual changes to Deployment default/web: spec.replicas.",
+		}}},
+		{name: "in sync", want: "Synced", conditions: []metav1.Condition{{
+			Type: "OutOfSync", Status: metav1.ConditionFalse, Reason: "Synced",
+		}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := appWithHistory()
+			app.Status.Conditions = tc.conditions
+			srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), app)
+			var got AppResp
+			getJSON(t, srv, "/api/apps/web", &got)
+			if got.ArgoSyncStatus != tc.want {
+				t.Errorf("argoSyncStatus = %q, want %q", got.ArgoSyncStatus, tc.want)
+			}
+		})
+	}
+}
+
+func appPod(name string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "prod",
+			Labels: map[string]string{
+				"app.kubernetes.io/name":       "web",
+				"app.kubernetes.io/managed-by": "flowcd-operator",
+			},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func logsTestServer(t *testing.T) http.Handler {
+	t.Helper()
+	app := appWithHistory()
+	app.Spec.Destination = &k8stypes.AppDestination{Namespace: "prod"}
+	cs := k8sfake.NewClientset(
+		appPod("web-b", corev1.PodRunning),
+		appPod("web-a", corev1.PodRunning),
+		appPod("web-c", corev1.PodPending),
+	)
+	srv, _ := newAppsTestServer(t, cs, app)
+	return srv
+}
+
+func TestAppsLogs(t *testing.T) {
+	srv := logsTestServer(t)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/apps/web/logs?tailLines=10&container=web", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+	}
+	var got []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := []string{"[web-a] fake logs", "[web-b] fake logs"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("logs = %q, want %q", got, want)
+	}
+
+	for _, q := range []string{"tailLines=-1", "sinceSeconds=0", "previous=maybe", "previous=true&follow=true"} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/apps/web/logs?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestAppsLogsFollow(t *testing.T) {
+	srv := logsTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/apps/web/logs?follow=true", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q, want text/event-stream", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"data: [web-a] fake logs\n\n", "data: [web-b] fake logs\n\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("stream %q does not contain %q", body, want)
+		}
+	}
+	if strings.Contains(body, "web-c") {
+		t.Errorf("stream %q includes a pending pod", body)
+	}
+}
@@ -2,13 +2,18 @@ package handlers
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"k8s.io/client-go/kubernetes"
 )
 
 // jwtSecret is loaded from the JWT_SECRET env var, with a default for development.
@@ -16,11 +21,13 @@ var jwtSecret = func() []byte {
 	if s := os.Getenv("JWT_SECRET"); s != "" {
 		return []byte(s)
 	}
-	return []byte("flowcd-dev-secret-change-in-production")
+	return []byte(defaultJWTSecret)
 }()
 
-// adminEmail / adminPassword are the single-user credentials.
-// In production, set AUTH_EMAIL and AUTH_PASSWORD env vars.
+// adminEmail / adminPassword are the bootstrap credentials: the first login
+// with them while no users exist creates that user as an Admin.
+// In production, set AUTH_EMAIL and AUTH_PASSWORD env vars; the built-in
+// password only bootstraps in dev mode.
 var adminEmail = func() string {
 	if e := os.Getenv("AUTH_EMAIL"); e != "" {
 		return e
@@ -32,29 +39,57 @@ var adminPassword = func() string {
 	if p := os.Getenv("AUTH_PASSWORD"); p != "" {
 		return p
 	}
-	return "flowcd"
+	return defaultAdminPassword
 }()
 
+const defaultAdminPassword = "flowcd"
+
 // contextKey is the type used for context keys in this package.
 type contextKey string
 
-const contextKeyEmail contextKey = "email"
+const (
+	contextKeyEmail  contextKey = "email"
+	contextKeyName   contextKey = "name"
+	contextKeyRole   contextKey = "role"
+	contextKeyScopes contextKey = "scopes"
+	contextKeyToken  contextKey = "token"
+	// contextKeyAPIToken marks requests made with an API token instead of a
+	// session.
+	contextKeyAPIToken contextKey = "api-token"
+)
+
+// tokenInfo identifies the access token a request was made with.
+type tokenInfo struct {
+	ID        string
+	SessionID string
+	ExpiresAt time.Time
+}
 
 // ─── Handler ─────────────────────────────────────────────────────────────────
 
-type AuthHandler struct{}
+type AuthHandler struct {
+	users    *userStore
+	sessions *sessionStore
+}
 
-func NewAuthHandler() *AuthHandler { return &AuthHandler{} }
+func NewAuthHandler(cs kubernetes.Interface) *AuthHandler {
+	return &AuthHandler{users: newUserStore(cs), sessions: newSessionStore(cs)}
+}
 
 type loginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+// loginResponse carries a short-lived access token in Token and the refresh
+// token that renews it.
 type loginResponse struct {
-	Token string `json:"token"`
-	Email string `json:"email"`
-	Name  string `json:"name"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	Role         string `json:"role"`
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -63,24 +98,151 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
-	if req.Email != adminEmail || req.Password != adminPassword {
+	u, err := h.users.authenticate(r.Context(), req.Email, req.Password)
+	if errors.Is(err, errUserNotFound) {
+		u, err = h.bootstrap(r.Context(), req.Email, req.Password)
+	}
+	if errors.Is(err, errUserNotFound) {
 		jsonError(w, "invalid credentials", http.StatusUnauthorized)
 		return
 	}
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	claims := jwt.MapClaims{
-		"sub":  req.Email,
-		"name": "Admin",
-		"exp":  time.Now().Add(24 * time.Hour).Unix(),
-		"iat":  time.Now().Unix(),
+	resp, err := startSession(r.Context(), h.sessions, session{Email: u.Email, Name: u.Name, Role: u.Role, Scopes: u.Scopes, Local: true})
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonOK(w, resp)
+}
+
+// Refresh exchanges a refresh token for a new access token and the next
+// refresh token; each refresh token works once. Local users get their
+// current role, and removed users are signed out.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	sess, refresh, err := h.sessions.rotate(r.Context(), req.RefreshToken, func(sess *session) error {
+		if !sess.Local {
+			return nil
+		}
+		u, err := h.users.byEmail(r.Context(), sess.Email)
+		if err != nil {
+			return errSessionInvalid
+		}
+		sess.Name, sess.Role, sess.Scopes = u.Name, u.Role, u.Scopes
+		return nil
+	})
+	if errors.Is(err, errSessionInvalid) {
+		jsonError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(jwtSecret)
+	access, err := signToken(sess)
 	if err != nil {
 		jsonError(w, "failed to sign token", http.StatusInternalServerError)
 		return
 	}
-	jsonOK(w, loginResponse{Token: signed, Email: req.Email, Name: "Admin"})
+	jsonOK(w, sessionResponse(sess, access, refresh))
+}
+
+// Logout ends the caller's session and revokes the access token it was
+// called with.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	tok, _ := r.Context().Value(contextKeyToken).(tokenInfo)
+	if tok.SessionID != "" {
+		if err := h.sessions.delete(r.Context(), tok.SessionID); err != nil {
+			jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if revocations != nil && tok.ID != "" {
+		if err := revocations.revoke(r.Context(), tok.ID, tok.ExpiresAt); err != nil {
+			jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// startSession signs a user in, whichever way they authenticated, and now and
+// then clears out sessions that expired unused.
+func startSession(ctx context.Context, sessions *sessionStore, sess session) (loginResponse, error) {
+	created, refresh, err := sessions.create(ctx, sess)
+	if err != nil {
+		return loginResponse{}, err
+	}
+	sessions.sweepExpired(ctx)
+	access, err := signToken(created)
+	if err != nil {
+		return loginResponse{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return sessionResponse(created, access, refresh), nil
+}
+
+func sessionResponse(sess *session, access, refresh string) loginResponse {
+	return loginResponse{
+		Token:        access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+		Email:        sess.Email,
+		Name:         sess.Name,
+		Role:         string(sess.Role),
+	}
+}
+
+// signToken issues an access token for the session.
+func signToken(sess *session) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":  sess.Email,
+		"name": sess.Name,
+		"role": string(sess.Role),
+		"sid":  sess.ID,
+		"jti":  jti,
+		"exp":  now.Add(accessTokenTTL).Unix(),
+		"iat":  now.Unix(),
+	}
+	if len(sess.Scopes) > 0 {
+		claims["scopes"] = sess.Scopes
+	}
+	return sessionKeys.sign(claims)
+}
+
+// bootstrap creates the first Admin from the AUTH_EMAIL/AUTH_PASSWORD
+// credentials. Once any user exists it reports errUserNotFound.
+func (h *AuthHandler) bootstrap(ctx context.Context, email, password string) (*user, error) {
+	if normalizeEmail(email) != normalizeEmail(adminEmail) ||
+		subtle.ConstantTimeCompare([]byte(password), []byte(adminPassword)) != 1 {
+		return nil, errUserNotFound
+	}
+	if adminPassword == defaultAdminPassword && !devMode() {
+		log.Printf("refusing to create the first Admin with the built-in password; set AUTH_PASSWORD (or FLOWCD_DEV_MODE=true for local development)")
+		return nil, errUserNotFound
+	}
+	users, err := h.users.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) > 0 {
+		return nil, errUserNotFound
+	}
+	return h.users.create(ctx, email, "Admin", RoleAdmin, nil, password)
 }
 
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
@@ -89,12 +251,15 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, "not authenticated", http.StatusUnauthorized)
 		return
 	}
-	jsonOK(w, map[string]string{"email": email, "name": "Admin"})
+	name, _ := r.Context().Value(contextKeyName).(string)
+	role, _ := r.Context().Value(contextKeyRole).(string)
+	jsonOK(w, map[string]string{"email": email, "name": name, "role": role})
 }
 
 // ─── Middleware ───────────────────────────────────────────────────────────────
 
-// ValidateJWT is middleware that requires a valid Bearer JWT on all requests.
+// ValidateJWT is middleware that requires a valid Bearer JWT, or an API
+// token, on all requests.
 func ValidateJWT(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -103,12 +268,25 @@ func ValidateJWT(next http.Handler) http.Handler {
 			return
 		}
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		if strings.HasPrefix(tokenStr, apiTokenPrefix) {
+			actor, role, scopes, err := apiTokenCaller(r.Context(), tokenStr)
+			if errors.Is(err, errAPITokenInvalid) {
+				jsonError(w, err.Error(), http.StatusUnauthorized)
+				return
 			}
-			return jwtSecret, nil
-		})
+			if err != nil {
+				jsonError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			ctx := context.WithValue(r.Context(), contextKeyEmail, actor)
+			ctx = context.WithValue(ctx, contextKeyName, actor)
+			ctx = context.WithValue(ctx, contextKeyRole, string(role))
+			ctx = context.WithValue(ctx, contextKeyScopes, scopes)
+			ctx = context.WithValue(ctx, contextKeyAPIToken, true)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		token, err := jwt.Parse(tokenStr, sessionKeys.keyFunc)
 		if err != nil || !token.Valid {
 			jsonError(w, "invalid or expired token", http.StatusUnauthorized)
 			return
@@ -118,8 +296,42 @@ func ValidateJWT(next http.Handler) http.Handler {
 			jsonError(w, "invalid token claims", http.StatusUnauthorized)
 			return
 		}
+		tok := tokenInfo{}
+		tok.ID, _ = claims["jti"].(string)
+		tok.SessionID, _ = claims["sid"].(string)
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			tok.ExpiresAt = exp.Time
+		}
+		// Only access tokens belong to a session; this also keeps other
+		// tokens signed with the same keys out.
+		if tok.SessionID == "" {
+			jsonError(w, "invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+		if revocations != nil && tok.ID != "" && revocations.isRevoked(r.Context(), tok.ID) {
+			jsonError(w, "token has been revoked", http.StatusUnauthorized)
+			return
+		}
 		email, _ := claims["sub"].(string)
+		name, _ := claims["name"].(string)
+		role, _ := claims["role"].(string)
 		ctx := context.WithValue(r.Context(), contextKeyEmail, email)
+		ctx = context.WithValue(ctx, contextKeyName, name)
+		ctx = context.WithValue(ctx, contextKeyRole, role)
+		ctx = context.WithValue(ctx, contextKeyScopes, claimStrings(claims["scopes"]))
+		ctx = context.WithValue(ctx, contextKeyToken, tok)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// claimStrings converts a JSON array claim to a string slice.
+func claimStrings(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
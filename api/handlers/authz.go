@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Permission is an action on a kind of resource. Every protected route is
+// registered behind Authorize or authorizeApp with the permission it needs.
+type Permission string
+
+const (
+	PermAppsRead          Permission = "apps:read"
+	PermAppsWrite         Permission = "apps:write"
+	PermAppsDeploy        Permission = "apps:deploy"
+	PermAppsDelete        Permission = "apps:delete"
+	PermPromotionsApprove Permission = "promotions:approve"
+	PermPipelinesRead     Permission = "pipelines:read"
+	PermClustersRead      Permission = "clusters:read"
+	PermActivityRead      Permission = "activity:read"
+	PermSettingsRead      Permission = "settings:read"
+	PermSettingsManage    Permission = "settings:manage"
+	PermTeamRead          Permission = "team:read"
+	PermTeamManage        Permission = "team:manage"
+	PermTokensOwn         Permission = "tokens:own"
+)
+
+var viewerPermissions = []Permission{
+	PermAppsRead, PermPipelinesRead, PermClustersRead, PermActivityRead,
+	PermSettingsRead, PermTeamRead, PermTokensOwn,
+}
+
+var developerPermissions = append([]Permission{PermAppsWrite, PermAppsDeploy}, viewerPermissions...)
+
+// rolePermissions grants Viewers read-only access and their own API tokens,
+// lets Developers create, edit and deploy Apps, and leaves deletion,
+// approvals, settings and the team to Admins.
+var rolePermissions = map[Role][]Permission{
+	RoleViewer:    viewerPermissions,
+	RoleDeveloper: developerPermissions,
+	RoleAdmin: append([]Permission{
+		PermAppsDelete, PermPromotionsApprove, PermSettingsManage, PermTeamManage,
+	}, developerPermissions...),
+}
+
+func (r Role) can(perm Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize is middleware that lets the request through only when the
+// caller's role grants perm. It must run after ValidateJWT.
+func Authorize(perm Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !checkRole(w, r, perm) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authorizeApp is Authorize for routes on a single App: a caller whose access
+// is scoped to some namespaces or Apps must also have the App's in scope.
+func authorizeApp(perm Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !checkRole(w, r, perm) {
+				return
+			}
+			ns, name := splitAppID(chi.URLParam(r, "id"))
+			if !checkScope(w, r, perm, ns, name) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func checkRole(w http.ResponseWriter, r *http.Request, perm Permission) bool {
+	role := callerRole(r)
+	if role.can(perm) {
+		return true
+	}
+	msg := fmt.Sprintf("role %s does not grant %s", role, perm)
+	if role == "" {
+		msg = fmt.Sprintf("token carries no role; %s is required", perm)
+	}
+	forbidden(w, ForbiddenResp{Error: msg, Permission: string(perm), Role: string(role)})
+	return false
+}
+
+// checkScope writes a 403 unless the App ns/name is within the caller's
+// scopes.
+func checkScope(w http.ResponseWriter, r *http.Request, perm Permission, ns, name string) bool {
+	if inScope(callerScopes(r), ns, name) {
+		return true
+	}
+	forbidden(w, ForbiddenResp{
+		Error:      fmt.Sprintf("%s on app %s/%s is outside your scope", perm, ns, name),
+		Permission: string(perm),
+		Role:       string(callerRole(r)),
+		Scope:      ns + "/" + name,
+	})
+	return false
+}
+
+func forbidden(w http.ResponseWriter, resp ForbiddenResp) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func callerRole(r *http.Request) Role {
+	role, _ := r.Context().Value(contextKeyRole).(string)
+	return Role(role)
+}
+
+func callerScopes(r *http.Request) []string {
+	scopes, _ := r.Context().Value(contextKeyScopes).([]string)
+	return scopes
+}
+
+// inScope reports whether the App ns/name is covered by scopes. Each scope is
+// a namespace or a "namespace/app" pair; no scopes means every App.
+func inScope(scopes []string, ns, name string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, s := range scopes {
+		if s == ns || s == ns+"/"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseScopes validates scopes given for a user and returns them trimmed.
+func parseScopes(scopes []string) ([]string, error) {
+	out := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		s = strings.TrimSpace(s)
+		ns, name, hasName := strings.Cut(s, "/")
+		if ns == "" || hasName && (name == "" || strings.Contains(name, "/")) {
+			return nil, fmt.Errorf("invalid scope %q: must be a namespace or namespace/app", s)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// splitAppID splits an App route id, "namespace/name" or plain "name" in the
+// default namespace, the same way fetchApp does.
+func splitAppID(id string) (string, string) {
+	if ns, name, ok := strings.Cut(id, "/"); ok {
+		return ns, name
+	}
+	return "default", id
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// asCaller is middleware that authenticates requests as role, standing in
+// for ValidateJWT. Requests that already carry a role keep it.
+func asCaller(role Role, scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if _, ok := ctx.Value(contextKeyRole).(string); !ok {
+				ctx = context.WithValue(ctx, contextKeyRole, string(role))
+				ctx = context.WithValue(ctx, contextKeyScopes, scopes)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func TestAuthorizeRoles(t *testing.T) {
+	tests := []struct {
+		role     Role
+		method   string
+		path     string
+		wantCode int
+		wantPerm string
+	}{
+		{role: RoleViewer, method: http.MethodGet, path: "/api/apps/web", wantCode: http.StatusOK},
+		{role: RoleViewer, method: http.MethodPost, path: "/api/apps/web/redeploy", wantCode: http.StatusForbidden, wantPerm: "apps:deploy"},
+		{role: RoleDeveloper, method: http.MethodPost, path: "/api/apps/web/redeploy", wantCode: http.StatusOK},
+		{role: RoleDeveloper, method: http.MethodDelete, path: "/api/apps/web", wantCode: http.StatusForbidden, wantPerm: "apps:delete"},
+		{role: RoleDeveloper, method: http.MethodPost, path: "/api/settings/team", wantCode: http.StatusForbidden, wantPerm: "team:manage"},
+		{role: RoleViewer, method: http.MethodGet, path: "/api/settings/credentials", wantCode: http.StatusOK},
+		{role: RoleAdmin, method: http.MethodDelete, path: "/api/apps/web", wantCode: http.StatusNoContent},
+		{role: "", method: http.MethodGet, path: "/api/apps/web", wantCode: http.StatusForbidden, wantPerm: "apps:read"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+tt.method+" "+tt.path, func(t *testing.T) {
+			srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), appWithHistory())
+			settings := chi.NewRouter()
+			settings.Route("/api/settings", NewSettingsHandler(k8sfake.NewClientset()).Routes)
+			mux := http.NewServeMux()
+			mux.Handle("/api/apps/", srv)
+			mux.Handle("/api/settings/", settings)
+
+			rec := httptest.NewRecorder()
+			asCaller(tt.role)(mux).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantPerm == "" {
+				return
+			}
+			var got ForbiddenResp
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Permission != tt.wantPerm || got.Role != string(tt.role) {
+				t.Errorf("403 = %+v, want permission %s for role %q", got, tt.wantPerm, tt.role)
+			}
+		})
+	}
+}
+
+func TestAuthorizeScopes(t *testing.T) {
+	other := appWithHistory()
+	other.ObjectMeta = metav1.ObjectMeta{Name: "api", Namespace: "default"}
+	srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), appWithHistory(), other)
+	r := asCaller(RoleDeveloper, "default/web")(srv)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/apps/web/redeploy", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("in-scope redeploy status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/apps/api/redeploy", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("out-of-scope redeploy status = %d, want 403", rec.Code)
+	}
+	var denied ForbiddenResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &denied); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if denied.Scope != "default/api" || denied.Permission != "apps:deploy" {
+		t.Errorf("403 = %+v, want apps:deploy on default/api", denied)
+	}
+
+	var apps []AppResp
+	getJSON(t, r, "/api/apps/", &apps)
+	if len(apps) != 1 || apps[0].Name != "web" {
+		t.Errorf("listed apps = %+v, want only web", apps)
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	if got, err := parseScopes([]string{" team-a ", "team-b/web"}); err != nil || len(got) != 2 || got[0] != "team-a" {
+		t.Errorf("parseScopes = %v, %v", got, err)
+	}
+	for _, bad := range []string{"", "/web", "team-a/", "a/b/c"} {
+		if _, err := parseScopes([]string{bad}); err == nil {
+			t.Errorf("parseScopes(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Labels and stage names shared with the operator's Pipeline controller.
+const (
+	pipelineLabel = "platform.flowcd.io/pipeline"
+	runLabel      = "platform.flowcd.io/run"
+	buildLogKey   = "log"
+)
+
+// buildStageNames are the build pod containers, in execution order.
+var buildStageNames = []string{"clone", "build"}
+
+// buildPollInterval is how often a live build log stream checks whether the
+// next stage has started.
+const buildPollInterval = 2 * time.Second
+
+// BuildsHandler serves the output of individual pipeline runs.
+type BuildsHandler struct {
+	client    client.Client
+	clientset kubernetes.Interface
+}
+
+func NewBuildsHandler(c client.Client, cs kubernetes.Interface) *BuildsHandler {
+	return &BuildsHandler{client: c, clientset: cs}
+}
+
+func (h *BuildsHandler) Routes(r chi.Router) {
+	r.With(Authorize(PermPipelinesRead)).Get("/{id}/logs", h.logs)
+}
+
+// logs returns a build's output. Finished builds are served from the
+// ConfigMaps the operator captured; running builds are read from the build
+// pod. With follow=true the output is streamed as Server-Sent Events until the
+// build ends, followed by an "end" event.
+func (h *BuildsHandler) logs(w http.ResponseWriter, r *http.Request) {
+	run, err := h.findRun(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !h.checkRunScope(w, r, run) {
+		return
+	}
+	follow := false
+	if v := r.URL.Query().Get("follow"); v != "" {
+		if follow, err = strconv.ParseBool(v); err != nil {
+			jsonError(w, "follow must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+	if follow {
+		h.streamLogs(w, r, run)
+		return
+	}
+	lines, err := buildLogs(r.Context(), h.clientset, run)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonOK(w, lines)
+}
+
+// findRun looks a PipelineRun up by the ID exposed in BuildResp.
+func (h *BuildsHandler) findRun(ctx context.Context, id string) (*k8stypes.PipelineRun, error) {
+	list := &k8stypes.PipelineRunList{}
+	if err := h.client.List(ctx, list); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if runID(&list.Items[i]) == id {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("build %q not found", id)
+}
+
+// checkRunScope refuses callers whose scopes do not cover the App the run's
+// Pipeline builds. A run whose Pipeline is gone is judged by its namespace.
+func (h *BuildsHandler) checkRunScope(w http.ResponseWriter, r *http.Request, run *k8stypes.PipelineRun) bool {
+	pipeline := &k8stypes.Pipeline{}
+	err := h.client.Get(r.Context(), client.ObjectKey{Namespace: run.Namespace, Name: run.Spec.PipelineRef}, pipeline)
+	if err != nil && !apierrors.IsNotFound(err) {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	return checkScope(w, r, PermPipelinesRead, run.Namespace, pipeline.Spec.AppRef)
+}
+
+func (h *BuildsHandler) streamLogs(w http.ResponseWriter, r *http.Request, run *k8stypes.PipelineRun) {
+	sse, ok := startSSE(w)
+	if !ok {
+		return
+	}
+	defer sse.send("end", "")
+
+	ctx := r.Context()
+	if runFinished(run) {
+		lines, err := buildLogs(ctx, h.clientset, run)
+		if err != nil {
+			sse.send("error", err.Error())
+			return
+		}
+		for _, line := range lines {
+			sse.send("", line)
+		}
+		return
+	}
+
+	for _, stage := range buildStageNames {
+		pod, ok := h.waitForStage(ctx, run, stage)
+		if !ok {
+			return
+		}
+		stream, err := h.clientset.CoreV1().Pods(pod.Namespace).
+			GetLogs(pod.Name, &corev1.PodLogOptions{Container: stage, Follow: true}).Stream(ctx)
+		if err != nil {
+			sse.send("error", fmt.Sprintf("[%s] %v", stage, err))
+			return
+		}
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineBytes)
+		for scanner.Scan() {
+			sse.send("", "["+stage+"] "+scanner.Text())
+		}
+		_ = stream.Close()
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// waitForStage polls until the build pod's container for stage has started.
+// It reports false when the stage will never run or the client went away.
+func (h *BuildsHandler) waitForStage(ctx context.Context, run *k8stypes.PipelineRun, stage string) (*corev1.Pod, bool) {
+	ticker := time.NewTicker(buildPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := h.client.Get(ctx, client.ObjectKeyFromObject(run), run); err != nil {
+			return nil, false
+		}
+		pod, err := buildPod(ctx, h.clientset, run)
+		if err != nil {
+			return nil, false
+		}
+		if pod != nil {
+			if stageStarted(pod, stage) {
+				return pod, true
+			}
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				return nil, false
+			}
+		} else if runFinished(run) {
+			return nil, false
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-ticker.C:
+		}
+	}
+}
+
+// ─── log sources ─────────────────────────────────────────────────────────────
+
+// buildLogs returns a run's output prefixed by stage, preferring the copy
+// persisted by the operator and falling back to the build pod while it
+// still exists.
+func buildLogs(ctx context.Context, cs kubernetes.Interface, run *k8stypes.PipelineRun) ([]string, error) {
+	if run.Status.LogChunks > 0 {
+		return storedBuildLogs(ctx, cs, run)
+	}
+	pod, err := buildPod(ctx, cs, run)
+	if err != nil || pod == nil {
+		return []string{}, err
+	}
+	lines := []string{}
+	for _, stage := range buildStageNames {
+		if !stageStarted(pod, stage) {
+			continue
+		}
+		raw, err := cs.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: stage}).DoRaw(ctx)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("[%s] logs unavailable: %v", stage, err))
+			continue
+		}
+		for _, l := range splitLines(string(raw)) {
+			lines = append(lines, "["+stage+"] "+l)
+		}
+	}
+	return lines, nil
+}
+
+// storedBuildLogs reassembles the build output chunks the operator wrote to
+// ConfigMaps named <run>-logs-<index>.
+func storedBuildLogs(ctx context.Context, cs kubernetes.Interface, run *k8stypes.PipelineRun) ([]string, error) {
+	var out strings.Builder
+	for i := int32(0); i < run.Status.LogChunks; i++ {
+		cm, err := cs.CoreV1().ConfigMaps(run.Namespace).Get(ctx, fmt.Sprintf("%s-logs-%d", run.Name, i), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(cm.Data[buildLogKey])
+	}
+	return splitLines(out.String()), nil
+}
+
+// buildPod returns the newest pod of a run's build Job, or nil when there is
+// none.
+func buildPod(ctx context.Context, cs kubernetes.Interface, run *k8stypes.PipelineRun) (*corev1.Pod, error) {
+	if run.Status.RunNumber == 0 {
+		return nil, nil
+	}
+	selector := labels.SelectorFromSet(map[string]string{
+		pipelineLabel: run.Spec.PipelineRef,
+		runLabel:      strconv.FormatInt(run.Status.RunNumber, 10),
+	})
+	list, err := cs.CoreV1().Pods(run.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	var newest *corev1.Pod
+	for i := range list.Items {
+		if newest == nil || newest.CreationTimestamp.Before(&list.Items[i].CreationTimestamp) {
+			newest = &list.Items[i]
+		}
+	}
+	return newest, nil
+}
+
+// stageStarted reports whether the build pod's container for stage is
+// running or has run.
+func stageStarted(pod *corev1.Pod, stage string) bool {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.Name == stage {
+			return cs.State.Running != nil || cs.State.Terminated != nil
+		}
+	}
+	return false
+}
+
+func runFinished(run *k8stypes.PipelineRun) bool {
+	return run.Status.Phase == k8stypes.PipelinePhaseSucceeded || run.Status.Phase == k8stypes.PipelinePhaseFailed
+}
+
+func splitLines(s string) []string {
+	lines := []string{}
+	for _, l := range strings.Split(s, "\n") {
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// ─── app builds ──────────────────────────────────────────────────────────────
+
+// appRuns returns the runs of every Pipeline delivering to the App, newest
+// first.
+func appRuns(ctx context.Context, c client.Client, app *k8stypes.App) ([]k8stypes.PipelineRun, error) {
+	pipelines := &k8stypes.PipelineList{}
+	if err := c.List(ctx, pipelines, client.InNamespace(app.Namespace)); err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, p := range pipelines.Items {
+		if p.Spec.AppRef == app.Name {
+			names[p.Name] = true
+		}
+	}
+	list := &k8stypes.PipelineRunList{}
+	if err := c.List(ctx, list, client.InNamespace(app.Namespace)); err != nil {
+		return nil, err
+	}
+	runs := make([]k8stypes.PipelineRun, 0, len(list.Items))
+	for _, run := range list.Items {
+		if names[run.Spec.PipelineRef] {
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[j].CreationTimestamp.Before(&runs[i].CreationTimestamp)
+	})
+	return runs, nil
+}
+
+func runID(run *k8stypes.PipelineRun) string {
+	if run.UID != "" {
+		return string(run.UID)
+	}
+	return run.Name
+}
+
+func toBuildResp(app *k8stypes.App, run *k8stypes.PipelineRun) BuildResp {
+	appID := string(app.UID)
+	if appID == "" {
+		appID = app.Name
+	}
+	startedAt := run.CreationTimestamp.UTC().Format(time.RFC3339)
+	duration := float64(run.Status.Duration)
+	if run.Status.StartedAt != nil {
+		startedAt = run.Status.StartedAt.UTC().Format(time.RFC3339)
+		if !runFinished(run) {
+			duration = time.Since(run.Status.StartedAt.Time).Seconds()
+		}
+	}
+	status := "pending"
+	switch run.Status.Phase {
+	case k8stypes.PipelinePhaseSucceeded:
+		status = "success"
+	case k8stypes.PipelinePhaseFailed:
+		status = "failed"
+	case k8stypes.PipelinePhaseRunning:
+		status = "building"
+	}
+	return BuildResp{
+		ID:        runID(run),
+		AppID:     appID,
+		CommitSha: run.Status.CommitSHA,
+		Status:    status,
+		StartedAt: startedAt,
+		Duration:  duration,
+		Logs:      []string{},
+	}
+}
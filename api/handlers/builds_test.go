@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func buildsTestServer(t *testing.T) http.Handler {
+	t.Helper()
+	scheme, err := k8stypes.NewScheme()
+	if err != nil {
+		t.Fatalf("scheme: %v", err)
+	}
+	older := metav1.NewTime(time.Now().Add(-time.Hour))
+	newer := metav1.NewTime(time.Now())
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&k8stypes.App{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&k8stypes.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-build", Namespace: "default"},
+			Spec:       k8stypes.PipelineSpec{AppRef: "web"},
+		},
+		&k8stypes.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-build-done", Namespace: "default", UID: "run-done", CreationTimestamp: older},
+			Spec:       k8stypes.PipelineRunSpec{PipelineRef: "web-build"},
+			Status: k8stypes.PipelineRunStatus{
+				Phase: k8stypes.PipelinePhaseFailed, RunNumber: 1, CommitSHA: "abc", LogChunks: 2, Duration: 42,
+			},
+		},
+		&k8stypes.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-build-live", Namespace: "default", UID: "run-live", CreationTimestamp: newer},
+			Spec:       k8stypes.PipelineRunSpec{PipelineRef: "web-build"},
+			Status:     k8stypes.PipelineRunStatus{Phase: k8stypes.PipelinePhaseRunning, RunNumber: 2, StartedAt: &newer},
+		},
+	).Build()
+
+	cs := k8sfake.NewClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-build-done-logs-0", Namespace: "default"},
+			Data:       map[string]string{"log": "[clone] cloned\n[build] step 1\n"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-build-done-logs-1", Namespace: "default"},
+			Data:       map[string]string{"log": "[build] error: exit 1\n"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-build-build-2-xyz",
+				Namespace: "default",
+				Labels:    map[string]string{pipelineLabel: "web-build", runLabel: "2"},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{Name: "clone", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "build", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+				},
+			},
+		},
+	)
+
+	r := chi.NewRouter()
+	r.Use(asCaller(RoleAdmin))
+	r.Route("/api/apps", NewAppsHandler(c, cs).Routes)
+	r.Route("/api/builds", NewBuildsHandler(c, cs).Routes)
+	r.Route("/api/pipelines", NewPipelinesHandler(c).Routes)
+	return r
+}
+
+func getJSON(t *testing.T, srv http.Handler, path string, v any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status = %d (body %s)", path, rec.Code, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("GET %s: decode: %v", path, err)
+	}
+}
+
+func TestBuildLogs(t *testing.T) {
+	srv := buildsTestServer(t)
+
+	var stored []string
+	getJSON(t, srv, "/api/builds/run-done/logs", &stored)
+	want := "[clone] cloned|[build] step 1|[build] error: exit 1"
+	if got := strings.Join(stored, "|"); got != want {
+		t.Errorf("stored logs = %q, want %q", got, want)
+	}
+
+	var live []string
+	getJSON(t, srv, "/api/builds/run-live/logs", &live)
+	if got := strings.Join(live, "|"); got != "[clone] fake logs" {
+		t.Errorf("live logs = %q, want only the started clone stage", got)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/builds/missing/logs", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing build: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestBuildsAndPipelinesScoped(t *testing.T) {
+	srv := buildsTestServer(t)
+	inScope := asCaller(RoleViewer, "default/web")(srv)
+	outOfScope := asCaller(RoleViewer, "default/api")(srv)
+
+	var pipelines []PipelineResp
+	getJSON(t, inScope, "/api/pipelines/", &pipelines)
+	if len(pipelines) != 1 {
+		t.Errorf("in-scope pipelines = %d, want 1", len(pipelines))
+	}
+	getJSON(t, outOfScope, "/api/pipelines/", &pipelines)
+	if len(pipelines) != 0 {
+		t.Errorf("out-of-scope pipelines = %+v, want none", pipelines)
+	}
+
+	for _, path := range []string{"/api/pipelines/web-build", "/api/builds/run-done/logs", "/api/builds/run-live/logs?follow=true"} {
+		rec := httptest.NewRecorder()
+		outOfScope.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("out-of-scope GET %s: status = %d, want 403", path, rec.Code)
+		}
+	}
+	var stored []string
+	getJSON(t, inScope, "/api/builds/run-done/logs", &stored)
+	if len(stored) == 0 {
+		t.Error("in-scope Viewer got no logs")
+	}
+}
+
+func TestBuildLogsFollowFinished(t *testing.T) {
+	srv := buildsTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/builds/run-done/logs?follow=true", nil).WithContext(ctx))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "data: [build] error: exit 1\n\n") || !strings.HasSuffix(body, "event: end\ndata: \n\n") {
+		t.Errorf("unexpected stream %q", body)
+	}
+}
+
+func TestAppBuilds(t *testing.T) {
+	srv := buildsTestServer(t)
+
+	var builds []BuildResp
+	getJSON(t, srv, "/api/apps/web/builds", &builds)
+	if len(builds) != 2 {
+		t.Fatalf("builds = %d, want 2", len(builds))
+	}
+	if builds[0].ID != "run-live" || builds[0].Status != "building" || len(builds[0].Logs) != 1 {
+		t.Errorf("newest build = %+v", builds[0])
+	}
+	if builds[1].Status != "failed" || builds[1].CommitSha != "abc" || builds[1].Duration != 42 || len(builds[1].Logs) != 0 {
+		t.Errorf("older build = %+v", builds[1])
+	}
+}
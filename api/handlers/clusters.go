@@ -11,8 +11,8 @@ type ClustersHandler struct{}
 func NewClustersHandler() *ClustersHandler { return &ClustersHandler{} }
 
 func (h *ClustersHandler) Routes(r chi.Router) {
-	r.Get("/", h.list)
-	r.Get("/{id}", h.get)
+	r.With(Authorize(PermClustersRead)).Get("/", h.list)
+	r.With(Authorize(PermClustersRead)).Get("/{id}", h.get)
 }
 
 var stubCluster = ClusterResp{
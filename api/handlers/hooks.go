@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxHookBodyBytes bounds the size of an inbound webhook payload.
+const maxHookBodyBytes = 5 << 20
+
+// zeroSHA is the "after" value Git hosts send when a branch is deleted.
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// pushEvent is the provider-neutral subset of a push payload.
+type pushEvent struct {
+	Ref      string
+	After    string
+	RepoURLs []string
+	Author   string
+}
+
+// hookProvider knows how to authenticate and decode one Git host's webhooks.
+type hookProvider struct {
+	verify func(h http.Header, body, secret []byte) bool
+	isPush func(h http.Header) bool
+	parse  func(body []byte) (*pushEvent, error)
+}
+
+var hookProviders = map[string]hookProvider{
+	"github": {
+		verify: func(h http.Header, body, secret []byte) bool {
+			sig, ok := strings.CutPrefix(h.Get("X-Hub-Signature-256"), "sha256=")
+			return ok && validHMACSHA256(sig, body, secret)
+		},
+		isPush: func(h http.Header) bool { return h.Get("X-GitHub-Event") == "push" },
+		parse:  parseGitHubStylePush,
+	},
+	"gitea": {
+		verify: func(h http.Header, body, secret []byte) bool {
+			return validHMACSHA256(h.Get("X-Gitea-Signature"), body, secret)
+		},
+		isPush: func(h http.Header) bool { return h.Get("X-Gitea-Event") == "push" },
+		parse:  parseGitHubStylePush,
+	},
+	// GitLab does not sign payloads; it echoes the shared secret back in
+	// X-Gitlab-Token, which is compared in constant time.
+	"gitlab": {
+		verify: func(h http.Header, _, secret []byte) bool {
+			return subtle.ConstantTimeCompare([]byte(h.Get("X-Gitlab-Token")), secret) == 1
+		},
+		isPush: func(h http.Header) bool { return h.Get("X-Gitlab-Event") == "Push Hook" },
+		parse:  parseGitLabPush,
+	},
+}
+
+// HooksHandler receives push webhooks from Git hosts and starts PipelineRuns
+// for every Pipeline whose App tracks the pushed repository and branch.
+type HooksHandler struct {
+	client client.Client
+	secret []byte
+}
+
+// NewHooksHandler returns a HooksHandler whose shared secret is loaded from
+// the WEBHOOK_SECRET env var. With no secret configured every hook is refused.
+func NewHooksHandler(c client.Client) *HooksHandler {
+	return &HooksHandler{client: c, secret: []byte(os.Getenv("WEBHOOK_SECRET"))}
+}
+
+func (h *HooksHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "provider")
+	provider, ok := hookProviders[name]
+	if !ok {
+		jsonError(w, "unsupported webhook provider: "+name, http.StatusNotFound)
+		return
+	}
+	if len(h.secret) == 0 {
+		jsonError(w, "webhooks are not configured", http.StatusServiceUnavailable)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHookBodyBytes))
+	if err != nil {
+		jsonError(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if !provider.verify(r.Header, body, h.secret) {
+		jsonError(w, "invalid webhook signature", http.StatusUnauthorized)
+		return
+	}
+	if !provider.isPush(r.Header) {
+		jsonOK(w, HookResp{Status: "ignored", Runs: []string{}})
+		return
+	}
+	event, err := provider.parse(body)
+	if err != nil {
+		jsonError(w, "invalid push payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	branch, isBranch := strings.CutPrefix(event.Ref, "refs/heads/")
+	if !isBranch || event.After == "" || event.After == zeroSHA {
+		jsonOK(w, HookResp{Status: "ignored", Runs: []string{}})
+		return
+	}
+
+	runs, err := h.trigger(r.Context(), event, branch)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(runs) == 0 {
+		jsonOK(w, HookResp{Status: "ignored", Runs: runs})
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	jsonOK(w, HookResp{Status: "accepted", Runs: runs})
+}
+
+// trigger starts a PipelineRun for every unsuspended Pipeline delivering to an
+// App that tracks the pushed repository and branch. It returns the
+// "namespace/name" of each run created.
+func (h *HooksHandler) trigger(ctx context.Context, event *pushEvent, branch string) ([]string, error) {
+	repos := make(map[string]bool, len(event.RepoURLs))
+	for _, u := range event.RepoURLs {
+		if u != "" {
+			repos[normalizeRepoURL(u)] = true
+		}
+	}
+
+	apps := &k8stypes.AppList{}
+	if err := h.client.List(ctx, apps); err != nil {
+		return nil, err
+	}
+	runs := []string{}
+	for _, app := range apps.Items {
+		appBranch := app.Spec.Branch
+		if appBranch == "" {
+			appBranch = "main"
+		}
+		if appBranch != branch || !repos[normalizeRepoURL(app.Spec.RepoUrl)] {
+			continue
+		}
+		pipelines := &k8stypes.PipelineList{}
+		if err := h.client.List(ctx, pipelines, client.InNamespace(app.Namespace)); err != nil {
+			return nil, err
+		}
+		for i := range pipelines.Items {
+			p := &pipelines.Items[i]
+			if p.Spec.AppRef != app.Name || p.Spec.Suspended {
+				continue
+			}
+			run := newPipelineRun(p, k8stypes.PipelineTriggerWebhook, event.After, event.Author)
+			if err := h.client.Create(ctx, run); err != nil {
+				return nil, err
+			}
+			runs = append(runs, run.Namespace+"/"+run.Name)
+		}
+	}
+	return runs, nil
+}
+
+// ─── payload decoding ────────────────────────────────────────────────────────
+
+// githubStylePush covers GitHub and Gitea, whose push payloads share a shape.
+type githubStylePush struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+		HTMLURL  string `json:"html_url"`
+		SSHURL   string `json:"ssh_url"`
+	} `json:"repository"`
+	HeadCommit *struct {
+		Author struct {
+			Name     string `json:"name"`
+			Username string `json:"username"`
+		} `json:"author"`
+	} `json:"head_commit"`
+	Pusher struct {
+		Name     string `json:"name"`
+		Login    string `json:"login"`
+		Username string `json:"username"`
+	} `json:"pusher"`
+}
+
+func parseGitHubStylePush(body []byte) (*pushEvent, error) {
+	var p githubStylePush
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	author := firstNonEmpty(p.Pusher.Username, p.Pusher.Login, p.Pusher.Name)
+	if p.HeadCommit != nil {
+		author = firstNonEmpty(p.HeadCommit.Author.Username, p.HeadCommit.Author.Name, author)
+	}
+	return &pushEvent{
+		Ref:      p.Ref,
+		After:    p.After,
+		RepoURLs: []string{p.Repository.CloneURL, p.Repository.HTMLURL, p.Repository.SSHURL},
+		Author:   author,
+	}, nil
+}
+
+type gitlabPush struct {
+	Ref          string `json:"ref"`
+	After        string `json:"after"`
+	UserName     string `json:"user_name"`
+	UserUsername string `json:"user_username"`
+	Project      struct {
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+}
+
+func parseGitLabPush(body []byte) (*pushEvent, error) {
+	var p gitlabPush
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	return &pushEvent{
+		Ref:      p.Ref,
+		After:    p.After,
+		RepoURLs: []string{p.Project.GitHTTPURL, p.Project.WebURL, p.Project.GitSSHURL},
+		Author:   firstNonEmpty(p.UserUsername, p.UserName),
+	}, nil
+}
+
+// ─── helpers ─────────────────────────────────────────────────────────────────
+
+// validHMACSHA256 reports whether sig is the hex HMAC-SHA256 of body.
+func validHMACSHA256(sig string, body, secret []byte) bool {
+	want, err := hex.DecodeString(sig)
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(want, mac.Sum(nil))
+}
+
+// normalizeRepoURL reduces HTTPS, SSH and scp-style Git URLs to a comparable
+// "host/owner/repo" form.
+func normalizeRepoURL(raw string) string {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if rest, ok := strings.CutPrefix(s, "git@"); ok {
+		s = strings.Replace(rest, ":", "/", 1)
+	} else if u, err := url.Parse(s); err == nil && u.Host != "" {
+		s = u.Hostname() + u.Path
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
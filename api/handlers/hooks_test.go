@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testHookSecret = "s3cr3t"
+
+func newHooksTestServer(t *testing.T, objs ...client.Object) (http.Handler, client.Client) {
+	t.Helper()
+	scheme, err := k8stypes.NewScheme()
+	if err != nil {
+		t.Fatalf("scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	h := &HooksHandler{client: c, secret: []byte(testHookSecret)}
+	r := chi.NewRouter()
+	r.Post("/api/hooks/{provider}", h.Receive)
+	return r, c
+}
+
+func hookFixtures() []client.Object {
+	return []client.Object{
+		&k8stypes.App{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       k8stypes.AppSpec{RepoUrl: "https://github.com/acme/web", Branch: "main"},
+		},
+		&k8stypes.App{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-gitlab", Namespace: "team-a"},
+			Spec:       k8stypes.AppSpec{RepoUrl: "git@gitlab.example.com:acme/web.git"},
+		},
+		&k8stypes.App{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-gitea", Namespace: "default"},
+			Spec:       k8stypes.AppSpec{RepoUrl: "https://gitea.example.com/acme/web.git", Branch: "develop"},
+		},
+		&k8stypes.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-build", Namespace: "default", UID: "uid-web-build"},
+			Spec:       k8stypes.PipelineSpec{AppRef: "web"},
+		},
+		&k8stypes.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-build", Namespace: "team-a", UID: "uid-team-a"},
+			Spec:       k8stypes.PipelineSpec{AppRef: "web-gitlab"},
+		},
+		&k8stypes.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-gitea-build", Namespace: "default", UID: "uid-gitea"},
+			Spec:       k8stypes.PipelineSpec{AppRef: "web-gitea"},
+		},
+	}
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testHookSecret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHooksReceive(t *testing.T) {
+	github := readFixture(t, "github_push.json")
+	gitlab := readFixture(t, "gitlab_push.json")
+	gitea := readFixture(t, "gitea_push.json")
+
+	tests := []struct {
+		name        string
+		provider    string
+		body        []byte
+		headers     map[string]string
+		wantStatus  int
+		wantRuns    int
+		wantNS      string
+		wantSHA     string
+		wantTrigger string
+	}{
+		{
+			name:     "github push starts a run",
+			provider: "github",
+			body:     github,
+			headers: map[string]string{
+				"X-GitHub-Event":      "push",
+				"X-Hub-Signature-256": "sha256=" + sign(github),
+			},
+			wantStatus:  http.StatusAccepted,
+			wantRuns:    1,
+			wantNS:      "default",
+			wantSHA:     "3a7c5e1f9b2d4c6a8e0f1b3d5c7a9e2f4b6d8c0a",
+			wantTrigger: "monalisa",
+		},
+		{
+			name:     "gitlab push matches ssh repo url and default branch",
+			provider: "gitlab",
+			body:     gitlab,
+			headers: map[string]string{
+				"X-Gitlab-Event": "Push Hook",
+				"X-Gitlab-Token": testHookSecret,
+			},
+			wantStatus:  http.StatusAccepted,
+			wantRuns:    1,
+			wantNS:      "team-a",
+			wantSHA:     "5b2d8f1a3c6e9b0d4f7a2c5e8b1d3f6a9c0e2b4d",
+			wantTrigger: "jdoe",
+		},
+		{
+			name:     "gitea push starts a run",
+			provider: "gitea",
+			body:     gitea,
+			headers: map[string]string{
+				"X-Gitea-Event":     "push",
+				"X-Gitea-Signature": sign(gitea),
+			},
+			wantStatus:  http.StatusAccepted,
+			wantRuns:    1,
+			wantNS:      "default",
+			wantSHA:     "7e4a1c9f2b5d8e0a3c6f9b2d5e8a1c4f7b0d3e6a",
+			wantTrigger: "slee",
+		},
+		{
+			name:     "bad github signature is rejected",
+			provider: "github",
+			body:     github,
+			headers: map[string]string{
+				"X-GitHub-Event":      "push",
+				"X-Hub-Signature-256": "sha256=" + sign([]byte("tampered")),
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:     "bad gitlab token is rejected",
+			provider: "gitlab",
+			body:     gitlab,
+			headers: map[string]string{
+				"X-Gitlab-Event": "Push Hook",
+				"X-Gitlab-Token": "wrong",
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:     "non-push event is ignored",
+			provider: "github",
+			body:     github,
+			headers: map[string]string{
+				"X-GitHub-Event":      "ping",
+				"X-Hub-Signature-256": "sha256=" + sign(github),
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown provider",
+			provider:   "bitbucket",
+			body:       github,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newHooksTestServer(t, hookFixtures()...)
+			req := httptest.NewRequest(http.MethodPost, "/api/hooks/"+tt.provider, bytes.NewReader(tt.body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			runs := &k8stypes.PipelineRunList{}
+			if err := c.List(context.Background(), runs); err != nil {
+				t.Fatalf("list runs: %v", err)
+			}
+			if len(runs.Items) != tt.wantRuns {
+				t.Fatalf("runs = %d, want %d", len(runs.Items), tt.wantRuns)
+			}
+			if tt.wantRuns == 0 {
+				return
+			}
+			run := runs.Items[0]
+			if run.Namespace != tt.wantNS {
+				t.Errorf("namespace = %q, want %q", run.Namespace, tt.wantNS)
+			}
+			if run.Spec.Trigger != k8stypes.PipelineTriggerWebhook {
+				t.Errorf("trigger = %q, want Webhook", run.Spec.Trigger)
+			}
+			if run.Spec.Revision != tt.wantSHA {
+				t.Errorf("revision = %q, want %q", run.Spec.Revision, tt.wantSHA)
+			}
+			if run.Spec.TriggeredBy != tt.wantTrigger {
+				t.Errorf("triggeredBy = %q, want %q", run.Spec.TriggeredBy, tt.wantTrigger)
+			}
+			if len(run.OwnerReferences) != 1 || run.OwnerReferences[0].Kind != "Pipeline" {
+				t.Errorf("ownerReferences = %+v, want a single Pipeline owner", run.OwnerReferences)
+			}
+		})
+	}
+}
+
+func TestHooksReceiveSkipsOtherBranches(t *testing.T) {
+	body := bytes.Replace(readFixture(t, "github_push.json"),
+		[]byte(`"refs/heads/main"`), []byte(`"refs/heads/feature/x"`), 1)
+	srv, c := newHooksTestServer(t, hookFixtures()...)
+	req := httptest.NewRequest(http.MethodPost, "/api/hooks/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-Hub-Signature-256", "sha256="+sign(body))
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	runs := &k8stypes.PipelineRunList{}
+	if err := c.List(context.Background(), runs); err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs.Items) != 0 {
+		t.Fatalf("runs = %d, want 0", len(runs.Items))
+	}
+}
+
+func TestNormalizeRepoURL(t *testing.T) {
+	want := "github.com/acme/web"
+	for _, in := range []string{
+		"https://github.com/acme/web",
+		"https://github.com/acme/web.git",
+		"https://GitHub.com/acme/web/",
+		"git@github.com:acme/web.git",
+		"ssh://git@github.com/acme/web.git",
+	} {
+		if got := normalizeRepoURL(in); got != want {
+			t.Errorf("normalizeRepoURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// defaultLogTailLines bounds snapshot requests that do not set tailLines.
+	defaultLogTailLines = int64(500)
+	// podRescanInterval is how often a follow stream looks for new pods,
+	// e.g. replicas created by a rollout.
+	podRescanInterval = 5 * time.Second
+	// maxLogLineBytes is the longest single log line relayed to clients.
+	maxLogLineBytes = 1 << 20
+)
+
+// logOptions are the query parameters accepted by the logs endpoint.
+type logOptions struct {
+	container    string
+	tailLines    *int64
+	sinceSeconds *int64
+	previous     bool
+	follow       bool
+}
+
+func parseLogOptions(q url.Values) (logOptions, error) {
+	opts := logOptions{container: q.Get("container")}
+	if v := q.Get("tailLines"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("tailLines must be a non-negative integer")
+		}
+		opts.tailLines = &n
+	}
+	if v := q.Get("sinceSeconds"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return opts, fmt.Errorf("sinceSeconds must be a positive integer")
+		}
+		opts.sinceSeconds = &n
+	}
+	for name, dst := range map[string]*bool{"previous": &opts.previous, "follow": &opts.follow} {
+		if v := q.Get(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return opts, fmt.Errorf("%s must be a boolean", name)
+			}
+			*dst = b
+		}
+	}
+	if opts.previous && opts.follow {
+		return opts, fmt.Errorf("previous and follow cannot be combined")
+	}
+	return opts, nil
+}
+
+// podLogOptions converts the request options for a pod of the given App.
+// The App's own container is selected unless another one is requested.
+func (o logOptions) podLogOptions(app *k8stypes.App) *corev1.PodLogOptions {
+	container := o.container
+	if container == "" {
+		container = app.Name
+	}
+	tail := o.tailLines
+	if tail == nil && !o.follow {
+		n := defaultLogTailLines
+		tail = &n
+	}
+	return &corev1.PodLogOptions{
+		Container:    container,
+		TailLines:    tail,
+		SinceSeconds: o.sinceSeconds,
+		Previous:     o.previous,
+		Follow:       o.follow,
+		Timestamps:   !o.follow,
+	}
+}
+
+// logs returns recent log lines from every pod of the App, merged by
+// timestamp and prefixed with the pod name. With follow=true the response is
+// a Server-Sent Events stream that keeps relaying new lines until the client
+// disconnects.
+func (h *AppsHandler) logs(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	app, err := h.fetchApp(r.Context(), id)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	opts, err := parseLogOptions(r.URL.Query())
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if opts.follow {
+		h.streamLogs(w, r, app, opts)
+		return
+	}
+	pods, err := h.appPods(r.Context(), app)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonOK(w, h.podLogs(r.Context(), app, pods, opts))
+}
+
+// appPods lists the App's workload pods that have started, sorted by name.
+func (h *AppsHandler) appPods(ctx context.Context, app *k8stypes.App) ([]corev1.Pod, error) {
+	selector := labels.SelectorFromSet(map[string]string{
+		"app.kubernetes.io/name":       app.Name,
+		"app.kubernetes.io/managed-by": "flowcd-operator",
+	})
+	list, err := h.clientset.CoreV1().Pods(appNamespace(app)).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]corev1.Pod, 0, len(list.Items))
+	for _, pod := range list.Items {
+		if pod.Status.Phase != corev1.PodPending {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, nil
+}
+
+// podLogs fetches a log snapshot from each pod and merges the lines in
+// timestamp order. A pod whose logs cannot be read contributes an error line
+// instead of failing the whole request.
+func (h *AppsHandler) podLogs(ctx context.Context, app *k8stypes.App, pods []corev1.Pod, opts logOptions) []string {
+	type logLine struct {
+		at   time.Time
+		text string
+	}
+	var lines []logLine
+	for _, pod := range pods {
+		raw, err := h.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts.podLogOptions(app)).DoRaw(ctx)
+		if err != nil {
+			lines = append(lines, logLine{text: fmt.Sprintf("[%s] failed to fetch logs: %v", pod.Name, err)})
+			continue
+		}
+		for _, l := range strings.Split(string(raw), "\n") {
+			if l == "" {
+				continue
+			}
+			at, text := splitLogTimestamp(l)
+			lines = append(lines, logLine{at: at, text: "[" + pod.Name + "] " + text})
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].at.Before(lines[j].at) })
+
+	resp := make([]string, 0, len(lines))
+	for _, l := range lines {
+		resp = append(resp, l.text)
+	}
+	return resp
+}
+
+// splitLogTimestamp separates the RFC 3339 timestamp the kubelet prepends
+// when timestamps are requested. Lines without one get the zero time.
+func splitLogTimestamp(line string) (time.Time, string) {
+	ts, rest, ok := strings.Cut(line, " ")
+	if !ok {
+		return time.Time{}, line
+	}
+	at, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, line
+	}
+	return at, rest
+}
+
+// streamLogs follows the logs of every App pod over Server-Sent Events. Each
+// line is sent as a "data:" event prefixed with the pod name; new pods are
+// picked up as they start and a pod whose stream ends is resumed from where it
+// stopped if it is still running.
+func (h *AppsHandler) streamLogs(w http.ResponseWriter, r *http.Request, app *k8stypes.App, opts logOptions) {
+	sse, ok := startSSE(w)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	lines := make(chan string, 64)
+	ended := make(chan string)
+	following := map[string]bool{}
+	resumeAt := map[string]metav1.Time{}
+
+	scan := func() {
+		pods, err := h.appPods(ctx, app)
+		if err != nil {
+			sse.send("error", err.Error())
+			return
+		}
+		for _, pod := range pods {
+			if following[pod.Name] || pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			podOpts := opts.podLogOptions(app)
+			if since, ok := resumeAt[pod.Name]; ok {
+				podOpts.TailLines = nil
+				podOpts.SinceSeconds = nil
+				podOpts.SinceTime = &since
+			}
+			following[pod.Name] = true
+			go h.followPod(ctx, pod, podOpts, lines, ended)
+		}
+	}
+
+	scan()
+	ticker := time.NewTicker(podRescanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case line := <-lines:
+			sse.send("", line)
+		case name := <-ended:
+			delete(following, name)
+			resumeAt[name] = metav1.Now()
+		case <-ticker.C:
+			scan()
+		}
+	}
+}
+
+// followPod relays one pod's log stream line by line until it ends or ctx is
+// cancelled, then reports the pod name on ended.
+func (h *AppsHandler) followPod(ctx context.Context, pod corev1.Pod, opts *corev1.PodLogOptions, lines chan<- string, ended chan<- string) {
+	defer func() {
+		select {
+		case ended <- pod.Name:
+		case <-ctx.Done():
+		}
+	}()
+	send := func(line string) bool {
+		select {
+		case lines <- "[" + pod.Name + "] " + line:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	stream, err := h.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).Stream(ctx)
+	if err != nil {
+		send("failed to stream logs: " + err.Error())
+		return
+	}
+	defer func() { _ = stream.Close() }()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineBytes)
+	for scanner.Scan() {
+		if !send(scanner.Text()) {
+			return
+		}
+	}
+}
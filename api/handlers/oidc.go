@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+	"k8s.io/client-go/kubernetes"
+)
+
+// OIDCConfig configures single sign-on through an OpenID Connect provider.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is this API's /api/auth/oidc/callback URL as registered
+	// with the provider.
+	RedirectURL string
+	Scopes      []string
+	// GroupsClaim names the ID token claim listing the user's groups.
+	GroupsClaim string
+	// RoleMapping maps provider groups to roles; a user in several mapped
+	// groups gets the most privileged role.
+	RoleMapping map[string]Role
+	// DefaultRole is given to users in no mapped group. When empty they are
+	// refused.
+	DefaultRole Role
+	// PostLoginURL is the UI page the callback redirects to, with the session
+	// token in the URL fragment. When empty the callback responds with JSON.
+	PostLoginURL string
+}
+
+// OIDCConfigFromEnv reads the OIDC_* env vars. It returns nil when
+// OIDC_ISSUER_URL is unset, leaving local login as the only option.
+func OIDCConfigFromEnv() (*OIDCConfig, error) {
+	issuer := os.Getenv("OIDC_ISSUER_URL")
+	if issuer == "" {
+		return nil, nil
+	}
+	cfg := &OIDCConfig{
+		IssuerURL:    issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile", "groups"},
+		GroupsClaim:  "groups",
+		PostLoginURL: os.Getenv("OIDC_POST_LOGIN_URL"),
+	}
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required with OIDC_ISSUER_URL")
+	}
+	if s := os.Getenv("OIDC_SCOPES"); s != "" {
+		cfg.Scopes = strings.Fields(strings.ReplaceAll(s, ",", " "))
+	}
+	if c := os.Getenv("OIDC_GROUPS_CLAIM"); c != "" {
+		cfg.GroupsClaim = c
+	}
+	mapping, err := parseRoleMapping(os.Getenv("OIDC_ROLE_MAPPING"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.RoleMapping = mapping
+	if d := os.Getenv("OIDC_DEFAULT_ROLE"); d != "" {
+		role, ok := parseRole(d)
+		if !ok {
+			return nil, fmt.Errorf("invalid OIDC_DEFAULT_ROLE %q: must be Admin, Developer or Viewer", d)
+		}
+		cfg.DefaultRole = role
+	}
+	return cfg, nil
+}
+
+// parseRoleMapping parses "group=Role" pairs separated by commas, such as
+// "platform-admins=Admin,engineering=Developer".
+func parseRoleMapping(s string) (map[string]Role, error) {
+	mapping := map[string]Role{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		group, roleName, ok := strings.Cut(pair, "=")
+		group = strings.TrimSpace(group)
+		role, valid := parseRole(strings.TrimSpace(roleName))
+		if !ok || group == "" || !valid {
+			return nil, fmt.Errorf("invalid OIDC role mapping %q: want group=Admin|Developer|Viewer", pair)
+		}
+		mapping[group] = role
+	}
+	return mapping, nil
+}
+
+// oidcStateCookie carries the state, nonce and PKCE verifier of a login in
+// progress, signed with the session keys so the callback can trust it on any
+// API replica.
+const (
+	oidcStateCookie = "flowcd_oidc"
+	oidcStateTTL    = 10 * time.Minute
+)
+
+type oidcState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// OIDCHandler signs users in through an OpenID Connect provider with the
+// authorization-code flow and PKCE, and issues the same session tokens as
+// local login.
+type OIDCHandler struct {
+	cfg      OIDCConfig
+	sessions *sessionStore
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCHandler(cfg OIDCConfig, cs kubernetes.Interface) *OIDCHandler {
+	return &OIDCHandler{cfg: cfg, sessions: newSessionStore(cs)}
+}
+
+func (h *OIDCHandler) Routes(r chi.Router) {
+	r.Get("/login", h.login)
+	r.Get("/callback", h.callback)
+}
+
+// discover fetches the provider's discovery document on first use, so the API
+// starts even while the provider is unreachable.
+func (h *OIDCHandler) discover(ctx context.Context) (*oidc.Provider, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.provider != nil {
+		return h.provider, nil
+	}
+	p, err := oidc.NewProvider(ctx, h.cfg.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+	h.provider = p
+	return p, nil
+}
+
+func (h *OIDCHandler) oauth2Config(p *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     h.cfg.ClientID,
+		ClientSecret: h.cfg.ClientSecret,
+		RedirectURL:  h.cfg.RedirectURL,
+		Endpoint:     p.Endpoint(),
+		Scopes:       h.cfg.Scopes,
+	}
+}
+
+// login redirects the browser to the provider.
+func (h *OIDCHandler) login(w http.ResponseWriter, r *http.Request) {
+	p, err := h.discover(r.Context())
+	if err != nil {
+		jsonError(w, "identity provider discovery failed: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	state, err := randomToken(24)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	nonce, err := randomToken(24)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+	signed, err := sessionKeys.sign(oidcState{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(oidcStateTTL)),
+		},
+	})
+	if err != nil {
+		jsonError(w, "failed to sign login state", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, h.stateCookie(r, signed, int(oidcStateTTL.Seconds())))
+	authURL := h.oauth2Config(p).AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// callback completes the login: it exchanges the code, validates the ID
+// token against the provider's keys and the login's nonce, and maps the
+// user's groups to a role.
+func (h *OIDCHandler) callback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		jsonError(w, strings.TrimSpace("identity provider refused the login: "+e+" "+q.Get("error_description")), http.StatusUnauthorized)
+		return
+	}
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		jsonError(w, "login session missing or expired; start the login again", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, h.stateCookie(r, "", -1))
+	st := &oidcState{}
+	if _, err := jwt.ParseWithClaims(cookie.Value, st, sessionKeys.keyFunc); err != nil {
+		jsonError(w, "login session missing or expired; start the login again", http.StatusBadRequest)
+		return
+	}
+	if q.Get("state") == "" || q.Get("state") != st.State {
+		jsonError(w, "login state mismatch", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.discover(r.Context())
+	if err != nil {
+		jsonError(w, "identity provider discovery failed: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	token, err := h.oauth2Config(p).Exchange(r.Context(), q.Get("code"), oauth2.VerifierOption(st.Verifier))
+	if err != nil {
+		jsonError(w, "code exchange failed: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		jsonError(w, "identity provider returned no ID token", http.StatusUnauthorized)
+		return
+	}
+	idToken, err := p.Verifier(&oidc.Config{ClientID: h.cfg.ClientID}).Verify(r.Context(), rawIDToken)
+	if err != nil {
+		jsonError(w, "invalid ID token: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if idToken.Nonce != st.Nonce {
+		jsonError(w, "ID token nonce mismatch", http.StatusUnauthorized)
+		return
+	}
+	claims := map[string]any{}
+	if err := idToken.Claims(&claims); err != nil {
+		jsonError(w, "invalid ID token claims: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	email, _ := claims["email"].(string)
+	if email == "" {
+		jsonError(w, "ID token has no email claim", http.StatusUnauthorized)
+		return
+	}
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		jsonError(w, "email "+email+" is not verified by the identity provider", http.StatusForbidden)
+		return
+	}
+	name, _ := claims["name"].(string)
+	if name == "" {
+		name = email
+	}
+	role, ok := h.role(groupsClaim(claims[h.cfg.GroupsClaim]))
+	if !ok {
+		jsonError(w, "none of your groups is mapped to a FlowCD role", http.StatusForbidden)
+		return
+	}
+
+	resp, err := startSession(r.Context(), h.sessions, session{Email: normalizeEmail(email), Name: name, Role: role})
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if h.cfg.PostLoginURL == "" {
+		jsonOK(w, resp)
+		return
+	}
+	fragment := url.Values{
+		"token":        {resp.Token},
+		"refreshToken": {resp.RefreshToken},
+		"expiresIn":    {strconv.Itoa(resp.ExpiresIn)},
+		"email":        {resp.Email},
+		"name":         {resp.Name},
+		"role":         {resp.Role},
+	}
+	http.Redirect(w, r, h.cfg.PostLoginURL+"#"+fragment.Encode(), http.StatusFound)
+}
+
+// roleRank orders roles from least to most privileged.
+var roleRank = map[Role]int{RoleViewer: 1, RoleDeveloper: 2, RoleAdmin: 3}
+
+// role returns the most privileged role mapped to any of groups, falling
+// back to the default role.
+func (h *OIDCHandler) role(groups []string) (Role, bool) {
+	best := h.cfg.DefaultRole
+	for _, g := range groups {
+		if role, ok := h.cfg.RoleMapping[g]; ok && roleRank[role] > roleRank[best] {
+			best = role
+		}
+	}
+	return best, best != ""
+}
+
+func (h *OIDCHandler) stateCookie(r *http.Request, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     "/api/auth/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// groupsClaim reads a groups claim given either as a list or, as some
+// providers do for a single group, a string.
+func groupsClaim(v any) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	return claimStrings(v)
+}
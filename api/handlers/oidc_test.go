@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// stubIssuer is a minimal OIDC provider: it serves discovery and keys,
+// approves every authorization request and checks the PKCE verifier when
+// the code is exchanged.
+type stubIssuer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	// claims are added to every ID token; nonce, when set, replaces the one
+	// from the authorization request.
+	claims jwt.MapClaims
+	nonce  string
+
+	mu    sync.Mutex
+	codes map[string]url.Values
+}
+
+func newStubIssuer(t *testing.T, claims jwt.MapClaims) *stubIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	s := &stubIssuer{key: key, claims: claims, codes: map[string]url.Values{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		jsonOK(w, map[string]any{
+			"issuer":                                s.URL,
+			"authorization_endpoint":                s.URL + "/authorize",
+			"token_endpoint":                        s.URL + "/token",
+			"jwks_uri":                              s.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		jsonOK(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "stub", "alg": "RS256", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		s.mu.Lock()
+		code := "code-" + q.Get("state")
+		s.codes[code] = q
+		s.mu.Unlock()
+		http.Redirect(w, r, q.Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {q.Get("state")}}.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		s.mu.Lock()
+		auth, ok := s.codes[r.PostForm.Get("code")]
+		delete(s.codes, r.PostForm.Get("code"))
+		s.mu.Unlock()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !ok || auth.Get("code_challenge_method") != "S256" ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != auth.Get("code_challenge") {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		nonce := auth.Get("nonce")
+		if s.nonce != "" {
+			nonce = s.nonce
+		}
+		idClaims := jwt.MapClaims{
+			"iss":   s.URL,
+			"sub":   "user-1",
+			"aud":   auth.Get("client_id"),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": nonce,
+		}
+		for k, v := range s.claims {
+			idClaims[k] = v
+		}
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, idClaims)
+		tok.Header["kid"] = "stub"
+		idToken, err := tok.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		jsonOK(w, map[string]any{"access_token": "stub-access", "token_type": "Bearer", "expires_in": 3600, "id_token": idToken})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stubIssuer) config() OIDCConfig {
+	return OIDCConfig{
+		IssuerURL:   s.URL,
+		ClientID:    "flowcd",
+		RedirectURL: "http://flowcd.test/api/auth/oidc/callback",
+		Scopes:      []string{"openid", "email", "groups"},
+		GroupsClaim: "groups",
+		RoleMapping: map[string]Role{"platform-admins": RoleAdmin, "engineering": RoleDeveloper},
+	}
+}
+
+// oidcLogin runs the browser side of a login: it starts at the API, follows
+// the stub's approval, and returns the callback's response. tamper may alter
+// the callback query.
+func oidcLogin(t *testing.T, cfg OIDCConfig, tamper func(url.Values)) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	r.Route("/api/auth/oidc", NewOIDCHandler(cfg, k8sfake.NewClientset()).Routes)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want 302 (body %s)", rec.Code, rec.Body.String())
+	}
+	cookies := rec.Result().Cookies()
+	authURL := rec.Header().Get("Location")
+	if !strings.HasPrefix(authURL, cfg.IssuerURL+"/authorize?") {
+		t.Fatalf("login redirected to %s", authURL)
+	}
+
+	noFollow := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noFollow.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	_ = resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("callback url: %v", err)
+	}
+	q := callback.Query()
+	if tamper != nil {
+		tamper(q)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+q.Encode(), nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOIDCLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		groups   any
+		wantRole Role
+	}{
+		{name: "developer group", groups: []string{"engineering"}, wantRole: RoleDeveloper},
+		{name: "most privileged group wins", groups: []string{"engineering", "platform-admins"}, wantRole: RoleAdmin},
+		{name: "single group as string", groups: "platform-admins", wantRole: RoleAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStubIssuer(t, jwt.MapClaims{"email": "Ada@Example.com", "name": "Ada", "groups": tt.groups})
+			rec := oidcLogin(t, stub.config(), nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("callback status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+			}
+			var got loginResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Email != "ada@example.com" || got.Role != string(tt.wantRole) {
+				t.Errorf("login = %+v, want ada@example.com as %s", got, tt.wantRole)
+			}
+			claims := jwt.MapClaims{}
+			if _, err := jwt.ParseWithClaims(got.Token, claims, func(*jwt.Token) (any, error) { return jwtSecret, nil }); err != nil {
+				t.Fatalf("session token: %v", err)
+			}
+			if claims["role"] != string(tt.wantRole) {
+				t.Errorf("token role = %v, want %s", claims["role"], tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestOIDCLoginRedirectsToUI(t *testing.T) {
+	stub := newStubIssuer(t, jwt.MapClaims{"email": "ada@example.com", "groups": []string{"engineering"}})
+	cfg := stub.config()
+	cfg.PostLoginURL = "http://ui.test/login/callback"
+	rec := oidcLogin(t, cfg, nil)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("callback status = %d, want 302 (body %s)", rec.Code, rec.Body.String())
+	}
+	loc, _ := url.Parse(rec.Header().Get("Location"))
+	fragment, _ := url.ParseQuery(loc.Fragment)
+	if loc.Host != "ui.test" || fragment.Get("token") == "" || fragment.Get("role") != "Developer" {
+		t.Errorf("redirect = %s, want the UI with a Developer token", loc)
+	}
+}
+
+func TestOIDCLoginRefused(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		nonce    string
+		tamper   func(url.Values)
+		wantCode int
+	}{
+		{name: "no mapped group", claims: jwt.MapClaims{"email": "eve@example.com", "groups": []string{"sales"}}, wantCode: http.StatusForbidden},
+		{name: "unverified email", claims: jwt.MapClaims{"email": "eve@example.com", "email_verified": false, "groups": []string{"engineering"}}, wantCode: http.StatusForbidden},
+		{name: "state mismatch", claims: jwt.MapClaims{"email": "eve@example.com"}, tamper: func(q url.Values) { q.Set("state", "forged") }, wantCode: http.StatusBadRequest},
+		{name: "nonce mismatch", claims: jwt.MapClaims{"email": "eve@example.com", "groups": []string{"engineering"}}, nonce: "replayed", wantCode: http.StatusUnauthorized},
+		{name: "provider error", tamper: func(q url.Values) { q.Set("error", "access_denied") }, wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStubIssuer(t, tt.claims)
+			stub.nonce = tt.nonce
+			rec := oidcLogin(t, stub.config(), tt.tamper)
+			if rec.Code != tt.wantCode {
+				t.Errorf("callback status = %d, want %d (body %s)", rec.Code, tt.wantCode, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestOIDCDefaultRole(t *testing.T) {
+	stub := newStubIssuer(t, jwt.MapClaims{"email": "eve@example.com"})
+	cfg := stub.config()
+	cfg.DefaultRole = RoleViewer
+	rec := oidcLogin(t, cfg, nil)
+	var got loginResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &got)
+	if rec.Code != http.StatusOK || got.Role != string(RoleViewer) {
+		t.Errorf("callback = %d %+v, want a Viewer login", rec.Code, got)
+	}
+}
+
+func TestParseRoleMapping(t *testing.T) {
+	got, err := parseRoleMapping(" platform-admins=Admin, engineering = Developer ,")
+	if err != nil || got["platform-admins"] != RoleAdmin || got["engineering"] != RoleDeveloper {
+		t.Errorf("parseRoleMapping = %v, %v", got, err)
+	}
+	for _, bad := range []string{"admins", "=Admin", "admins=Root"} {
+		if _, err := parseRoleMapping(bad); err == nil {
+			t.Errorf("parseRoleMapping(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -15,8 +18,8 @@ type PipelinesHandler struct{ client client.Client }
 func NewPipelinesHandler(c client.Client) *PipelinesHandler { return &PipelinesHandler{client: c} }
 
 func (h *PipelinesHandler) Routes(r chi.Router) {
-	r.Get("/", h.list)
-	r.Get("/{id}", h.get)
+	r.With(Authorize(PermPipelinesRead)).Get("/", h.list)
+	r.With(Authorize(PermPipelinesRead)).Get("/{id}", h.get)
 }
 
 func (h *PipelinesHandler) list(w http.ResponseWriter, r *http.Request) {
@@ -25,9 +28,12 @@ func (h *PipelinesHandler) list(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	scopes := callerScopes(r)
 	resp := make([]PipelineResp, 0, len(list.Items))
 	for _, p := range list.Items {
-		resp = append(resp, toPipelineResp(&p))
+		if inScope(scopes, p.Namespace, p.Spec.AppRef) {
+			resp = append(resp, toPipelineResp(&p, nil))
+		}
 	}
 	jsonOK(w, resp)
 }
@@ -43,10 +49,64 @@ func (h *PipelinesHandler) get(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, "pipeline not found", http.StatusNotFound)
 		return
 	}
-	jsonOK(w, toPipelineResp(pipeline))
+	if !checkScope(w, r, PermPipelinesRead, pipeline.Namespace, pipeline.Spec.AppRef) {
+		return
+	}
+	runs, err := h.listRuns(r.Context(), pipeline)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonOK(w, toPipelineResp(pipeline, runs))
+}
+
+// listRuns returns the Pipeline's recorded runs, newest first.
+func (h *PipelinesHandler) listRuns(ctx context.Context, p *k8stypes.Pipeline) ([]k8stypes.PipelineRun, error) {
+	list := &k8stypes.PipelineRunList{}
+	if err := h.client.List(ctx, list, client.InNamespace(p.Namespace)); err != nil {
+		return nil, err
+	}
+	runs := make([]k8stypes.PipelineRun, 0, len(list.Items))
+	for _, run := range list.Items {
+		if run.Spec.PipelineRef == p.Name {
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[j].CreationTimestamp.Before(&runs[i].CreationTimestamp)
+	})
+	return runs, nil
+}
+
+// newPipelineRun builds a PipelineRun requesting a build of revision for p.
+// The run is owned by the Pipeline so it is garbage collected with it; the
+// operator picks it up and executes it.
+func newPipelineRun(p *k8stypes.Pipeline, trigger k8stypes.PipelineTrigger, revision, triggeredBy string) *k8stypes.PipelineRun {
+	isController := true
+	return &k8stypes.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: p.Name + "-",
+			Namespace:    p.Namespace,
+			Labels:       map[string]string{pipelineLabel: p.Name},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         k8stypes.GroupVersion.String(),
+				Kind:               "Pipeline",
+				Name:               p.Name,
+				UID:                p.UID,
+				Controller:         &isController,
+				BlockOwnerDeletion: &isController,
+			}},
+		},
+		Spec: k8stypes.PipelineRunSpec{
+			PipelineRef: p.Name,
+			Trigger:     trigger,
+			Revision:    revision,
+			TriggeredBy: triggeredBy,
+		},
+	}
 }
 
-func toPipelineResp(p *k8stypes.Pipeline) PipelineResp {
+func toPipelineResp(p *k8stypes.Pipeline, runs []k8stypes.PipelineRun) PipelineResp {
 	id := string(p.UID)
 	if id == "" {
 		id = p.Name
@@ -58,8 +118,9 @@ func toPipelineResp(p *k8stypes.Pipeline) PipelineResp {
 	stages := make([]PipelineStageResp, 0, len(p.Status.Stages))
 	for _, s := range p.Status.Stages {
 		stages = append(stages, PipelineStageResp{
-			Name:   s.Name,
-			Status: strings.ToLower(string(s.Phase)),
+			Name:     s.Name,
+			Status:   strings.ToLower(string(s.Phase)),
+			Duration: float64(s.Duration),
 		})
 	}
 	return PipelineResp{
@@ -70,8 +131,37 @@ func toPipelineResp(p *k8stypes.Pipeline) PipelineResp {
 		LastRunStatus: pipelinePhaseToStatus(p.Status.Phase),
 		LastRunAt:     lastRunAt,
 		Stages:        stages,
-		Runs:          []PipelineRunResp{},
+		Runs:          toPipelineRunResps(runs),
+	}
+}
+
+func toPipelineRunResps(runs []k8stypes.PipelineRun) []PipelineRunResp {
+	resp := make([]PipelineRunResp, 0, len(runs))
+	for _, run := range runs {
+		id := string(run.UID)
+		if id == "" {
+			id = run.Name
+		}
+		startedAt := run.CreationTimestamp.UTC().Format(time.RFC3339)
+		if run.Status.StartedAt != nil {
+			startedAt = run.Status.StartedAt.UTC().Format(time.RFC3339)
+		}
+		triggeredBy := run.Spec.TriggeredBy
+		if triggeredBy == "" {
+			triggeredBy = strings.ToLower(string(run.Spec.Trigger))
+		}
+		resp = append(resp, PipelineRunResp{
+			ID:          id,
+			Status:      pipelinePhaseToStatus(run.Status.Phase),
+			StartedAt:   startedAt,
+			Duration:    float64(run.Status.Duration),
+			TriggeredBy: triggeredBy,
+			Trigger:     strings.ToLower(string(run.Spec.Trigger)),
+			CommitSha:   run.Status.CommitSHA,
+			Image:       run.Status.Image,
+		})
 	}
+	return resp
 }
 
 func pipelinePhaseToStatus(phase k8stypes.PipelinePhase) string {
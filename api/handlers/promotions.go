@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// promote requests that the App take an image already rolled out by the App
+// named in its spec.promotion.from. Without an explicit revision the source's
+// current revision is promoted. The operator applies the promotion, or holds
+// it for approval when the App requires one.
+func (h *AppsHandler) promote(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	app, err := h.fetchApp(r.Context(), id)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if app.Spec.Promotion == nil {
+		jsonError(w, fmt.Sprintf("app %s has no promotion source", app.Name), http.StatusConflict)
+		return
+	}
+	var body struct {
+		Revision int64 `json:"revision"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		jsonError(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from := app.Spec.Promotion.From
+	if from.Namespace == "" {
+		from.Namespace = app.Namespace
+	}
+	// Promoting reveals the source's revisions and images.
+	if !checkScope(w, r, PermAppsRead, from.Namespace, from.Name) {
+		return
+	}
+	source, err := h.fetchApp(r.Context(), from.Namespace+"/"+from.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			jsonError(w, fmt.Sprintf("source app %s/%s not found", from.Namespace, from.Name), http.StatusConflict)
+		} else {
+			jsonError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	rev := promotableRevision(source, body.Revision)
+	if rev == nil {
+		if body.Revision != 0 {
+			jsonError(w, fmt.Sprintf("revision %d of %s/%s was not rolled out successfully", body.Revision, from.Namespace, from.Name), http.StatusConflict)
+		} else {
+			jsonError(w, fmt.Sprintf("app %s/%s has no successful rollout to promote", from.Namespace, from.Name), http.StatusConflict)
+		}
+		return
+	}
+
+	actor, _ := r.Context().Value(contextKeyEmail).(string)
+	promotion := newAppPromotion(app, rev.Image, actor)
+	if err := h.client.Create(r.Context(), promotion); err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	jsonOK(w, toPromotionResp(app, promotion))
+}
+
+// promotions lists the App's promotions, newest first.
+func (h *AppsHandler) promotions(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	app, err := h.fetchApp(r.Context(), id)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	list := &k8stypes.AppPromotionList{}
+	if err := h.client.List(r.Context(), list, client.InNamespace(app.Namespace)); err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	items := make([]k8stypes.AppPromotion, 0, len(list.Items))
+	for _, p := range list.Items {
+		if p.Spec.App == app.Name {
+			items = append(items, p)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[j].CreationTimestamp.Before(&items[i].CreationTimestamp)
+	})
+	resp := make([]PromotionResp, 0, len(items))
+	for i := range items {
+		resp = append(resp, toPromotionResp(app, &items[i]))
+	}
+	jsonOK(w, resp)
+}
+
+func (h *AppsHandler) approvePromotion(w http.ResponseWriter, r *http.Request) {
+	h.decidePromotion(w, r, k8stypes.AppPromotionDecisionApproved)
+}
+
+func (h *AppsHandler) rejectPromotion(w http.ResponseWriter, r *http.Request) {
+	h.decidePromotion(w, r, k8stypes.AppPromotionDecisionRejected)
+}
+
+// decidePromotion records the caller's decision on a promotion that has not
+// finished yet; the operator acts on it.
+func (h *AppsHandler) decidePromotion(w http.ResponseWriter, r *http.Request, decision k8stypes.AppPromotionDecision) {
+	id := chi.URLParam(r, "id")
+	app, err := h.fetchApp(r.Context(), id)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	promotion := &k8stypes.AppPromotion{}
+	key := client.ObjectKey{Namespace: app.Namespace, Name: chi.URLParam(r, "promotion")}
+	if err := h.client.Get(r.Context(), key, promotion); err != nil || promotion.Spec.App != app.Name {
+		jsonError(w, fmt.Sprintf("promotion %s not found", key.Name), http.StatusNotFound)
+		return
+	}
+	switch promotion.Status.Phase {
+	case "", k8stypes.AppPromotionPhasePendingApproval:
+	default:
+		jsonError(w, fmt.Sprintf("promotion %s is already %s", promotion.Name, strings.ToLower(string(promotion.Status.Phase))), http.StatusConflict)
+		return
+	}
+	if promotion.Spec.Decision != "" {
+		jsonError(w, fmt.Sprintf("promotion %s was already %s", promotion.Name, strings.ToLower(string(promotion.Spec.Decision))), http.StatusConflict)
+		return
+	}
+
+	actor, _ := r.Context().Value(contextKeyEmail).(string)
+	// An approval gate means a second pair of eyes.
+	if decision == k8stypes.AppPromotionDecisionApproved && actor != "" && actor == promotion.Spec.RequestedBy &&
+		app.Spec.Promotion != nil && app.Spec.Promotion.RequireApproval {
+		forbidden(w, ForbiddenResp{
+			Error:      fmt.Sprintf("promotion %s must be approved by someone other than its requester", promotion.Name),
+			Permission: string(PermPromotionsApprove),
+			Role:       string(callerRole(r)),
+			Scope:      app.Namespace + "/" + app.Name,
+		})
+		return
+	}
+	patch := client.MergeFrom(promotion.DeepCopy())
+	promotion.Spec.Decision = decision
+	promotion.Spec.DecidedBy = actor
+	if err := h.client.Patch(r.Context(), promotion, patch); err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonOK(w, toPromotionResp(app, promotion))
+}
+
+// promotableRevision returns the source App's revision to promote: the given
+// one, or its current revision. Revisions that were rolled back are never
+// promoted.
+func promotableRevision(source *k8stypes.App, revision int64) *k8stypes.AppRevision {
+	if revision == 0 {
+		revision = source.Status.CurrentRevision
+	}
+	for i := range source.Status.History {
+		rev := &source.Status.History[i]
+		if rev.Revision == revision && rev.Status != k8stypes.AppRevisionRolledBack && rev.Image != "" {
+			return rev
+		}
+	}
+	return nil
+}
+
+func newAppPromotion(app *k8stypes.App, image, requestedBy string) *k8stypes.AppPromotion {
+	isController := true
+	return &k8stypes.AppPromotion{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: app.Name + "-",
+			Namespace:    app.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         k8stypes.GroupVersion.String(),
+				Kind:               "App",
+				Name:               app.Name,
+				UID:                app.UID,
+				Controller:         &isController,
+				BlockOwnerDeletion: &isController,
+			}},
+		},
+		Spec: k8stypes.AppPromotionSpec{
+			App:         app.Name,
+			Image:       image,
+			RequestedBy: requestedBy,
+		},
+	}
+}
+
+func toPromotionResp(app *k8stypes.App, p *k8stypes.AppPromotion) PromotionResp {
+	resp := PromotionResp{
+		ID:          p.Name,
+		AppID:       string(app.UID),
+		Image:       p.Spec.Image,
+		Status:      "pending",
+		RequestedBy: p.Spec.RequestedBy,
+		DecidedBy:   p.Spec.DecidedBy,
+		Revision:    p.Status.Revision,
+		CommitSha:   p.Status.CommitSHA,
+		Message:     p.Status.Message,
+		CreatedAt:   p.CreationTimestamp.UTC().Format(time.RFC3339),
+	}
+	switch p.Status.Phase {
+	case k8stypes.AppPromotionPhasePendingApproval:
+		resp.Status = "pending_approval"
+	case k8stypes.AppPromotionPhasePromoted:
+		resp.Status = "promoted"
+	case k8stypes.AppPromotionPhaseRejected:
+		resp.Status = "rejected"
+	case k8stypes.AppPromotionPhaseFailed:
+		resp.Status = "failed"
+	}
+	if p.Status.Image != "" {
+		resp.Image = p.Status.Image
+	}
+	if p.Status.Source != nil {
+		resp.Source = p.Status.Source.Namespace + "/" + p.Status.Source.Name
+	}
+	if p.Status.PromotedAt != nil {
+		resp.PromotedAt = p.Status.PromotedAt.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	k8stypes "github.com/nimi-io/FlowCD/api/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// promotionApps returns a staging App with history and a production App that
+// promotes from it.
+func promotionApps() (*k8stypes.App, *k8stypes.App) {
+	staging := appWithHistory()
+	staging.Name = "web-staging"
+	prod := &k8stypes.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-prod", Namespace: "default", UID: "prod-uid"},
+		Spec: k8stypes.AppSpec{
+			RepoUrl:   "https://github.com/acme/web",
+			Image:     "ghcr.io/acme/web:v1",
+			Promotion: &k8stypes.AppPromotionPolicy{From: k8stypes.AppReference{Name: "web-staging"}},
+		},
+	}
+	return staging, prod
+}
+
+func TestAppsPromote(t *testing.T) {
+	staging, prod := promotionApps()
+	srv, c := newAppsTestServer(t, k8sfake.NewClientset(), staging, prod)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/apps/web-prod/promote", nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201 (body %s)", rec.Code, rec.Body.String())
+	}
+	var got PromotionResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Image != "ghcr.io/acme/web:v3" || got.Status != "pending" {
+		t.Errorf("promotion = %+v, want pending ghcr.io/acme/web:v3", got)
+	}
+
+	list := &k8stypes.AppPromotionList{}
+	if err := c.List(context.Background(), list); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("promotions = %d, want 1", len(list.Items))
+	}
+	p := list.Items[0]
+	if p.Spec.App != "web-prod" || !strings.HasPrefix(p.Name, "web-prod-") {
+		t.Errorf("promotion %s targets %q", p.Name, p.Spec.App)
+	}
+	if owner := metav1.GetControllerOf(&p); owner == nil || owner.Name != "web-prod" {
+		t.Errorf("owner = %+v, want App web-prod", owner)
+	}
+}
+
+func TestAppsPromoteRevision(t *testing.T) {
+	staging, prod := promotionApps()
+	staging.Status.History[1].Status = k8stypes.AppRevisionRolledBack
+	srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), staging, prod)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		want     string
+	}{
+		{name: "older revision", body: `{"revision":1}`, wantCode: http.StatusCreated, want: "ghcr.io/acme/web:v1"},
+		{name: "rolled back revision", body: `{"revision":2}`, wantCode: http.StatusConflict},
+		{name: "unknown revision", body: `{"revision":9}`, wantCode: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/apps/web-prod/promote", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.want == "" {
+				return
+			}
+			var got PromotionResp
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Image != tt.want {
+				t.Errorf("image = %q, want %q", got.Image, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppsPromoteWithoutSource(t *testing.T) {
+	staging, _ := promotionApps()
+	srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), staging)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/apps/web-staging/promote", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409 (body %s)", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAppsPromotionDecision(t *testing.T) {
+	staging, prod := promotionApps()
+	pending := &k8stypes.AppPromotion{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-prod-abc", Namespace: "default"},
+		Spec:       k8stypes.AppPromotionSpec{App: "web-prod", Image: "ghcr.io/acme/web:v3", RequestedBy: "alice"},
+		Status:     k8stypes.AppPromotionStatus{Phase: k8stypes.AppPromotionPhasePendingApproval},
+	}
+	done := &k8stypes.AppPromotion{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-prod-old", Namespace: "default"},
+		Spec:       k8stypes.AppPromotionSpec{App: "web-prod", Image: "ghcr.io/acme/web:v2"},
+		Status:     k8stypes.AppPromotionStatus{Phase: k8stypes.AppPromotionPhasePromoted},
+	}
+	srv, c := newAppsTestServer(t, k8sfake.NewClientset(), staging, prod, pending, done)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/apps/web-prod/promotions/web-prod-abc/approve", nil)
+	req = req.WithContext(context.WithValue(req.Context(), contextKeyEmail, "bob"))
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("approve status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+	}
+	got := &k8stypes.AppPromotion{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "web-prod-abc"}, got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Spec.Decision != k8stypes.AppPromotionDecisionApproved || got.Spec.DecidedBy != "bob" {
+		t.Errorf("decision = %q by %q, want Approved by bob", got.Spec.Decision, got.Spec.DecidedBy)
+	}
+
+	for _, path := range []string{
+		"/api/apps/web-prod/promotions/web-prod-abc/reject",
+		"/api/apps/web-prod/promotions/web-prod-old/approve",
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Code != http.StatusConflict {
+			t.Errorf("%s status = %d, want 409 (body %s)", path, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/apps/web-prod/promotions", nil))
+	var list []PromotionResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("promotions = %d, want 2", len(list))
+	}
+}
+
+func TestAppsPromoteOutOfScopeSource(t *testing.T) {
+	staging, prod := promotionApps()
+	staging.Namespace = "staging"
+	prod.Spec.Promotion.From.Namespace = "staging"
+	srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), staging, prod)
+	r := asCaller(RoleDeveloper, "default/web-prod")(srv)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/apps/web-prod/promote", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403 (body %s)", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "ghcr.io/acme/web") {
+		t.Errorf("403 leaks the source's images: %s", rec.Body.String())
+	}
+}
+
+func TestAppsPromotionSelfApproval(t *testing.T) {
+	staging, prod := promotionApps()
+	prod.Spec.Promotion.RequireApproval = true
+	pending := &k8stypes.AppPromotion{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-prod-abc", Namespace: "default"},
+		Spec:       k8stypes.AppPromotionSpec{App: "web-prod", Image: "ghcr.io/acme/web:v3", RequestedBy: "alice"},
+		Status:     k8stypes.AppPromotionStatus{Phase: k8stypes.AppPromotionPhasePendingApproval},
+	}
+	srv, _ := newAppsTestServer(t, k8sfake.NewClientset(), staging, prod, pending)
+
+	as := func(actor, path string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyEmail, actor))
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := as("alice", "/api/apps/web-prod/promotions/web-prod-abc/approve"); code != http.StatusForbidden {
+		t.Errorf("self-approval status = %d, want 403", code)
+	}
+	if code := as("bob", "/api/apps/web-prod/promotions/web-prod-abc/approve"); code != http.StatusOK {
+		t.Errorf("approval by another member status = %d, want 200", code)
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// refreshTokenTTL is how long a session survives without being refreshed.
+const refreshTokenTTL = 7 * 24 * time.Hour
+
+// sessionSweepInterval is how often a login also deletes the sessions that
+// expired without their refresh token coming back.
+const sessionSweepInterval = time.Hour
+
+// lastSessionSweep is when this replica last swept expired sessions.
+var lastSessionSweep struct {
+	sync.Mutex
+	at time.Time
+}
+
+// Sessions are stored as Secrets next to the users. Each holds the hash of
+// the session's current refresh token; refreshing swaps in a new one, and
+// presenting a token that was already swapped out ends the session, since
+// it means the token leaked.
+const (
+	sessionLabel        = "platform.flowcd.io/session"
+	sessionUserLabel    = "platform.flowcd.io/session-user"
+	sessionSecretType   = corev1.SecretType("platform.flowcd.io/session")
+	sessionSecretPrefix = "flowcd-session-"
+
+	sessionEmailKey   = "email"
+	sessionNameKey    = "name"
+	sessionRoleKey    = "role"
+	sessionScopesKey  = "scopes"
+	sessionLocalKey   = "local"
+	sessionRefreshKey = "refresh-hash"
+	sessionExpiresKey = "expires-at"
+)
+
+var errSessionInvalid = errors.New("refresh token is invalid or expired")
+
+// session is a signed-in user. Local sessions belong to a stored user and
+// pick up role changes on refresh; SSO sessions keep the role they signed in
+// with.
+type session struct {
+	ID     string
+	Email  string
+	Name   string
+	Role   Role
+	Scopes []string
+	Local  bool
+}
+
+type sessionStore struct {
+	clientset kubernetes.Interface
+	namespace string
+}
+
+func newSessionStore(cs kubernetes.Interface) *sessionStore {
+	return &sessionStore{clientset: cs, namespace: newUserStore(cs).namespace}
+}
+
+// create starts a session and returns its first refresh token.
+func (s *sessionStore) create(ctx context.Context, sess session) (*session, string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("generate session id: %w", err)
+	}
+	id := hex.EncodeToString(b)
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	local := "false"
+	if sess.Local {
+		local = "true"
+	}
+	sess.ID = id
+	obj := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sessionSecretPrefix + id,
+			Namespace: s.namespace,
+			Labels:    map[string]string{sessionLabel: "true", sessionUserLabel: userID(sess.Email)},
+		},
+		Type: sessionSecretType,
+		Data: map[string][]byte{
+			sessionEmailKey:   []byte(sess.Email),
+			sessionNameKey:    []byte(sess.Name),
+			sessionRoleKey:    []byte(sess.Role),
+			sessionScopesKey:  []byte(strings.Join(sess.Scopes, ",")),
+			sessionLocalKey:   []byte(local),
+			sessionRefreshKey: tokenHash(secret),
+			sessionExpiresKey: []byte(time.Now().Add(refreshTokenTTL).UTC().Format(time.RFC3339)),
+		},
+	}
+	if _, err := s.clientset.CoreV1().Secrets(s.namespace).Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		return nil, "", err
+	}
+	return &sess, id + "." + secret, nil
+}
+
+// rotate exchanges a refresh token for the session and its next refresh
+// token. update, when non-nil, may change the session's identity or refuse
+// to continue it.
+func (s *sessionStore) rotate(ctx context.Context, refreshToken string, update func(*session) error) (*session, string, error) {
+	id, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || id == "" || secret == "" {
+		return nil, "", errSessionInvalid
+	}
+	secrets := s.clientset.CoreV1().Secrets(s.namespace)
+	obj, err := secrets.Get(ctx, sessionSecretPrefix+id, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || err == nil && obj.Type != sessionSecretType {
+		return nil, "", errSessionInvalid
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if subtle.ConstantTimeCompare(obj.Data[sessionRefreshKey], tokenHash(secret)) != 1 {
+		// A rotated-out token came back: whoever holds it, end the session.
+		_ = s.delete(ctx, id)
+		return nil, "", errSessionInvalid
+	}
+	expires, err := time.Parse(time.RFC3339, string(obj.Data[sessionExpiresKey]))
+	if err != nil || time.Now().After(expires) {
+		_ = s.delete(ctx, id)
+		return nil, "", errSessionInvalid
+	}
+
+	sess := sessionFromSecret(obj)
+	if update != nil {
+		if err := update(sess); err != nil {
+			_ = s.delete(ctx, id)
+			return nil, "", err
+		}
+	}
+	next, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	obj.Data[sessionNameKey] = []byte(sess.Name)
+	obj.Data[sessionRoleKey] = []byte(sess.Role)
+	obj.Data[sessionScopesKey] = []byte(strings.Join(sess.Scopes, ","))
+	obj.Data[sessionRefreshKey] = tokenHash(next)
+	obj.Data[sessionExpiresKey] = []byte(time.Now().Add(refreshTokenTTL).UTC().Format(time.RFC3339))
+	// The update is conditional on the version read above, so of two
+	// concurrent refreshes with the same token only one succeeds.
+	if _, err := secrets.Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsConflict(err) {
+			return nil, "", errSessionInvalid
+		}
+		return nil, "", err
+	}
+	return sess, id + "." + next, nil
+}
+
+// sweepExpired deletes expired sessions, at most once per
+// sessionSweepInterval. Failures are logged; the next sweep tries again.
+func (s *sessionStore) sweepExpired(ctx context.Context) {
+	lastSessionSweep.Lock()
+	due := time.Since(lastSessionSweep.at) >= sessionSweepInterval
+	if due {
+		lastSessionSweep.at = time.Now()
+	}
+	lastSessionSweep.Unlock()
+	if !due {
+		return
+	}
+
+	secrets, err := s.clientset.CoreV1().Secrets(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: sessionLabel + "=true"})
+	if err != nil {
+		log.Printf("session sweep: %v", err)
+		return
+	}
+	now := time.Now()
+	for i := range secrets.Items {
+		obj := &secrets.Items[i]
+		if obj.Type != sessionSecretType {
+			continue
+		}
+		// An unreadable expiry cannot be refreshed either.
+		if expires, err := time.Parse(time.RFC3339, string(obj.Data[sessionExpiresKey])); err == nil && now.Before(expires) {
+			continue
+		}
+		if err := s.delete(ctx, strings.TrimPrefix(obj.Name, sessionSecretPrefix)); err != nil {
+			log.Printf("session sweep: %v", err)
+		}
+	}
+}
+
+func (s *sessionStore) delete(ctx context.Context, id string) error {
+	err := s.clientset.CoreV1().Secrets(s.namespace).Delete(ctx, sessionSecretPrefix+id, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// deleteForUser ends every session of the user with email.
+func (s *sessionStore) deleteForUser(ctx context.Context, email string) error {
+	return s.clientset.CoreV1().Secrets(s.namespace).DeleteCollection(ctx, metav1.DeleteOptions{},
+		metav1.ListOptions{LabelSelector: sessionLabel + "=true," + sessionUserLabel + "=" + userID(email)})
+}
+
+func sessionFromSecret(obj *corev1.Secret) *session {
+	role, ok := parseRole(string(obj.Data[sessionRoleKey]))
+	if !ok {
+		role = RoleViewer
+	}
+	var scopes []string
+	if s := string(obj.Data[sessionScopesKey]); s != "" {
+		scopes = strings.Split(s, ",")
+	}
+	return &session{
+		ID:     strings.TrimPrefix(obj.Name, sessionSecretPrefix),
+		Email:  string(obj.Data[sessionEmailKey]),
+		Name:   string(obj.Data[sessionNameKey]),
+		Role:   role,
+		Scopes: scopes,
+		Local:  string(obj.Data[sessionLocalKey]) == "true",
+	}
+}
+
+// tokenHash hashes a refresh or API token for storage. Both are long random
+// strings, so a plain SHA-256 is enough.
+func tokenHash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return []byte(hex.EncodeToString(sum[:]))
+}
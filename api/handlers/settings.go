@@ -1,26 +1,212 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/mail"
+	"strings"
 	"time"
+
+	"github.com/go-chi/chi/v5"
+	"k8s.io/client-go/kubernetes"
 )
 
-type SettingsHandler struct{}
+type SettingsHandler struct {
+	users     *userStore
+	sessions  *sessionStore
+	apiTokens *apiTokenStore
+}
+
+func NewSettingsHandler(cs kubernetes.Interface) *SettingsHandler {
+	return &SettingsHandler{users: newUserStore(cs), sessions: newSessionStore(cs), apiTokens: newAPITokenStore(cs)}
+}
 
-func NewSettingsHandler() *SettingsHandler { return &SettingsHandler{} }
+func (h *SettingsHandler) Routes(r chi.Router) {
+	r.With(Authorize(PermTeamRead)).Get("/team", h.Team)
+	r.With(Authorize(PermTeamManage)).Post("/team", h.InviteMember)
+	r.With(Authorize(PermTeamManage)).Patch("/team/{id}", h.UpdateMember)
+	r.With(Authorize(PermTeamManage)).Delete("/team/{id}", h.RemoveMember)
+	r.With(Authorize(PermSettingsRead)).Get("/general", h.General)
+	r.With(Authorize(PermTokensOwn)).Get("/credentials", h.Credentials)
+	r.With(Authorize(PermTokensOwn)).Post("/credentials", h.CreateAPIToken)
+	r.With(Authorize(PermTokensOwn)).Delete("/credentials/{id}", h.RevokeAPIToken)
+	r.With(Authorize(PermSettingsRead)).Get("/integrations", h.Integrations)
+	r.With(Authorize(PermSettingsRead)).Get("/notifications", h.Notifications)
+}
 
-func (h *SettingsHandler) Team(w http.ResponseWriter, _ *http.Request) {
-	jsonOK(w, []TeamMemberResp{
-		{
-			ID:       "1",
-			Name:     "Admin",
-			Email:    "admin@flowcd.io",
-			Role:     "Admin",
-			JoinedAt: time.Now().UTC().Format(time.RFC3339),
-		},
-	})
+func (h *SettingsHandler) Team(w http.ResponseWriter, r *http.Request) {
+	users, err := h.users.list(r.Context())
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := make([]TeamMemberResp, 0, len(users))
+	for i := range users {
+		resp = append(resp, toTeamMemberResp(&users[i]))
+	}
+	jsonOK(w, resp)
+}
+
+// InviteMember adds a user. Without a password in the request a temporary
+// one is generated and returned once in the response. Scopes limit the user
+// to some namespaces or Apps.
+func (h *SettingsHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email    string   `json:"email"`
+		Name     string   `json:"name"`
+		Role     string   `json:"role"`
+		Scopes   []string `json:"scopes"`
+		Password string   `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	addr, err := mail.ParseAddress(body.Email)
+	if err != nil || addr.Address != strings.TrimSpace(body.Email) {
+		jsonError(w, "invalid email address", http.StatusBadRequest)
+		return
+	}
+	role, ok := parseRole(body.Role)
+	if !ok {
+		jsonError(w, fmt.Sprintf("invalid role %q: must be Admin, Developer or Viewer", body.Role), http.StatusBadRequest)
+		return
+	}
+	scopes, err := parseScopes(body.Scopes)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !scopesWithin(scopes, callerScopes(r)) {
+		jsonError(w, "member scopes must be within your own scopes", http.StatusForbidden)
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(addr.Address, "@")
+	}
+	password, temporary := body.Password, ""
+	if password == "" {
+		if password, err = randomToken(18); err != nil {
+			jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		temporary = password
+	} else if len(password) < minPasswordLength {
+		jsonError(w, fmt.Sprintf("password must be at least %d characters", minPasswordLength), http.StatusBadRequest)
+		return
+	}
+
+	u, err := h.users.create(r.Context(), addr.Address, name, role, scopes, password)
+	if errors.Is(err, errUserExists) {
+		jsonError(w, fmt.Sprintf("%s is already a member", addr.Address), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	jsonOK(w, InviteResp{TeamMemberResp: toTeamMemberResp(u), TemporaryPassword: temporary})
+}
+
+// UpdateMember changes a user's role and, when given, their scopes. The last
+// Admin cannot be demoted.
+func (h *SettingsHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Role   string    `json:"role"`
+		Scopes *[]string `json:"scopes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	role, ok := parseRole(body.Role)
+	if !ok {
+		jsonError(w, fmt.Sprintf("invalid role %q: must be Admin, Developer or Viewer", body.Role), http.StatusBadRequest)
+		return
+	}
+	var scopes []string
+	if body.Scopes != nil {
+		parsed, err := parseScopes(*body.Scopes)
+		if err != nil {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		scopes = parsed
+		// An empty list means every App, so scoped callers cannot clear it.
+		if !scopesWithin(scopes, callerScopes(r)) {
+			jsonError(w, "member scopes must be within your own scopes", http.StatusForbidden)
+			return
+		}
+	}
+	id := chi.URLParam(r, "id")
+	if _, ok := h.memberChange(w, r, id, role != RoleAdmin); !ok {
+		return
+	}
+	u, err := h.users.update(r.Context(), id, role, scopes)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jsonOK(w, toTeamMemberResp(u))
 }
 
+// RemoveMember deletes a user, ends their sessions and revokes their personal
+// API tokens. The last Admin cannot be removed.
+func (h *SettingsHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	u, ok := h.memberChange(w, r, id, true)
+	if !ok {
+		return
+	}
+	if err := h.users.delete(r.Context(), id); err != nil && !errors.Is(err, errUserNotFound) {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.sessions.deleteForUser(r.Context(), u.Email); err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.apiTokens.deleteForOwner(r.Context(), u.Email); err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// memberChange loads the user about to be changed. A scoped caller may only
+// change members whose scopes lie within their own. When dropsAdmin is set
+// and the user is the only Admin left it writes a conflict instead.
+func (h *SettingsHandler) memberChange(w http.ResponseWriter, r *http.Request, id string, dropsAdmin bool) (*user, bool) {
+	users, err := h.users.list(r.Context())
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	for i := range users {
+		u := &users[i]
+		if u.ID != id {
+			continue
+		}
+		if !scopesWithin(u.Scopes, callerScopes(r)) {
+			jsonError(w, fmt.Sprintf("%s has access outside your scopes", u.Email), http.StatusForbidden)
+			return nil, false
+		}
+		if dropsAdmin && u.Role == RoleAdmin && countAdmins(users) == 1 {
+			jsonError(w, "cannot remove the last Admin", http.StatusConflict)
+			return nil, false
+		}
+		return u, true
+	}
+	jsonError(w, "member not found", http.StatusNotFound)
+	return nil, false
+}
+
+const minPasswordLength = 8
+
 func (h *SettingsHandler) General(w http.ResponseWriter, _ *http.Request) {
 	jsonOK(w, GeneralSettingsResp{
 		PlatformName:        "FlowCD",
@@ -29,10 +215,148 @@ func (h *SettingsHandler) General(w http.ResponseWriter, _ *http.Request) {
 	})
 }
 
-func (h *SettingsHandler) Credentials(w http.ResponseWriter, _ *http.Request) {
-	jsonOK(w, []CredentialResp{})
+// Credentials lists the API tokens, without their secrets: every token to
+// callers who manage settings, and their own personal tokens to everyone else.
+func (h *SettingsHandler) Credentials(w http.ResponseWriter, r *http.Request) {
+	var tokens []apiToken
+	var err error
+	if callerRole(r).can(PermSettingsManage) {
+		tokens, err = h.apiTokens.list(r.Context())
+	} else {
+		email, _ := r.Context().Value(contextKeyEmail).(string)
+		tokens, err = h.apiTokens.listForOwner(r.Context(), email)
+	}
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := make([]CredentialResp, 0, len(tokens))
+	for i := range tokens {
+		resp = append(resp, toCredentialResp(&tokens[i]))
+	}
+	jsonOK(w, resp)
 }
 
+// CreateAPIToken issues an API token and returns it once. A personal token
+// (the default) acts for the caller, who must be a local member; a service
+// token acts for no one and may only be created by those who manage
+// settings. Either way the token gets at most the caller's role and scopes.
+// API tokens cannot create tokens, which would outlive their expiry and
+// revocation.
+func (h *SettingsHandler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	if viaAPIToken, _ := r.Context().Value(contextKeyAPIToken).(bool); viaAPIToken {
+		jsonError(w, "API tokens cannot create API tokens; sign in to create one", http.StatusForbidden)
+		return
+	}
+	var body struct {
+		Name      string   `json:"name"`
+		Role      string   `json:"role"`
+		Scopes    []string `json:"scopes"`
+		ExpiresAt string   `json:"expiresAt"`
+		Service   bool     `json:"service"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" || len(name) > maxAPITokenNameLength {
+		jsonError(w, fmt.Sprintf("name is required and may be at most %d characters", maxAPITokenNameLength), http.StatusBadRequest)
+		return
+	}
+	caller := callerRole(r)
+	if body.Service && !caller.can(PermSettingsManage) {
+		forbidden(w, ForbiddenResp{
+			Error:      "service tokens can only be created by those who manage settings",
+			Permission: string(PermSettingsManage),
+			Role:       string(caller),
+		})
+		return
+	}
+	role := caller
+	if body.Role != "" {
+		var ok bool
+		if role, ok = parseRole(body.Role); !ok {
+			jsonError(w, fmt.Sprintf("invalid role %q: must be Admin, Developer or Viewer", body.Role), http.StatusBadRequest)
+			return
+		}
+	}
+	if roleRank[role] > roleRank[caller] {
+		jsonError(w, fmt.Sprintf("cannot create a %s token as %s", role, caller), http.StatusForbidden)
+		return
+	}
+	scopes, err := parseScopes(body.Scopes)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !scopesWithin(scopes, callerScopes(r)) {
+		jsonError(w, "token scopes must be within your own scopes", http.StatusForbidden)
+		return
+	}
+	tok := apiToken{Name: name, Role: role, Scopes: scopes}
+	if body.ExpiresAt != "" {
+		if tok.ExpiresAt, err = time.Parse(time.RFC3339, body.ExpiresAt); err != nil || !tok.ExpiresAt.After(time.Now()) {
+			jsonError(w, "expiresAt must be an RFC 3339 time in the future", http.StatusBadRequest)
+			return
+		}
+	}
+	if !body.Service {
+		email, _ := r.Context().Value(contextKeyEmail).(string)
+		u, err := h.users.byEmail(r.Context(), email)
+		if errors.Is(err, errUserNotFound) {
+			jsonError(w, "personal tokens need a local member; create a service token instead", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tok.Owner = u.Email
+	}
+
+	created, raw, err := h.apiTokens.create(r.Context(), tok)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	jsonOK(w, APITokenResp{CredentialResp: toCredentialResp(created), Token: raw})
+}
+
+// RevokeAPIToken deletes an API token. Callers who do not manage settings
+// may only revoke their own personal tokens; others' look missing to them.
+func (h *SettingsHandler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !callerRole(r).can(PermSettingsManage) {
+		email, _ := r.Context().Value(contextKeyEmail).(string)
+		tok, err := h.apiTokens.get(r.Context(), id)
+		if err == nil && (tok.Owner == "" || normalizeEmail(tok.Owner) != normalizeEmail(email)) {
+			err = errAPITokenNotFound
+		}
+		if errors.Is(err, errAPITokenNotFound) {
+			jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	err := h.apiTokens.delete(r.Context(), id)
+	if errors.Is(err, errAPITokenNotFound) {
+		jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+const maxAPITokenNameLength = 100
+
 func (h *SettingsHandler) Integrations(w http.ResponseWriter, _ *http.Request) {
 	jsonOK(w, []IntegrationResp{
 		{ID: "1", Name: "GitHub", Type: "github", Connected: false},
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// newAuthTestServer serves the auth and settings routes behind ValidateJWT,
+// with users stored in cs.
+func newAuthTestServer(t *testing.T, cs kubernetes.Interface) http.Handler {
+	t.Helper()
+	t.Setenv("FLOWCD_NAMESPACE", "flowcd")
+	// Tests bootstrap with the built-in Admin password.
+	t.Setenv("FLOWCD_DEV_MODE", "true")
+	authH := NewAuthHandler(cs)
+	settingsH := NewSettingsHandler(cs)
+	r := chi.NewRouter()
+	r.Post("/api/auth/login", authH.Login)
+	r.Post("/api/auth/refresh", authH.Refresh)
+	r.Get("/api/auth/jwks", JWKS)
+	r.Group(func(protected chi.Router) {
+		protected.Use(ValidateJWT)
+		protected.Get("/api/auth/me", authH.Me)
+		protected.Post("/api/auth/logout", authH.Logout)
+		protected.Route("/api/settings", settingsH.Routes)
+	})
+	return r
+}
+
+// login signs in and returns the issued token, failing the test on any
+// status but 200.
+func login(t *testing.T, srv http.Handler, email, password string) loginResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	body := `{"email":"` + email + `","password":"` + password + `"}`
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login %s status = %d, want 200 (body %s)", email, rec.Code, rec.Body.String())
+	}
+	var resp loginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+func authed(method, path, token, body string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestLoginBootstrapsAdmin(t *testing.T) {
+	cs := k8sfake.NewClientset()
+	srv := newAuthTestServer(t, cs)
+
+	resp := login(t, srv, adminEmail, adminPassword)
+	if resp.Role != string(RoleAdmin) {
+		t.Errorf("role = %q, want Admin", resp.Role)
+	}
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(resp.Token, claims, func(*jwt.Token) (any, error) { return jwtSecret, nil }); err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if claims["role"] != "Admin" || claims["sub"] != adminEmail {
+		t.Errorf("claims = %v, want Admin %s", claims, adminEmail)
+	}
+
+	secret, err := cs.CoreV1().Secrets("flowcd").Get(context.Background(), userID(adminEmail), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("bootstrap user not stored: %v", err)
+	}
+	if string(secret.Data[userPasswordKey]) == adminPassword {
+		t.Error("password stored in plaintext")
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login",
+		strings.NewReader(`{"email":"`+adminEmail+`","password":"wrong"}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong password status = %d, want 401", rec.Code)
+	}
+}
+
+func TestLoginRefusesDefaultPasswordOutsideDevMode(t *testing.T) {
+	if adminPassword != defaultAdminPassword {
+		t.Skip("AUTH_PASSWORD is set")
+	}
+	cs := k8sfake.NewClientset()
+	srv := newAuthTestServer(t, cs)
+	t.Setenv("FLOWCD_DEV_MODE", "")
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login",
+		strings.NewReader(`{"email":"`+adminEmail+`","password":"`+adminPassword+`"}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("login with the built-in password status = %d, want 401", rec.Code)
+	}
+	if _, err := cs.CoreV1().Secrets("flowcd").Get(context.Background(), userID(adminEmail), metav1.GetOptions{}); err == nil {
+		t.Error("Admin created with the built-in password outside dev mode")
+	}
+}
+
+func TestScopedAdminCannotWidenAccess(t *testing.T) {
+	srv := newAuthTestServer(t, k8sfake.NewClientset())
+	admin := login(t, srv, adminEmail, adminPassword).Token
+
+	invite := func(token, body string) (*httptest.ResponseRecorder, InviteResp) {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/team", token, body))
+		var resp InviteResp
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+	rec, ops := invite(admin, `{"email":"ops@example.com","role":"Admin","scopes":["staging"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("invite status = %d (body %s)", rec.Code, rec.Body.String())
+	}
+	scoped := login(t, srv, "ops@example.com", ops.TemporaryPassword).Token
+
+	for _, body := range []string{
+		`{"email":"a@example.com","role":"Admin"}`,
+		`{"email":"b@example.com","role":"Viewer","scopes":["production"]}`,
+	} {
+		if rec, _ := invite(scoped, body); rec.Code != http.StatusForbidden {
+			t.Errorf("scoped invite %s status = %d, want 403", body, rec.Code)
+		}
+	}
+	rec, dev := invite(scoped, `{"email":"dev@example.com","role":"Developer","scopes":["staging/web"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("in-scope invite status = %d (body %s)", rec.Code, rec.Body.String())
+	}
+
+	tests := []struct {
+		name, id, body string
+		wantCode       int
+	}{
+		{name: "clear own scopes", id: ops.ID, body: `{"role":"Admin","scopes":[]}`, wantCode: http.StatusForbidden},
+		{name: "widen own scopes", id: ops.ID, body: `{"role":"Admin","scopes":["production"]}`, wantCode: http.StatusForbidden},
+		{name: "change unscoped Admin", id: userID(adminEmail), body: `{"role":"Viewer"}`, wantCode: http.StatusForbidden},
+		{name: "change in-scope member", id: dev.ID, body: `{"role":"Viewer"}`, wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, authed(http.MethodPatch, "/api/settings/team/"+tt.id, scoped, tt.body))
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s: status = %d, want %d (body %s)", tt.name, rec.Code, tt.wantCode, rec.Body.String())
+		}
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodDelete, "/api/settings/team/"+userID(adminEmail), scoped, ""))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("removing an unscoped Admin status = %d, want 403", rec.Code)
+	}
+}
+
+func TestTeamManagement(t *testing.T) {
+	srv := newAuthTestServer(t, k8sfake.NewClientset())
+	admin := login(t, srv, adminEmail, adminPassword).Token
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/team", admin,
+		`{"email":"dev@example.com","name":"Dev","role":"Developer"}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("invite status = %d, want 201 (body %s)", rec.Code, rec.Body.String())
+	}
+	var invited InviteResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &invited); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if invited.Role != "Developer" || invited.TemporaryPassword == "" {
+		t.Fatalf("invite = %+v, want Developer with a temporary password", invited)
+	}
+
+	dev := login(t, srv, "dev@example.com", invited.TemporaryPassword)
+	if dev.Role != "Developer" {
+		t.Errorf("developer login role = %q", dev.Role)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/team", dev.Token,
+		`{"email":"other@example.com","role":"Admin"}`))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("developer invite status = %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/team", admin,
+		`{"email":"dev@example.com","role":"Viewer"}`))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("duplicate invite status = %d, want 409", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPatch, "/api/settings/team/"+invited.ID, admin, `{"role":"Viewer"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("change role status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+	}
+	if viewer := login(t, srv, "dev@example.com", invited.TemporaryPassword); viewer.Role != "Viewer" {
+		t.Errorf("role after change = %q, want Viewer", viewer.Role)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodDelete, "/api/settings/team/"+userID(adminEmail), admin, ""))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("remove last admin status = %d, want 409", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPatch, "/api/settings/team/"+userID(adminEmail), admin, `{"role":"Viewer"}`))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("demote last admin status = %d, want 409", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodDelete, "/api/settings/team/"+invited.ID, admin, ""))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("remove status = %d, want 204 (body %s)", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/settings/team", admin, ""))
+	var team []TeamMemberResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &team); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(team) != 1 || team[0].Email != adminEmail {
+		t.Errorf("team = %+v, want only the admin", team)
+	}
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// defaultJWTSecret is only acceptable in dev mode; see ConfigureAuth.
+const defaultJWTSecret = "flowcd-dev-secret-change-in-production"
+
+// accessTokenTTL bounds how long a stolen access token stays useful; clients
+// renew through /api/auth/refresh.
+const accessTokenTTL = 15 * time.Minute
+
+// tokenKey is a key session tokens are signed or verified with.
+type tokenKey struct {
+	kid    string
+	method jwt.SigningMethod
+	// private is nil for keys that are only still trusted for verification.
+	private any
+	public  any
+}
+
+// tokenKeys signs session tokens with the active key and verifies them with
+// any key still trusted, so signing keys can be rotated without logging
+// everyone out.
+type tokenKeys struct {
+	active *tokenKey
+	byKID  map[string]*tokenKey
+}
+
+// sessionKeys starts out as HS256 with JWT_SECRET; ConfigureAuth replaces it
+// with asymmetric keys when they are configured.
+var sessionKeys = hmacKeys(jwtSecret)
+
+func hmacKeys(secret []byte) *tokenKeys {
+	k := &tokenKey{method: jwt.SigningMethodHS256, private: secret, public: secret}
+	return &tokenKeys{active: k, byKID: map[string]*tokenKey{}}
+}
+
+func (k *tokenKeys) sign(claims jwt.Claims) (string, error) {
+	tok := jwt.NewWithClaims(k.active.method, claims)
+	if k.active.kid != "" {
+		tok.Header["kid"] = k.active.kid
+	}
+	return tok.SignedString(k.active.private)
+}
+
+// keyFunc picks the verification key by the token's kid, refusing tokens
+// whose algorithm does not match that key.
+func (k *tokenKeys) keyFunc(t *jwt.Token) (any, error) {
+	key := k.active
+	if kid, _ := t.Header["kid"].(string); kid != "" {
+		key = k.byKID[kid]
+	}
+	if key == nil || t.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	return key.public, nil
+}
+
+// jwks returns the public keys, for services that verify FlowCD tokens.
+// It is empty with an HS256 secret.
+func (k *tokenKeys) jwks() jose.JSONWebKeySet {
+	set := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{}}
+	for _, key := range k.byKID {
+		set.Keys = append(set.Keys, jose.JSONWebKey{Key: key.public, KeyID: key.kid, Algorithm: key.method.Alg(), Use: "sig"})
+	}
+	return set
+}
+
+// JWKS serves the token verification keys.
+func JWKS(w http.ResponseWriter, _ *http.Request) {
+	jsonOK(w, sessionKeys.jwks())
+}
+
+// loadTokenKeys reads the active signing key from activeFile and keys still
+// trusted for verification from verifyFiles. Both accept PEM RSA or P-256
+// keys; verify-only keys may be public keys.
+func loadTokenKeys(activeFile string, verifyFiles []string) (*tokenKeys, error) {
+	keys := &tokenKeys{byKID: map[string]*tokenKey{}}
+	active, err := readTokenKey(activeFile)
+	if err != nil {
+		return nil, err
+	}
+	if active.private == nil {
+		return nil, fmt.Errorf("%s: signing key must be a private key", activeFile)
+	}
+	keys.active = active
+	keys.byKID[active.kid] = active
+	for _, f := range verifyFiles {
+		key, err := readTokenKey(f)
+		if err != nil {
+			return nil, err
+		}
+		key.private = nil
+		keys.byKID[key.kid] = key
+	}
+	return keys, nil
+}
+
+func readTokenKey(file string) (*tokenKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", file)
+	}
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", file, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	key := &tokenKey{}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.private = signer
+		key.public = signer.Public()
+	} else {
+		key.public = parsed
+	}
+	switch pub := key.public.(type) {
+	case *rsa.PublicKey:
+		key.method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%s: only P-256 EC keys are supported", file)
+		}
+		key.method = jwt.SigningMethodES256
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T", file, key.public)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.public)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	sum := sha256.Sum256(der)
+	key.kid = base64.RawURLEncoding.EncodeToString(sum[:12])
+	return key, nil
+}
+
+// ConfigureAuth prepares session tokens from the environment:
+//
+//   - JWT_SIGNING_KEY_FILE switches signing to that RSA or P-256 key, and
+//     JWT_VERIFY_KEY_FILES (comma-separated) keeps earlier keys trusted
+//     while their tokens expire;
+//   - revoked access tokens are tracked in a ConfigMap so every replica
+//     refuses them;
+//   - API tokens are accepted alongside session tokens.
+//
+// Outside dev mode (FLOWCD_DEV_MODE=true) it refuses to run with neither a
+// signing key nor a JWT_SECRET of its own.
+func ConfigureAuth(cs kubernetes.Interface) error {
+	if file := os.Getenv("JWT_SIGNING_KEY_FILE"); file != "" {
+		var verify []string
+		for _, f := range strings.Split(os.Getenv("JWT_VERIFY_KEY_FILES"), ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				verify = append(verify, f)
+			}
+		}
+		keys, err := loadTokenKeys(file, verify)
+		if err != nil {
+			return err
+		}
+		sessionKeys = keys
+	} else if string(jwtSecret) == defaultJWTSecret {
+		if !devMode() {
+			return errors.New("JWT_SECRET is unset and no JWT_SIGNING_KEY_FILE is configured; " +
+				"refusing to sign tokens with the built-in development secret (set FLOWCD_DEV_MODE=true for local development)")
+		}
+		log.Printf("WARNING: signing tokens with the built-in development secret")
+	}
+	revocations = newRevocationList(cs)
+	apiTokens = newAPITokenStore(cs)
+	return nil
+}
+
+func devMode() bool {
+	dev, _ := strconv.ParseBool(os.Getenv("FLOWCD_DEV_MODE"))
+	return dev
+}
+
+// ─── Revocation ───────────────────────────────────────────────────────────────
+
+// revokedTokensConfigMap maps the IDs of revoked access tokens to the Unix
+// time they expire anyway, after which they are pruned.
+const (
+	revokedTokensConfigMap = "flowcd-revoked-tokens"
+	revocationSyncInterval = 10 * time.Second
+)
+
+// revocations is nil until ConfigureAuth runs, which leaves revocation
+// checks off.
+var revocations *revocationList
+
+// revocationList is a cached view of the revoked-token ConfigMap. Tokens this
+// replica revokes are refused at once; others within revocationSyncInterval.
+type revocationList struct {
+	clientset kubernetes.Interface
+	namespace string
+
+	// mu guards the cache only; the ConfigMap is read without it so that
+	// requests never wait on the API server.
+	mu       sync.Mutex
+	revoked  map[string]time.Time
+	syncedAt time.Time
+	syncing  bool
+}
+
+func newRevocationList(cs kubernetes.Interface) *revocationList {
+	return &revocationList{clientset: cs, namespace: newUserStore(cs).namespace, revoked: map[string]time.Time{}}
+}
+
+// revoke records jti as revoked until exp.
+func (l *revocationList) revoke(ctx context.Context, jti string, exp time.Time) error {
+	cms := l.clientset.CoreV1().ConfigMaps(l.namespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := cms.Get(ctx, revokedTokensConfigMap, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: revokedTokensConfigMap, Namespace: l.namespace}}
+			cm.Data = map[string]string{jti: strconv.FormatInt(exp.Unix(), 10)}
+			_, err = cms.Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(corev1.Resource("configmaps"), revokedTokensConfigMap, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		for id, v := range cm.Data {
+			if until, err := strconv.ParseInt(v, 10, 64); err != nil || time.Unix(until, 0).Before(time.Now()) {
+				delete(cm.Data, id)
+			}
+		}
+		cm.Data[jti] = strconv.FormatInt(exp.Unix(), 10)
+		_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.revoked[jti] = exp
+	l.mu.Unlock()
+	return nil
+}
+
+// isRevoked reports whether jti was revoked. A stale cache is refreshed by the
+// one request that notices; the others answer from the cache meanwhile.
+func (l *revocationList) isRevoked(ctx context.Context, jti string) bool {
+	l.mu.Lock()
+	stale := !l.syncing && time.Since(l.syncedAt) > revocationSyncInterval
+	if stale {
+		l.syncing = true
+	}
+	l.mu.Unlock()
+	if stale {
+		l.sync(ctx)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.revoked[jti]
+	return ok
+}
+
+// sync merges the ConfigMap into the cache and drops expired entries.
+// Revocations are never undone, so entries this replica added since the read
+// are kept. When the ConfigMap cannot be read the cache is left as it is
+// until the next interval, so an outage costs one read per interval.
+func (l *revocationList) sync(ctx context.Context) {
+	cm, err := l.clientset.CoreV1().ConfigMaps(l.namespace).Get(ctx, revokedTokensConfigMap, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Printf("revocation list: %v", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.syncing, l.syncedAt = false, now
+	if err != nil && !apierrors.IsNotFound(err) {
+		return
+	}
+	revoked := map[string]time.Time{}
+	for id, until := range l.revoked {
+		if until.After(now) {
+			revoked[id] = until
+		}
+	}
+	if cm != nil {
+		for id, v := range cm.Data {
+			if until, err := strconv.ParseInt(v, 10, 64); err == nil {
+				revoked[id] = time.Unix(until, 0)
+			}
+		}
+	}
+	l.revoked = revoked
+}
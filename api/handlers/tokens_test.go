@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// refresh exchanges a refresh token and returns the response.
+func refresh(srv http.Handler, refreshToken string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/auth/refresh", "", `{"refreshToken":"`+refreshToken+`"}`))
+	return rec
+}
+
+func TestRefreshRotates(t *testing.T) {
+	srv := newAuthTestServer(t, k8sfake.NewClientset())
+	first := login(t, srv, adminEmail, adminPassword)
+	if first.RefreshToken == "" || first.ExpiresIn != int(accessTokenTTL.Seconds()) {
+		t.Fatalf("login = %+v, want a refresh token and a %s access token", first, accessTokenTTL)
+	}
+
+	rec := refresh(srv, first.RefreshToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh status = %d, want 200 (body %s)", rec.Code, rec.Body.String())
+	}
+	var second loginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &second); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.Role != string(RoleAdmin) {
+		t.Errorf("refresh = %+v, want a new refresh token for an Admin", second)
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/auth/me", second.Token, ""))
+	if rec.Code != http.StatusOK {
+		t.Errorf("me with refreshed token status = %d, want 200", rec.Code)
+	}
+
+	// Replaying the rotated-out token ends the session, so the current
+	// refresh token stops working too.
+	if rec := refresh(srv, first.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("reused refresh status = %d, want 401", rec.Code)
+	}
+	if rec := refresh(srv, second.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after reuse status = %d, want 401", rec.Code)
+	}
+}
+
+func TestRefreshPicksUpMemberChanges(t *testing.T) {
+	srv := newAuthTestServer(t, k8sfake.NewClientset())
+	admin := login(t, srv, adminEmail, adminPassword)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/settings/team", admin.Token, `{"email":"dev@example.com","role":"Developer"}`))
+	var invited InviteResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &invited); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("invite = %d %v (body %s)", rec.Code, err, rec.Body.String())
+	}
+	dev := login(t, srv, "dev@example.com", invited.TemporaryPassword)
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPatch, "/api/settings/team/"+invited.ID, admin.Token, `{"role":"Viewer"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d (body %s)", rec.Code, rec.Body.String())
+	}
+	rec = refresh(srv, dev.RefreshToken)
+	var got loginResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &got)
+	if rec.Code != http.StatusOK || got.Role != string(RoleViewer) {
+		t.Fatalf("refresh = %d %+v, want a Viewer token", rec.Code, got)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodDelete, "/api/settings/team/"+invited.ID, admin.Token, ""))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("remove status = %d (body %s)", rec.Code, rec.Body.String())
+	}
+	if rec := refresh(srv, got.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after removal status = %d, want 401", rec.Code)
+	}
+}
+
+func TestLoginSweepsExpiredSessions(t *testing.T) {
+	cs := k8sfake.NewClientset()
+	srv := newAuthTestServer(t, cs)
+	sessions := newSessionStore(cs)
+	stale, _, err := sessions.create(t.Context(), session{Email: adminEmail, Role: RoleAdmin})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	secrets := cs.CoreV1().Secrets("flowcd")
+	obj, err := secrets.Get(t.Context(), sessionSecretPrefix+stale.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	obj.Data[sessionExpiresKey] = []byte(time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+	if _, err := secrets.Update(t.Context(), obj, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update session: %v", err)
+	}
+
+	lastSessionSweep.Lock()
+	lastSessionSweep.at = time.Time{}
+	lastSessionSweep.Unlock()
+	fresh := login(t, srv, adminEmail, adminPassword)
+
+	if _, err := secrets.Get(t.Context(), sessionSecretPrefix+stale.ID, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expired session still stored (err %v)", err)
+	}
+	if rec := refresh(srv, fresh.RefreshToken); rec.Code != http.StatusOK {
+		t.Errorf("refresh of the new session status = %d, want 200", rec.Code)
+	}
+}
+
+func TestLogoutRevokes(t *testing.T) {
+	cs := k8sfake.NewClientset()
+	srv := newAuthTestServer(t, cs)
+	saved := revocations
+	revocations = newRevocationList(cs)
+	t.Cleanup(func() { revocations = saved })
+	resp := login(t, srv, adminEmail, adminPassword)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodPost, "/api/auth/logout", resp.Token, ""))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("logout status = %d, want 204 (body %s)", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/auth/me", resp.Token, ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("me after logout status = %d, want 401", rec.Code)
+	}
+	if rec := refresh(srv, resp.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout status = %d, want 401", rec.Code)
+	}
+
+	// Another replica learns of the revocation from the ConfigMap.
+	other := newRevocationList(cs)
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(resp.Token, claims, sessionKeys.keyFunc); err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if jti, _ := claims["jti"].(string); !other.isRevoked(t.Context(), jti) {
+		t.Error("revocation not visible to another replica")
+	}
+}
+
+func TestRevocationCheckDoesNotWaitOnRefresh(t *testing.T) {
+	cs := k8sfake.NewClientset()
+	release := make(chan struct{})
+	cs.PrependReactor("get", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+		<-release
+		return false, nil, nil
+	})
+	l := newRevocationList(cs)
+	l.revoked["cached"] = time.Now().Add(time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.isRevoked(t.Context(), "other")
+	}()
+	// Wait for the refresh to be under way, then check from the cache.
+	for {
+		l.mu.Lock()
+		syncing := l.syncing
+		l.mu.Unlock()
+		if syncing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !l.isRevoked(t.Context(), "cached") {
+		t.Error("cached revocation not reported while refreshing")
+	}
+	close(release)
+	<-done
+	if !l.isRevoked(t.Context(), "cached") {
+		t.Error("refresh dropped a revocation that has not expired")
+	}
+}
+
+func TestRevocationRefreshBacksOffOnError(t *testing.T) {
+	cs := k8sfake.NewClientset()
+	reads := 0
+	cs.PrependReactor("get", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+		reads++
+		return true, nil, errors.New("api server unavailable")
+	})
+	l := newRevocationList(cs)
+	for range 3 {
+		l.isRevoked(t.Context(), "jti")
+	}
+	if reads != 1 {
+		t.Errorf("ConfigMap read %d times during an outage, want once per interval", reads)
+	}
+}
+
+func TestValidateJWTRejectsOtherTokens(t *testing.T) {
+	srv := newAuthTestServer(t, k8sfake.NewClientset())
+	state, err := sessionKeys.sign(oidcState{
+		State:            "s",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/auth/me", state, ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("me with login state token status = %d, want 401", rec.Code)
+	}
+}
+
+// writeKey writes key as PEM to a file under dir and returns its path.
+func writeKey(t *testing.T, dir, name string, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return path
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	savedKeys, savedRevocations := sessionKeys, revocations
+	t.Cleanup(func() { sessionKeys, revocations = savedKeys, savedRevocations })
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	oldFile := writeKey(t, dir, "old.pem", rsaKey)
+	newFile := writeKey(t, dir, "new.pem", ecKey)
+
+	t.Setenv("FLOWCD_DEV_MODE", "")
+	t.Setenv("JWT_SIGNING_KEY_FILE", oldFile)
+	if err := ConfigureAuth(k8sfake.NewClientset()); err != nil {
+		t.Fatalf("ConfigureAuth: %v", err)
+	}
+	srv := newAuthTestServer(t, k8sfake.NewClientset())
+	before := login(t, srv, adminEmail, adminPassword)
+
+	// Rotate: sign with the EC key, keep trusting the RSA key.
+	t.Setenv("JWT_SIGNING_KEY_FILE", newFile)
+	t.Setenv("JWT_VERIFY_KEY_FILES", oldFile)
+	if err := ConfigureAuth(k8sfake.NewClientset()); err != nil {
+		t.Fatalf("ConfigureAuth: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/auth/me", before.Token, ""))
+	if rec.Code != http.StatusOK {
+		t.Errorf("me with token from the previous key status = %d, want 200", rec.Code)
+	}
+	rec = refresh(srv, before.RefreshToken)
+	var after loginResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &after)
+	tok, _, err := jwt.NewParser().ParseUnverified(after.Token, jwt.MapClaims{})
+	if err != nil || tok.Method.Alg() != "ES256" {
+		t.Errorf("refreshed token = %v, %v; want ES256", tok, err)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/auth/jwks", nil))
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(rec.Body.Bytes(), &set); err != nil {
+		t.Fatalf("decode jwks: %v", err)
+	}
+	if len(set.Keys) != 2 {
+		t.Fatalf("jwks has %d keys, want 2", len(set.Keys))
+	}
+	for _, k := range set.Keys {
+		if !k.IsPublic() {
+			t.Errorf("jwks key %s is not public", k.KeyID)
+		}
+	}
+
+	// Once the old key is dropped its tokens are refused.
+	t.Setenv("JWT_VERIFY_KEY_FILES", "")
+	if err := ConfigureAuth(k8sfake.NewClientset()); err != nil {
+		t.Fatalf("ConfigureAuth: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, authed(http.MethodGet, "/api/auth/me", before.Token, ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("me with token from a dropped key status = %d, want 401", rec.Code)
+	}
+}
+
+func TestConfigureAuthRefusesDefaultSecret(t *testing.T) {
+	saved := revocations
+	t.Cleanup(func() { revocations = saved })
+	t.Setenv("JWT_SIGNING_KEY_FILE", "")
+	t.Setenv("FLOWCD_DEV_MODE", "")
+	if string(jwtSecret) != defaultJWTSecret {
+		t.Skip("JWT_SECRET is set")
+	}
+	if err := ConfigureAuth(k8sfake.NewClientset()); err == nil {
+		t.Error("ConfigureAuth succeeded with the development secret outside dev mode")
+	}
+	t.Setenv("FLOWCD_DEV_MODE", "true")
+	if err := ConfigureAuth(k8sfake.NewClientset()); err != nil {
+		t.Errorf("ConfigureAuth in dev mode: %v", err)
+	}
+}
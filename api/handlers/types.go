@@ -8,6 +8,8 @@ type DomainResp struct {
 	ID        string `json:"id"`
 	Domain    string `json:"domain"`
 	SslStatus string `json:"sslStatus"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	Message   string `json:"message,omitempty"`
 }
 
 type EnvVarResp struct {
@@ -31,6 +33,60 @@ type AppResp struct {
 	ArgoHealthStatus string       `json:"argoHealthStatus"`
 	Domains          []DomainResp `json:"domains"`
 	EnvVars          []EnvVarResp `json:"envVars"`
+	Rollout          *RolloutResp `json:"rollout,omitempty"`
+}
+
+// RolloutResp is the progress of a Canary or BlueGreen rollout.
+type RolloutResp struct {
+	Strategy    string `json:"strategy"`
+	Phase       string `json:"phase"`
+	Step        int32  `json:"step"`
+	TotalSteps  int32  `json:"totalSteps"`
+	Weight      int32  `json:"weight"`
+	Paused      bool   `json:"paused"`
+	Promoting   bool   `json:"promoting"`
+	StableImage string `json:"stableImage"`
+	NewImage    string `json:"newImage"`
+	Message     string `json:"message"`
+}
+
+type DeploymentResp struct {
+	ID            string  `json:"id"`
+	AppID         string  `json:"appId"`
+	Version       string  `json:"version"`
+	CommitSha     string  `json:"commitSha"`
+	CommitMessage string  `json:"commitMessage"`
+	Status        string  `json:"status"`
+	TriggeredBy   string  `json:"triggeredBy"`
+	DeployedAt    string  `json:"deployedAt"`
+	Duration      float64 `json:"duration"`
+	ImageTag      string  `json:"imageTag"`
+}
+
+type PromotionResp struct {
+	ID          string `json:"id"`
+	AppID       string `json:"appId"`
+	Source      string `json:"source,omitempty"`
+	Revision    int64  `json:"revision,omitempty"`
+	Image       string `json:"image"`
+	CommitSha   string `json:"commitSha,omitempty"`
+	Status      string `json:"status"`
+	RequestedBy string `json:"requestedBy"`
+	DecidedBy   string `json:"decidedBy,omitempty"`
+	Message     string `json:"message,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+	PromotedAt  string `json:"promotedAt,omitempty"`
+}
+
+type BuildResp struct {
+	ID            string   `json:"id"`
+	AppID         string   `json:"appId"`
+	CommitSha     string   `json:"commitSha"`
+	CommitMessage string   `json:"commitMessage"`
+	Status        string   `json:"status"`
+	StartedAt     string   `json:"startedAt"`
+	Duration      float64  `json:"duration"`
+	Logs          []string `json:"logs"`
 }
 
 // ─── Pipeline ─────────────────────────────────────────────────────────────────
@@ -42,11 +98,14 @@ type PipelineStageResp struct {
 }
 
 type PipelineRunResp struct {
-	ID          string `json:"id"`
-	Status      string `json:"status"`
-	StartedAt   string `json:"startedAt"`
+	ID          string  `json:"id"`
+	Status      string  `json:"status"`
+	StartedAt   string  `json:"startedAt"`
 	Duration    float64 `json:"duration"`
-	TriggeredBy string `json:"triggeredBy"`
+	TriggeredBy string  `json:"triggeredBy"`
+	Trigger     string  `json:"trigger,omitempty"`
+	CommitSha   string  `json:"commitSha,omitempty"`
+	Image       string  `json:"image,omitempty"`
 }
 
 type PipelineResp struct {
@@ -60,6 +119,13 @@ type PipelineResp struct {
 	Runs          []PipelineRunResp   `json:"runs"`
 }
 
+// ─── Webhooks ───────────────────────────────────────────────────────────────
+
+type HookResp struct {
+	Status string   `json:"status"`
+	Runs   []string `json:"runs"`
+}
+
 // ─── Cluster (stub) ─────────────────────────────────────────────────────────
 
 type ClusterNodeResp struct {
@@ -110,12 +176,29 @@ type ActivityPageResp struct {
 // ─── Settings ─────────────────────────────────────────────────────────────────
 
 type TeamMemberResp struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-	Role      string `json:"role"`
-	JoinedAt  string `json:"joinedAt"`
-	AvatarUrl string `json:"avatarUrl,omitempty"`
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Email     string   `json:"email"`
+	Role      string   `json:"role"`
+	Scopes    []string `json:"scopes,omitempty"`
+	JoinedAt  string   `json:"joinedAt"`
+	AvatarUrl string   `json:"avatarUrl,omitempty"`
+}
+
+// InviteResp is a new member. TemporaryPassword is set only when the API
+// generated the password, and is never returned again.
+type InviteResp struct {
+	TeamMemberResp
+	TemporaryPassword string `json:"temporaryPassword,omitempty"`
+}
+
+// ForbiddenResp is the body of a 403: the permission the caller lacks, their
+// role and, when the role allows the action elsewhere, the App out of scope.
+type ForbiddenResp struct {
+	Error      string `json:"error"`
+	Permission string `json:"permission"`
+	Role       string `json:"role"`
+	Scope      string `json:"scope,omitempty"`
 }
 
 type GeneralSettingsResp struct {
@@ -124,12 +207,26 @@ type GeneralSettingsResp struct {
 	DefaultBuildTimeout int    `json:"defaultBuildTimeout"`
 }
 
+// CredentialResp describes a credential without its secret; for API tokens
+// Value is only the token's recognisable prefix.
 type CredentialResp struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Type      string `json:"type"`
-	Value     string `json:"value"`
-	CreatedAt string `json:"createdAt"`
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Value      string   `json:"value"`
+	CreatedAt  string   `json:"createdAt"`
+	Role       string   `json:"role,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
+	Owner      string   `json:"owner,omitempty"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+	Expired    bool     `json:"expired,omitempty"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+}
+
+// APITokenResp is a new API token. Token is never returned again.
+type APITokenResp struct {
+	CredentialResp
+	Token string `json:"token"`
 }
 
 type IntegrationResp struct {
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Role is a user's access level; the values match the UI's TeamRoleSchema.
+type Role string
+
+const (
+	RoleAdmin     Role = "Admin"
+	RoleDeveloper Role = "Developer"
+	RoleViewer    Role = "Viewer"
+)
+
+func parseRole(s string) (Role, bool) {
+	switch r := Role(s); r {
+	case RoleAdmin, RoleDeveloper, RoleViewer:
+		return r, true
+	}
+	return "", false
+}
+
+// Users are stored as Secrets so their password hashes sit behind the
+// cluster's Secret RBAC. Each Secret is named after a hash of the user's
+// email, which keeps lookups by email a single GET.
+const (
+	userLabel        = "platform.flowcd.io/user"
+	userSecretType   = corev1.SecretType("platform.flowcd.io/user")
+	userSecretPrefix = "flowcd-user-"
+
+	userEmailKey    = "email"
+	userNameKey     = "name"
+	userRoleKey     = "role"
+	userScopesKey   = "scopes"
+	userPasswordKey = "password-hash"
+)
+
+var (
+	errUserNotFound = errors.New("user not found")
+	errUserExists   = errors.New("user already exists")
+)
+
+type user struct {
+	ID           string
+	Email        string
+	Name         string
+	Role         Role
+	Scopes       []string
+	PasswordHash []byte
+	JoinedAt     time.Time
+}
+
+// userStore keeps users in the namespace named by the FLOWCD_NAMESPACE env
+// var, "default" when unset.
+type userStore struct {
+	clientset kubernetes.Interface
+	namespace string
+}
+
+func newUserStore(cs kubernetes.Interface) *userStore {
+	ns := os.Getenv("FLOWCD_NAMESPACE")
+	if ns == "" {
+		ns = "default"
+	}
+	return &userStore{clientset: cs, namespace: ns}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// userID returns the name of the Secret holding the user with email.
+func userID(email string) string {
+	sum := sha256.Sum256([]byte(normalizeEmail(email)))
+	return userSecretPrefix + hex.EncodeToString(sum[:10])
+}
+
+func (s *userStore) get(ctx context.Context, id string) (*user, error) {
+	secret, err := s.clientset.CoreV1().Secrets(s.namespace).Get(ctx, id, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || err == nil && secret.Type != userSecretType {
+		return nil, errUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return userFromSecret(secret), nil
+}
+
+func (s *userStore) byEmail(ctx context.Context, email string) (*user, error) {
+	return s.get(ctx, userID(email))
+}
+
+// list returns every user, longest-standing first.
+func (s *userStore) list(ctx context.Context) ([]user, error) {
+	secrets, err := s.clientset.CoreV1().Secrets(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: userLabel + "=true"})
+	if err != nil {
+		return nil, err
+	}
+	users := make([]user, 0, len(secrets.Items))
+	for i := range secrets.Items {
+		if secrets.Items[i].Type == userSecretType {
+			users = append(users, *userFromSecret(&secrets.Items[i]))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].JoinedAt.Equal(users[j].JoinedAt) {
+			return users[i].JoinedAt.Before(users[j].JoinedAt)
+		}
+		return users[i].Email < users[j].Email
+	})
+	return users, nil
+}
+
+func (s *userStore) create(ctx context.Context, email, name string, role Role, scopes []string, password string) (*user, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+	email = normalizeEmail(email)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      userID(email),
+			Namespace: s.namespace,
+			Labels:    map[string]string{userLabel: "true"},
+		},
+		Type: userSecretType,
+		Data: map[string][]byte{
+			userEmailKey:    []byte(email),
+			userNameKey:     []byte(name),
+			userRoleKey:     []byte(role),
+			userScopesKey:   []byte(strings.Join(scopes, ",")),
+			userPasswordKey: hash,
+		},
+	}
+	created, err := s.clientset.CoreV1().Secrets(s.namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil, errUserExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return userFromSecret(created), nil
+}
+
+// update sets the user's role and, when scopes is non-nil, their scopes.
+func (s *userStore) update(ctx context.Context, id string, role Role, scopes []string) (*user, error) {
+	secret, err := s.clientset.CoreV1().Secrets(s.namespace).Get(ctx, id, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || err == nil && secret.Type != userSecretType {
+		return nil, errUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	secret.Data[userRoleKey] = []byte(role)
+	if scopes != nil {
+		secret.Data[userScopesKey] = []byte(strings.Join(scopes, ","))
+	}
+	updated, err := s.clientset.CoreV1().Secrets(s.namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return userFromSecret(updated), nil
+}
+
+func (s *userStore) delete(ctx context.Context, id string) error {
+	err := s.clientset.CoreV1().Secrets(s.namespace).Delete(ctx, id, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return errUserNotFound
+	}
+	return err
+}
+
+// authenticate returns the user whose email and password match. Unknown
+// emails are checked against a dummy hash so both failures take as long.
+func (s *userStore) authenticate(ctx context.Context, email, password string) (*user, error) {
+	u, err := s.byEmail(ctx, email)
+	if errors.Is(err, errUserNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, errUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(password)) != nil {
+		return nil, errUserNotFound
+	}
+	return u, nil
+}
+
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("flowcd-dummy-password"), bcrypt.DefaultCost)
+
+func userFromSecret(secret *corev1.Secret) *user {
+	role, ok := parseRole(string(secret.Data[userRoleKey]))
+	if !ok {
+		role = RoleViewer
+	}
+	var scopes []string
+	if s := string(secret.Data[userScopesKey]); s != "" {
+		scopes = strings.Split(s, ",")
+	}
+	return &user{
+		ID:           secret.Name,
+		Email:        string(secret.Data[userEmailKey]),
+		Name:         string(secret.Data[userNameKey]),
+		Role:         role,
+		Scopes:       scopes,
+		PasswordHash: secret.Data[userPasswordKey],
+		JoinedAt:     secret.CreationTimestamp.UTC(),
+	}
+}
+
+// countAdmins returns how many of users are Admins.
+func countAdmins(users []user) int {
+	n := 0
+	for _, u := range users {
+		if u.Role == RoleAdmin {
+			n++
+		}
+	}
+	return n
+}
+
+func toTeamMemberResp(u *user) TeamMemberResp {
+	return TeamMemberResp{
+		ID:       u.ID,
+		Name:     u.Name,
+		Email:    u.Email,
+		Role:     string(u.Role),
+		Scopes:   u.Scopes,
+		JoinedAt: u.JoinedAt.Format(time.RFC3339),
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -18,8 +19,8 @@ func NewClient() (client.Client, error) {
 		return nil, fmt.Errorf("load kubeconfig: %w", err)
 	}
 
-	scheme := runtime.NewScheme()
-	if err := addToScheme(scheme); err != nil {
+	scheme, err := NewScheme()
+	if err != nil {
 		return nil, fmt.Errorf("build scheme: %w", err)
 	}
 
@@ -30,6 +31,20 @@ func NewClient() (client.Client, error) {
 	return c, nil
 }
 
+// NewClientset builds a typed clientset for core APIs the controller-runtime
+// client cannot serve, such as pod log streams.
+func NewClientset() (kubernetes.Interface, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create k8s clientset: %w", err)
+	}
+	return cs, nil
+}
+
 func loadConfig() (*rest.Config, error) {
 	// Try in-cluster config first.
 	cfg, err := rest.InClusterConfig()
@@ -44,6 +59,15 @@ func loadConfig() (*rest.Config, error) {
 	return clientcmd.BuildConfigFromFlags("", kubeconfig)
 }
 
+// NewScheme returns a runtime.Scheme with the FlowCD CRDs registered.
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := addToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
 func addToScheme(scheme *runtime.Scheme) error {
 	// Register core k8s types needed for status conditions.
 	scheme.AddKnownTypeWithName(
@@ -62,6 +86,22 @@ func addToScheme(scheme *runtime.Scheme) error {
 		GroupVersion.WithKind("PipelineList"),
 		&PipelineList{},
 	)
+	scheme.AddKnownTypeWithName(
+		GroupVersion.WithKind("PipelineRun"),
+		&PipelineRun{},
+	)
+	scheme.AddKnownTypeWithName(
+		GroupVersion.WithKind("PipelineRunList"),
+		&PipelineRunList{},
+	)
+	scheme.AddKnownTypeWithName(
+		GroupVersion.WithKind("AppPromotion"),
+		&AppPromotion{},
+	)
+	scheme.AddKnownTypeWithName(
+		GroupVersion.WithKind("AppPromotionList"),
+		&AppPromotionList{},
+	)
 
 	// Register with the codec factory.
 	_ = serializer.NewCodecFactory(scheme)
@@ -1,6 +1,9 @@
 package k8s
 
 import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -21,22 +24,321 @@ const (
 	AppPhaseDegraded  AppPhase = "Degraded"
 	AppPhaseFailed    AppPhase = "Failed"
 	AppPhaseSuspended AppPhase = "Suspended"
+	AppPhaseCanary    AppPhase = "Canary"
+	AppPhasePreview   AppPhase = "Preview"
+	AppPhasePaused    AppPhase = "Paused"
+	AppPhasePromoting AppPhase = "Promoting"
+	AppPhaseAborted   AppPhase = "Aborted"
+	AppPhaseSleeping  AppPhase = "Sleeping"
 )
 
+type AppStrategyType string
+
+const (
+	AppStrategyRollingUpdate AppStrategyType = "RollingUpdate"
+	AppStrategyCanary        AppStrategyType = "Canary"
+	AppStrategyBlueGreen     AppStrategyType = "BlueGreen"
+)
+
+type CanaryStep struct {
+	Weight       int32  `json:"weight"`
+	PauseSeconds *int32 `json:"pauseSeconds,omitempty"`
+}
+
+type CanaryStrategy struct {
+	Steps []CanaryStep `json:"steps"`
+}
+
+type BlueGreenStrategy struct {
+	AutoPromotionSeconds *int32 `json:"autoPromotionSeconds,omitempty"`
+}
+
+type AppStrategy struct {
+	Type      AppStrategyType    `json:"type,omitempty"`
+	Canary    *CanaryStrategy    `json:"canary,omitempty"`
+	BlueGreen *BlueGreenStrategy `json:"blueGreen,omitempty"`
+}
+
+type SecretKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
 type AppEnvVar struct {
-	Name  string `json:"name"`
-	Value string `json:"value,omitempty"`
+	Name         string             `json:"name"`
+	Value        string             `json:"value,omitempty"`
+	SecretKeyRef *SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+type AppDestination struct {
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type AppReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type AppPromotionPolicy struct {
+	From            AppReference `json:"from"`
+	RequireApproval bool         `json:"requireApproval,omitempty"`
 }
 
 type AppSpec struct {
-	RepoUrl   string      `json:"repoUrl"`
-	Branch    string      `json:"branch,omitempty"`
-	Image     string      `json:"image,omitempty"`
-	Port      int32       `json:"port,omitempty"`
-	Replicas  *int32      `json:"replicas,omitempty"`
-	Env       []AppEnvVar `json:"env,omitempty"`
-	Domains   []string    `json:"domains,omitempty"`
-	Suspended bool        `json:"suspended,omitempty"`
+	RepoUrl              string              `json:"repoUrl"`
+	Branch               string              `json:"branch,omitempty"`
+	Image                string              `json:"image,omitempty"`
+	Port                 int32               `json:"port,omitempty"`
+	Replicas             *int32              `json:"replicas,omitempty"`
+	Env                  []AppEnvVar         `json:"env,omitempty"`
+	Domains              []string            `json:"domains,omitempty"`
+	Suspended            bool                `json:"suspended,omitempty"`
+	Destination          *AppDestination     `json:"destination,omitempty"`
+	Promotion            *AppPromotionPolicy `json:"promotion,omitempty"`
+	RevisionHistoryLimit *int32              `json:"revisionHistoryLimit,omitempty"`
+	Strategy             *AppStrategy        `json:"strategy,omitempty"`
+	Rollback             *AppRollbackPolicy  `json:"rollback,omitempty"`
+	HealthCheck          *AppHealthCheck     `json:"healthCheck,omitempty"`
+	Resources            *AppResources       `json:"resources,omitempty"`
+	Autoscaling          *AppAutoscaling     `json:"autoscaling,omitempty"`
+	Idle                 *AppIdlePolicy      `json:"idle,omitempty"`
+	TLS                  *AppTLS             `json:"tls,omitempty"`
+	Routing              *AppRouting         `json:"routing,omitempty"`
+	Sidecars             []AppContainer      `json:"sidecars,omitempty"`
+	InitContainers       []AppContainer      `json:"initContainers,omitempty"`
+	Volumes              []AppVolume         `json:"volumes,omitempty"`
+	Hooks                *AppHooks           `json:"hooks,omitempty"`
+}
+
+type AppContainer struct {
+	Name         string             `json:"name"`
+	Image        string             `json:"image"`
+	Command      []string           `json:"command,omitempty"`
+	Args         []string           `json:"args,omitempty"`
+	Env          []AppEnvVar        `json:"env,omitempty"`
+	Ports        []AppContainerPort `json:"ports,omitempty"`
+	VolumeMounts []AppVolumeMount   `json:"volumeMounts,omitempty"`
+	Resources    *AppResources      `json:"resources,omitempty"`
+}
+
+type AppContainerPort struct {
+	Name          string          `json:"name,omitempty"`
+	ContainerPort int32           `json:"containerPort"`
+	Protocol      corev1.Protocol `json:"protocol,omitempty"`
+}
+
+type AppVolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	SubPath   string `json:"subPath,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+type AppVolume struct {
+	Name                  string              `json:"name"`
+	MountPath             string              `json:"mountPath,omitempty"`
+	SubPath               string              `json:"subPath,omitempty"`
+	ReadOnly              bool                `json:"readOnly,omitempty"`
+	EmptyDir              *AppEmptyDirVolume  `json:"emptyDir,omitempty"`
+	PersistentVolumeClaim *AppPVCVolume       `json:"persistentVolumeClaim,omitempty"`
+	ConfigMap             *AppConfigMapVolume `json:"configMap,omitempty"`
+	Secret                *AppSecretVolume    `json:"secret,omitempty"`
+}
+
+type AppVolumeReclaimPolicy string
+
+const (
+	AppVolumeRetain AppVolumeReclaimPolicy = "Retain"
+	AppVolumeDelete AppVolumeReclaimPolicy = "Delete"
+)
+
+type AppPVCVolume struct {
+	Size             resource.Quantity                   `json:"size"`
+	StorageClassName *string                             `json:"storageClassName,omitempty"`
+	AccessModes      []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	ReclaimPolicy    AppVolumeReclaimPolicy              `json:"reclaimPolicy,omitempty"`
+}
+
+type AppKeyToPath struct {
+	Key  string `json:"key"`
+	Path string `json:"path"`
+}
+
+type AppConfigMapVolume struct {
+	Name     string         `json:"name"`
+	Items    []AppKeyToPath `json:"items,omitempty"`
+	Optional bool           `json:"optional,omitempty"`
+}
+
+type AppSecretVolume struct {
+	Name     string         `json:"name"`
+	Items    []AppKeyToPath `json:"items,omitempty"`
+	Optional bool           `json:"optional,omitempty"`
+}
+
+type AppEmptyDirVolume struct {
+	Medium    corev1.StorageMedium `json:"medium,omitempty"`
+	SizeLimit *resource.Quantity   `json:"sizeLimit,omitempty"`
+}
+
+type AppHooks struct {
+	PreDeploy  []AppHook `json:"preDeploy,omitempty"`
+	PostDeploy []AppHook `json:"postDeploy,omitempty"`
+}
+
+type AppHook struct {
+	Name           string      `json:"name"`
+	Image          string      `json:"image,omitempty"`
+	Command        []string    `json:"command,omitempty"`
+	Args           []string    `json:"args,omitempty"`
+	Env            []AppEnvVar `json:"env,omitempty"`
+	BackoffLimit   *int32      `json:"backoffLimit,omitempty"`
+	TimeoutSeconds *int64      `json:"timeoutSeconds,omitempty"`
+}
+
+type AppRoutingBackend string
+
+const (
+	AppRoutingIngress AppRoutingBackend = "Ingress"
+	AppRoutingGateway AppRoutingBackend = "Gateway"
+)
+
+type AppRouting struct {
+	Backend          AppRoutingBackend `json:"backend,omitempty"`
+	IngressClassName string            `json:"ingressClassName,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+	Gateway          *AppGatewayRef    `json:"gateway,omitempty"`
+	Paths            []AppRoutePath    `json:"paths,omitempty"`
+	Redirects        []AppRedirect     `json:"redirects,omitempty"`
+	ForceHTTPS       bool              `json:"forceHTTPS,omitempty"`
+}
+
+type AppGatewayRef struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	SectionName     string `json:"sectionName,omitempty"`
+	HTTPSectionName string `json:"httpSectionName,omitempty"`
+}
+
+type AppRoutePath struct {
+	Path    string   `json:"path"`
+	Domains []string `json:"domains,omitempty"`
+}
+
+type AppRedirect struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type AppIssuerKind string
+
+const (
+	AppIssuerKindIssuer        AppIssuerKind = "Issuer"
+	AppIssuerKindClusterIssuer AppIssuerKind = "ClusterIssuer"
+)
+
+type AppTLS struct {
+	Issuer     *AppIssuerRef `json:"issuer,omitempty"`
+	SecretName string        `json:"secretName,omitempty"`
+}
+
+type AppIssuerRef struct {
+	Name string        `json:"name"`
+	Kind AppIssuerKind `json:"kind,omitempty"`
+}
+
+type AppSSLStatus string
+
+const (
+	AppSSLStatusNone    AppSSLStatus = "None"
+	AppSSLStatusPending AppSSLStatus = "Pending"
+	AppSSLStatusValid   AppSSLStatus = "Valid"
+	AppSSLStatusFailed  AppSSLStatus = "Failed"
+)
+
+type AppDomainStatus struct {
+	Domain    string       `json:"domain"`
+	SSLStatus AppSSLStatus `json:"sslStatus"`
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	Message   string       `json:"message,omitempty"`
+}
+
+type AppIdlePolicy struct {
+	AfterMinutes int32 `json:"afterMinutes"`
+}
+
+type AppAutoscaling struct {
+	MinReplicas                       *int32                     `json:"minReplicas,omitempty"`
+	MaxReplicas                       int32                      `json:"maxReplicas"`
+	TargetCPUUtilizationPercentage    *int32                     `json:"targetCPUUtilizationPercentage,omitempty"`
+	TargetMemoryUtilizationPercentage *int32                     `json:"targetMemoryUtilizationPercentage,omitempty"`
+	Metrics                           []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+}
+
+type AppResources struct {
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+	Limits   corev1.ResourceList `json:"limits,omitempty"`
+}
+
+type AppHTTPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type AppProbeSettings struct {
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       *int32 `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      *int32 `json:"timeoutSeconds,omitempty"`
+	SuccessThreshold    *int32 `json:"successThreshold,omitempty"`
+	FailureThreshold    *int32 `json:"failureThreshold,omitempty"`
+}
+
+type AppHealthCheck struct {
+	Path      string            `json:"path,omitempty"`
+	Port      *int32            `json:"port,omitempty"`
+	Headers   []AppHTTPHeader   `json:"headers,omitempty"`
+	Readiness *AppProbeSettings `json:"readiness,omitempty"`
+	Liveness  *AppProbeSettings `json:"liveness,omitempty"`
+	Startup   *AppProbeSettings `json:"startup,omitempty"`
+}
+
+type AppRollbackPolicy struct {
+	Auto               bool   `json:"auto,omitempty"`
+	CrashLoopThreshold *int32 `json:"crashLoopThreshold,omitempty"`
+}
+
+type AppRevisionStatus string
+
+const (
+	AppRevisionSucceeded  AppRevisionStatus = "Succeeded"
+	AppRevisionRolledBack AppRevisionStatus = "RolledBack"
+)
+
+type AppRevision struct {
+	Revision   int64             `json:"revision"`
+	Image      string            `json:"image"`
+	CommitSHA  string            `json:"commitSha,omitempty"`
+	Replicas   int32             `json:"replicas,omitempty"`
+	Env        []AppEnvVar       `json:"env,omitempty"`
+	EnvHash    string            `json:"envHash,omitempty"`
+	DeployedAt metav1.Time       `json:"deployedAt"`
+	DeployedBy string            `json:"deployedBy,omitempty"`
+	Duration   int64             `json:"duration,omitempty"`
+	Status     AppRevisionStatus `json:"status,omitempty"`
+}
+
+type AppRolloutStatus struct {
+	StableImage            string       `json:"stableImage,omitempty"`
+	NewImage               string       `json:"newImage,omitempty"`
+	CurrentStep            int32        `json:"currentStep,omitempty"`
+	Weight                 int32        `json:"weight,omitempty"`
+	StepStartedAt          *metav1.Time `json:"stepStartedAt,omitempty"`
+	Paused                 bool         `json:"paused,omitempty"`
+	Promoting              bool         `json:"promoting,omitempty"`
+	AbortedImage           string       `json:"abortedImage,omitempty"`
+	ObservedPromoteRequest string       `json:"observedPromoteRequest,omitempty"`
+	ObservedAbortRequest   string       `json:"observedAbortRequest,omitempty"`
+	Message                string       `json:"message,omitempty"`
 }
 
 type AppStatus struct {
@@ -46,6 +348,12 @@ type AppStatus struct {
 	AvailableReplicas int32              `json:"availableReplicas,omitempty"`
 	ReadyReplicas     int32              `json:"readyReplicas,omitempty"`
 	LastDeployedAt    *metav1.Time       `json:"lastDeployedAt,omitempty"`
+	DesiredReplicas   int32              `json:"desiredReplicas,omitempty"`
+	CurrentRevision   int64              `json:"currentRevision,omitempty"`
+	RolloutStartedAt  *metav1.Time       `json:"rolloutStartedAt,omitempty"`
+	History           []AppRevision      `json:"history,omitempty"`
+	Domains           []AppDomainStatus  `json:"domains,omitempty"`
+	Rollout           *AppRolloutStatus  `json:"rollout,omitempty"`
 	Conditions        []metav1.Condition `json:"conditions,omitempty"`
 }
 
@@ -66,7 +374,10 @@ func (a *App) DeepCopy() *App {
 	a.DeepCopyInto(out)
 	return out
 }
-func (a *App) DeepCopyInto(out *App) { *out = *a }
+func (a *App) DeepCopyInto(out *App) {
+	*out = *a
+	a.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+}
 
 // +kubebuilder:object:root=true
 type AppList struct {
@@ -102,27 +413,35 @@ const (
 )
 
 type PipelineStageStatus struct {
-	Name      string        `json:"name"`
-	Phase     PipelinePhase `json:"phase"`
-	StartedAt *metav1.Time  `json:"startedAt,omitempty"`
-	Duration  string        `json:"duration,omitempty"`
+	Name     string        `json:"name"`
+	Phase    PipelinePhase `json:"phase"`
+	Duration int64         `json:"duration,omitempty"`
 }
 
 type PipelineSpec struct {
-	AppRef        string `json:"appRef"`
-	DockerfilePath string `json:"dockerfilePath,omitempty"`
-	Registry      string `json:"registry,omitempty"`
-	ImageName     string `json:"imageName,omitempty"`
-	Suspended     bool   `json:"suspended,omitempty"`
+	AppRef          string `json:"appRef"`
+	DockerfilePath  string `json:"dockerfilePath,omitempty"`
+	Registry        string `json:"registry,omitempty"`
+	ImageName       string `json:"imageName,omitempty"`
+	RegistrySecret  string `json:"registrySecret,omitempty"`
+	TimeoutSeconds  int64  `json:"timeoutSeconds,omitempty"`
+	RunHistoryLimit *int32 `json:"runHistoryLimit,omitempty"`
+	Suspended       bool   `json:"suspended,omitempty"`
+	RunOnCreate     bool   `json:"runOnCreate,omitempty"`
 }
 
 type PipelineStatus struct {
-	Phase           PipelinePhase         `json:"phase,omitempty"`
-	CurrentStage    string                `json:"currentStage,omitempty"`
-	LastRunAt       *metav1.Time          `json:"lastRunAt,omitempty"`
-	LastRunDuration string                `json:"lastRunDuration,omitempty"`
-	Stages          []PipelineStageStatus `json:"stages,omitempty"`
-	Conditions      []metav1.Condition    `json:"conditions,omitempty"`
+	Phase              PipelinePhase         `json:"phase,omitempty"`
+	CurrentStage       string                `json:"currentStage,omitempty"`
+	LastRunAt          *metav1.Time          `json:"lastRunAt,omitempty"`
+	LastRunDuration    int64                 `json:"lastRunDuration,omitempty"`
+	Stages             []PipelineStageStatus `json:"stages,omitempty"`
+	RunNumber          int64                 `json:"runNumber,omitempty"`
+	ActiveRun          string                `json:"activeRun,omitempty"`
+	JobName            string                `json:"jobName,omitempty"`
+	Image              string                `json:"image,omitempty"`
+	ObservedRunRequest string                `json:"observedRunRequest,omitempty"`
+	Conditions         []metav1.Condition    `json:"conditions,omitempty"`
 }
 
 type Pipeline struct {
@@ -160,3 +479,142 @@ func (pl *PipelineList) DeepCopy() *PipelineList {
 	copy(out.Items, pl.Items)
 	return out
 }
+
+// ─── PipelineRun ──────────────────────────────────────────────────────────────
+
+type PipelineTrigger string
+
+const (
+	PipelineTriggerInitial PipelineTrigger = "Initial"
+	PipelineTriggerManual  PipelineTrigger = "Manual"
+	PipelineTriggerWebhook PipelineTrigger = "Webhook"
+)
+
+type PipelineRunSpec struct {
+	PipelineRef string          `json:"pipelineRef"`
+	Trigger     PipelineTrigger `json:"trigger,omitempty"`
+	Revision    string          `json:"revision,omitempty"`
+	TriggeredBy string          `json:"triggeredBy,omitempty"`
+}
+
+type PipelineRunStatus struct {
+	Phase      PipelinePhase         `json:"phase,omitempty"`
+	RunNumber  int64                 `json:"runNumber,omitempty"`
+	CommitSHA  string                `json:"commitSha,omitempty"`
+	Image      string                `json:"image,omitempty"`
+	JobName    string                `json:"jobName,omitempty"`
+	Stages     []PipelineStageStatus `json:"stages,omitempty"`
+	StartedAt  *metav1.Time          `json:"startedAt,omitempty"`
+	FinishedAt *metav1.Time          `json:"finishedAt,omitempty"`
+	Duration   int64                 `json:"duration,omitempty"`
+	LogChunks  int32                 `json:"logChunks,omitempty"`
+	Message    string                `json:"message,omitempty"`
+}
+
+type PipelineRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              PipelineRunSpec   `json:"spec,omitempty"`
+	Status            PipelineRunStatus `json:"status,omitempty"`
+}
+
+func (pr *PipelineRun) DeepCopyObject() runtime.Object { c := pr.DeepCopy(); return c }
+func (pr *PipelineRun) DeepCopy() *PipelineRun {
+	if pr == nil {
+		return nil
+	}
+	out := new(PipelineRun)
+	*out = *pr
+	return out
+}
+
+type PipelineRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PipelineRun `json:"items"`
+}
+
+func (prl *PipelineRunList) DeepCopyObject() runtime.Object { c := prl.DeepCopy(); return c }
+func (prl *PipelineRunList) DeepCopy() *PipelineRunList {
+	if prl == nil {
+		return nil
+	}
+	out := new(PipelineRunList)
+	out.TypeMeta = prl.TypeMeta
+	out.ListMeta = prl.ListMeta
+	out.Items = make([]PipelineRun, len(prl.Items))
+	copy(out.Items, prl.Items)
+	return out
+}
+
+// ─── AppPromotion ─────────────────────────────────────────────────────────────
+
+type AppPromotionDecision string
+
+const (
+	AppPromotionDecisionApproved AppPromotionDecision = "Approved"
+	AppPromotionDecisionRejected AppPromotionDecision = "Rejected"
+)
+
+type AppPromotionPhase string
+
+const (
+	AppPromotionPhasePendingApproval AppPromotionPhase = "PendingApproval"
+	AppPromotionPhasePromoted        AppPromotionPhase = "Promoted"
+	AppPromotionPhaseRejected        AppPromotionPhase = "Rejected"
+	AppPromotionPhaseFailed          AppPromotionPhase = "Failed"
+)
+
+type AppPromotionSpec struct {
+	App         string               `json:"app"`
+	Image       string               `json:"image,omitempty"`
+	RequestedBy string               `json:"requestedBy,omitempty"`
+	Decision    AppPromotionDecision `json:"decision,omitempty"`
+	DecidedBy   string               `json:"decidedBy,omitempty"`
+}
+
+type AppPromotionStatus struct {
+	Phase      AppPromotionPhase `json:"phase,omitempty"`
+	Source     *AppReference     `json:"source,omitempty"`
+	Revision   int64             `json:"revision,omitempty"`
+	Image      string            `json:"image,omitempty"`
+	CommitSHA  string            `json:"commitSha,omitempty"`
+	PromotedAt *metav1.Time      `json:"promotedAt,omitempty"`
+	Message    string            `json:"message,omitempty"`
+}
+
+type AppPromotion struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              AppPromotionSpec   `json:"spec,omitempty"`
+	Status            AppPromotionStatus `json:"status,omitempty"`
+}
+
+func (ap *AppPromotion) DeepCopyObject() runtime.Object { c := ap.DeepCopy(); return c }
+func (ap *AppPromotion) DeepCopy() *AppPromotion {
+	if ap == nil {
+		return nil
+	}
+	out := new(AppPromotion)
+	*out = *ap
+	return out
+}
+
+type AppPromotionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AppPromotion `json:"items"`
+}
+
+func (apl *AppPromotionList) DeepCopyObject() runtime.Object { c := apl.DeepCopy(); return c }
+func (apl *AppPromotionList) DeepCopy() *AppPromotionList {
+	if apl == nil {
+		return nil
+	}
+	out := new(AppPromotionList)
+	out.TypeMeta = apl.TypeMeta
+	out.ListMeta = apl.ListMeta
+	out.Items = make([]AppPromotion, len(apl.Items))
+	copy(out.Items, apl.Items)
+	return out
+}
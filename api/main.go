@@ -19,13 +19,27 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to create kubernetes client: %v", err)
 	}
+	clientset, err := k8s.NewClientset()
+	if err != nil {
+		log.Fatalf("failed to create kubernetes clientset: %v", err)
+	}
 
-	appsH := handlers.NewAppsHandler(k8sClient)
+	appsH := handlers.NewAppsHandler(k8sClient, clientset)
 	pipelinesH := handlers.NewPipelinesHandler(k8sClient)
 	clustersH := handlers.NewClustersHandler()
 	activityH := handlers.NewActivityHandler()
-	settingsH := handlers.NewSettingsHandler()
-	authH := handlers.NewAuthHandler()
+	settingsH := handlers.NewSettingsHandler(clientset)
+	authH := handlers.NewAuthHandler(clientset)
+	buildsH := handlers.NewBuildsHandler(k8sClient, clientset)
+	hooksH := handlers.NewHooksHandler(k8sClient)
+
+	if err := handlers.ConfigureAuth(clientset); err != nil {
+		log.Fatalf("auth configuration: %v", err)
+	}
+	oidcCfg, err := handlers.OIDCConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid OIDC configuration: %v", err)
+	}
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
@@ -39,27 +53,34 @@ func main() {
 	}))
 
 	r.Route("/api", func(api chi.Router) {
-		// Public: login endpoint (no auth required).
+		// Public: login endpoint (no auth required). Local login stays
+		// available alongside SSO as a break-glass option.
 		api.Post("/auth/login", authH.Login)
+		api.Post("/auth/refresh", authH.Refresh)
+		api.Get("/auth/jwks", handlers.JWKS)
+
+		// Public: OIDC single sign-on, when an identity provider is configured.
+		if oidcCfg != nil {
+			api.Route("/auth/oidc", handlers.NewOIDCHandler(*oidcCfg, clientset).Routes)
+		}
+
+		// Public: Git push webhooks, authenticated by their shared-secret signature.
+		api.Post("/hooks/{provider}", hooksH.Receive)
 
 		// Protected routes — all require a valid Bearer JWT.
 		api.Group(func(protected chi.Router) {
 			protected.Use(handlers.ValidateJWT)
 
 			protected.Get("/auth/me", authH.Me)
+			protected.Post("/auth/logout", authH.Logout)
 
 			protected.Route("/apps", appsH.Routes)
 			protected.Route("/pipelines", pipelinesH.Routes)
+			protected.Route("/builds", buildsH.Routes)
 			protected.Route("/clusters", clustersH.Routes)
-			protected.Get("/activity", activityH.List)
-
-			protected.Route("/settings", func(s chi.Router) {
-				s.Get("/team", settingsH.Team)
-				s.Get("/general", settingsH.General)
-				s.Get("/credentials", settingsH.Credentials)
-				s.Get("/integrations", settingsH.Integrations)
-				s.Get("/notifications", settingsH.Notifications)
-			})
+			protected.With(handlers.Authorize(handlers.PermActivityRead)).Get("/activity", activityH.List)
+
+			protected.Route("/settings", settingsH.Routes)
 		})
 	})
 
@@ -17,11 +17,14 @@ limitations under the License.
 package v1alpha1
 
 import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // AppPhase is the current lifecycle phase of an App.
-// +kubebuilder:validation:Enum=Pending;Building;Deploying;Healthy;Degraded;Failed;Suspended
+// +kubebuilder:validation:Enum=Pending;Building;Deploying;Canary;Preview;Paused;Promoting;Aborted;Healthy;Degraded;Failed;Suspended;Sleeping
 type AppPhase string
 
 const (
@@ -32,6 +35,39 @@ const (
 	AppPhaseDegraded  AppPhase = "Degraded"
 	AppPhaseFailed    AppPhase = "Failed"
 	AppPhaseSuspended AppPhase = "Suspended"
+
+	// AppPhaseCanary is a canary rollout shifting traffic step by step.
+	AppPhaseCanary AppPhase = "Canary"
+	// AppPhasePreview is a blue/green rollout whose new version is starting
+	// behind the preview Service.
+	AppPhasePreview AppPhase = "Preview"
+	// AppPhasePaused is a rollout waiting for manual promotion.
+	AppPhasePaused AppPhase = "Paused"
+	// AppPhasePromoting is a rollout moving the new version onto the stable
+	// Deployment.
+	AppPhasePromoting AppPhase = "Promoting"
+	// AppPhaseAborted is a rollout that was aborted; the stable version keeps
+	// serving until spec.image changes again.
+	AppPhaseAborted AppPhase = "Aborted"
+
+	// AppPhaseSleeping is an idle App scaled to zero until its next request.
+	// Unlike Suspended it wakes up on its own.
+	AppPhaseSleeping AppPhase = "Sleeping"
+)
+
+// AppStrategyType selects how a new image is rolled out.
+// +kubebuilder:validation:Enum=RollingUpdate;Canary;BlueGreen
+type AppStrategyType string
+
+const (
+	// AppStrategyRollingUpdate updates the Deployment in place.
+	AppStrategyRollingUpdate AppStrategyType = "RollingUpdate"
+	// AppStrategyCanary runs the new image in a second Deployment and shifts
+	// traffic to it in weighted steps.
+	AppStrategyCanary AppStrategyType = "Canary"
+	// AppStrategyBlueGreen runs the new image behind a preview Service and
+	// switches all traffic at once when promoted.
+	AppStrategyBlueGreen AppStrategyType = "BlueGreen"
 )
 
 // AppSpec defines the desired state of App.
@@ -80,6 +116,718 @@ type AppSpec struct {
 	// destination specifies the target namespace for the workload resources.
 	// +optional
 	Destination *AppDestination `json:"destination,omitempty"`
+
+	// promotion links the App to the environment it is promoted from, such
+	// as a production App to its staging App.
+	// +optional
+	Promotion *AppPromotionPolicy `json:"promotion,omitempty"`
+
+	// revisionHistoryLimit is the number of successful rollouts kept in
+	// status.history and available for rollback.
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=50
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// strategy controls how image changes are rolled out.
+	// Defaults to an in-place rolling update.
+	// +optional
+	Strategy *AppStrategy `json:"strategy,omitempty"`
+
+	// rollback configures automatic rollback of failed rollouts.
+	// +optional
+	Rollback *AppRollbackPolicy `json:"rollback,omitempty"`
+
+	// healthCheck configures the container's readiness, liveness and startup
+	// probes. Defaults to TCP probes on the app port.
+	// +optional
+	HealthCheck *AppHealthCheck `json:"healthCheck,omitempty"`
+
+	// resources are the CPU and memory requests and limits of the app
+	// container. Requests default to 100m CPU and 128Mi memory, capped by
+	// any limits.
+	// +optional
+	Resources *AppResources `json:"resources,omitempty"`
+
+	// autoscaling hands the replica count of the App's Deployment to a
+	// HorizontalPodAutoscaler. spec.replicas is ignored while it is set.
+	// +optional
+	Autoscaling *AppAutoscaling `json:"autoscaling,omitempty"`
+
+	// idle scales the App to zero after a period without requests and wakes
+	// it on the next one. Requests are counted by the operator's activator,
+	// so the App needs at least one custom domain.
+	// +optional
+	Idle *AppIdlePolicy `json:"idle,omitempty"`
+
+	// tls serves the App's custom domains over HTTPS with a certificate from
+	// a cert-manager issuer or an existing Secret.
+	// +optional
+	TLS *AppTLS `json:"tls,omitempty"`
+
+	// routing configures how traffic for the custom domains reaches the App.
+	// Unset fields fall back to the operator's defaults.
+	// +optional
+	Routing *AppRouting `json:"routing,omitempty"`
+
+	// sidecars are additional containers run next to the app container in
+	// every pod, e.g. proxies or log shippers.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Sidecars []AppContainer `json:"sidecars,omitempty"`
+
+	// initContainers run to completion, in order, before the app container
+	// and sidecars start.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	InitContainers []AppContainer `json:"initContainers,omitempty"`
+
+	// volumes are mounted into the app container at their mountPath and can
+	// be shared with sidecars and init containers through volumeMounts. They
+	// are scratch space, persistent storage or existing ConfigMaps and
+	// Secrets.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Volumes []AppVolume `json:"volumes,omitempty"`
+
+	// hooks are Jobs run around the rollout of each new image.
+	// +optional
+	Hooks *AppHooks `json:"hooks,omitempty"`
+}
+
+// AppContainer is an extra container in the App's pods.
+type AppContainer struct {
+	// name of the container, unique within the pod. The app container is
+	// named after the App.
+	// +required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// image is the container image to run.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// command overrides the image's entrypoint.
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// args overrides the image's arguments.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// env is a list of environment variables injected into the container.
+	// +optional
+	Env []AppEnvVar `json:"env,omitempty"`
+
+	// ports the container listens on.
+	// +optional
+	Ports []AppContainerPort `json:"ports,omitempty"`
+
+	// volumeMounts mount entries of spec.volumes into the container.
+	// +optional
+	VolumeMounts []AppVolumeMount `json:"volumeMounts,omitempty"`
+
+	// resources are the CPU and memory requests and limits of the container.
+	// +optional
+	Resources *AppResources `json:"resources,omitempty"`
+}
+
+// AppContainerPort is a port exposed by a sidecar.
+type AppContainerPort struct {
+	// name of the port.
+	// +optional
+	// +kubebuilder:validation:MaxLength=15
+	Name string `json:"name,omitempty"`
+
+	// containerPort is the port number.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ContainerPort int32 `json:"containerPort"`
+
+	// protocol of the port.
+	// +optional
+	// +kubebuilder:default=TCP
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+}
+
+// AppVolumeMount mounts one of the App's volumes into a container.
+type AppVolumeMount struct {
+	// name of the entry in spec.volumes.
+	// +required
+	Name string `json:"name"`
+
+	// mountPath is where the volume appears in the container.
+	// +required
+	// +kubebuilder:validation:Pattern=`^/`
+	MountPath string `json:"mountPath"`
+
+	// subPath mounts a single file or directory of the volume.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+
+	// readOnly mounts the volume read-only.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// AppVolume is a volume of the App's pods. At most one source may be set;
+// volumes without one are empty scratch directories.
+type AppVolume struct {
+	// name of the volume, referenced by volumeMounts.
+	// +required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// mountPath is where the volume appears in the app container. Volumes
+	// without one are only mounted by sidecars and init containers.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	MountPath string `json:"mountPath,omitempty"`
+
+	// subPath mounts a single file or directory of the volume at mountPath,
+	// e.g. one key of a ConfigMap as a config file.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+
+	// readOnly mounts the volume read-only in the app container. ConfigMap
+	// and Secret volumes are always read-only.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// emptyDir configures the scratch directory backing the volume.
+	// +optional
+	EmptyDir *AppEmptyDirVolume `json:"emptyDir,omitempty"`
+
+	// persistentVolumeClaim backs the volume with a PersistentVolumeClaim
+	// named <app>-<volume> that the operator creates.
+	// +optional
+	PersistentVolumeClaim *AppPVCVolume `json:"persistentVolumeClaim,omitempty"`
+
+	// configMap mounts the keys of an existing ConfigMap as files.
+	// +optional
+	ConfigMap *AppConfigMapVolume `json:"configMap,omitempty"`
+
+	// secret mounts the keys of an existing Secret as files.
+	// +optional
+	Secret *AppSecretVolume `json:"secret,omitempty"`
+}
+
+// AppEmptyDirVolume is a scratch directory living as long as the pod.
+type AppEmptyDirVolume struct {
+	// medium is "Memory" for a tmpfs, or empty for node storage.
+	// +optional
+	// +kubebuilder:validation:Enum="";Memory
+	Medium corev1.StorageMedium `json:"medium,omitempty"`
+
+	// sizeLimit caps the space the directory may use.
+	// +optional
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+}
+
+// AppVolumeReclaimPolicy is what happens to a PersistentVolumeClaim when the
+// App or the volume is deleted.
+// +kubebuilder:validation:Enum=Retain;Delete
+type AppVolumeReclaimPolicy string
+
+const (
+	// AppVolumeRetain keeps the claim and its data.
+	AppVolumeRetain AppVolumeReclaimPolicy = "Retain"
+	// AppVolumeDelete deletes the claim, and with most storage classes its
+	// data.
+	AppVolumeDelete AppVolumeReclaimPolicy = "Delete"
+)
+
+// AppPVCVolume is the template of a PersistentVolumeClaim.
+type AppPVCVolume struct {
+	// size is the requested capacity. It can grow when the storage class
+	// allows expansion but never shrink.
+	// +required
+	Size resource.Quantity `json:"size"`
+
+	// storageClassName selects the storage class. Defaults to the cluster
+	// default. It cannot be changed once the claim exists.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// accessModes of the claim. Defaults to ReadWriteOnce, which only allows
+	// pods on a single node to mount the volume.
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// reclaimPolicy decides whether the claim is deleted along with the App
+	// or when the volume is removed from spec.volumes.
+	// +optional
+	// +kubebuilder:default=Retain
+	ReclaimPolicy AppVolumeReclaimPolicy `json:"reclaimPolicy,omitempty"`
+}
+
+// AppKeyToPath projects one key of a ConfigMap or Secret to a file.
+type AppKeyToPath struct {
+	// key to project.
+	// +required
+	Key string `json:"key"`
+
+	// path of the file, relative to the mount point.
+	// +required
+	Path string `json:"path"`
+}
+
+// AppConfigMapVolume mounts an existing ConfigMap.
+type AppConfigMapVolume struct {
+	// name of the ConfigMap, in the App's target namespace.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// items limits the mounted keys and sets their file names. All keys are
+	// mounted under their own names when empty.
+	// +optional
+	Items []AppKeyToPath `json:"items,omitempty"`
+
+	// optional lets pods start while the ConfigMap is missing.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// AppSecretVolume mounts an existing Secret.
+type AppSecretVolume struct {
+	// name of the Secret, in the App's target namespace.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// items limits the mounted keys and sets their file names. All keys are
+	// mounted under their own names when empty.
+	// +optional
+	Items []AppKeyToPath `json:"items,omitempty"`
+
+	// optional lets pods start while the Secret is missing.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// AppHooks are Jobs run around the rollout of each new image.
+type AppHooks struct {
+	// preDeploy hooks run in order before a new image is deployed, e.g.
+	// database migrations. The new image is not deployed until all succeed.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	PreDeploy []AppHook `json:"preDeploy,omitempty"`
+
+	// postDeploy hooks run in order once the pods of a new image are ready.
+	// With the Canary and BlueGreen strategies the new image receives no
+	// traffic until all succeed; with RollingUpdate a failure marks the
+	// rollout failed.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	PostDeploy []AppHook `json:"postDeploy,omitempty"`
+}
+
+// AppHook is a Job run for each new image of the App.
+type AppHook struct {
+	// name of the hook. The Job is named <app>-<pre|post>-<name>-<hash>.
+	// +required
+	// +kubebuilder:validation:MaxLength=30
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// image to run. Defaults to the App image being deployed.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// command overrides the image's entrypoint.
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// args overrides the image's arguments.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// env is added to the App's environment for the hook.
+	// +optional
+	Env []AppEnvVar `json:"env,omitempty"`
+
+	// backoffLimit is how many times a failed hook is retried.
+	// +optional
+	// +kubebuilder:default=0
+	// +kubebuilder:validation:Minimum=0
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// timeoutSeconds bounds how long the hook may run.
+	// +optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// AppRoutingBackend is the kind of object that routes traffic to an App.
+// +kubebuilder:validation:Enum=Ingress;Gateway
+type AppRoutingBackend string
+
+const (
+	// AppRoutingIngress routes through a networking.k8s.io Ingress.
+	AppRoutingIngress AppRoutingBackend = "Ingress"
+	// AppRoutingGateway routes through Gateway API HTTPRoutes attached to
+	// a Gateway.
+	AppRoutingGateway AppRoutingBackend = "Gateway"
+)
+
+// AppRouting configures the routes to an App's custom domains.
+type AppRouting struct {
+	// backend selects Ingress or Gateway API routing.
+	// +optional
+	Backend AppRoutingBackend `json:"backend,omitempty"`
+
+	// ingressClassName is the IngressClass of the App's Ingresses.
+	// +optional
+	IngressClassName string `json:"ingressClassName,omitempty"`
+
+	// annotations are added to the App's Ingresses or HTTPRoutes, overriding
+	// the operator's default annotations.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// gateway is the Gateway the App's HTTPRoutes attach to.
+	// +optional
+	Gateway *AppGatewayRef `json:"gateway,omitempty"`
+
+	// paths are the path prefixes routed to the App. Each applies to the
+	// listed domains, or to every custom domain when none are listed.
+	// Domains without a path get /.
+	// +optional
+	Paths []AppRoutePath `json:"paths,omitempty"`
+
+	// redirects permanently redirect every request for a host, such as
+	// www.example.com, to another host, keeping the path.
+	// +optional
+	Redirects []AppRedirect `json:"redirects,omitempty"`
+
+	// forceHTTPS redirects plain HTTP requests to HTTPS.
+	// +optional
+	ForceHTTPS bool `json:"forceHTTPS,omitempty"`
+}
+
+// AppGatewayRef references a Gateway API Gateway and its listeners.
+type AppGatewayRef struct {
+	// name is the name of the Gateway.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// namespace is the namespace of the Gateway. Defaults to the workload
+	// namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// sectionName is the listener the App's routes attach to. Defaults to
+	// every listener that accepts them.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+
+	// httpSectionName is the plain HTTP listener that redirects to HTTPS
+	// when forceHTTPS is set.
+	// +optional
+	HTTPSectionName string `json:"httpSectionName,omitempty"`
+}
+
+// AppRoutePath routes a path prefix to the App.
+type AppRoutePath struct {
+	// path is the path prefix.
+	// +required
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+
+	// domains limits the path to these custom domains.
+	// +optional
+	Domains []string `json:"domains,omitempty"`
+}
+
+// AppRedirect redirects one host to another.
+type AppRedirect struct {
+	// from is the host being redirected. It must not be one of the App's
+	// custom domains.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+
+	// to is the host requests are redirected to.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to"`
+}
+
+// AppIssuerKind is the kind of a cert-manager issuer.
+// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+type AppIssuerKind string
+
+const (
+	AppIssuerKindIssuer        AppIssuerKind = "Issuer"
+	AppIssuerKindClusterIssuer AppIssuerKind = "ClusterIssuer"
+)
+
+// AppTLS configures the certificate for the App's custom domains. Exactly
+// one of issuer and secretName must be set.
+type AppTLS struct {
+	// issuer is the cert-manager issuer that obtains a certificate for every
+	// custom domain. The certificate is stored in the Secret <app>-tls.
+	// Gateway-routed Apps get their certificates from the Gateway's
+	// listeners instead.
+	// +optional
+	Issuer *AppIssuerRef `json:"issuer,omitempty"`
+
+	// secretName is an existing kubernetes.io/tls Secret, in the workload
+	// namespace, holding a certificate for the custom domains.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// AppIssuerRef references a cert-manager Issuer or ClusterIssuer.
+type AppIssuerRef struct {
+	// name is the name of the issuer.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// kind is Issuer, for an issuer in the workload namespace, or
+	// ClusterIssuer.
+	// +optional
+	// +kubebuilder:default=ClusterIssuer
+	Kind AppIssuerKind `json:"kind,omitempty"`
+}
+
+// AppSSLStatus is the state of a custom domain's certificate.
+// +kubebuilder:validation:Enum=None;Pending;Valid;Failed
+type AppSSLStatus string
+
+const (
+	// AppSSLStatusNone means the App does not configure TLS.
+	AppSSLStatusNone AppSSLStatus = "None"
+	// AppSSLStatusPending means the certificate has not been issued yet.
+	AppSSLStatusPending AppSSLStatus = "Pending"
+	// AppSSLStatusValid means a current certificate covers the domain.
+	AppSSLStatusValid AppSSLStatus = "Valid"
+	// AppSSLStatusFailed means issuance failed, or the certificate is
+	// missing, expired, or does not cover the domain.
+	AppSSLStatusFailed AppSSLStatus = "Failed"
+)
+
+// AppDomainStatus reports the certificate state of one custom domain.
+type AppDomainStatus struct {
+	// domain is the custom hostname.
+	Domain string `json:"domain"`
+
+	// sslStatus is the state of the domain's certificate.
+	SSLStatus AppSSLStatus `json:"sslStatus"`
+
+	// expiresAt is when the domain's certificate expires.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// message explains a Pending or Failed status.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// AppIdlePolicy configures scale-to-zero of an App without traffic.
+type AppIdlePolicy struct {
+	// afterMinutes is how long the App may go without a request before it is
+	// scaled to zero.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	AfterMinutes int32 `json:"afterMinutes"`
+}
+
+// AppAutoscaling configures the App's HorizontalPodAutoscaler.
+type AppAutoscaling struct {
+	// minReplicas is the lower bound of the replica count.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// maxReplicas is the upper bound of the replica count.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// targetCPUUtilizationPercentage is the average CPU utilization, relative
+	// to the CPU request, to scale towards. Defaults to 80 when no other
+	// metric is configured.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// targetMemoryUtilizationPercentage is the average memory utilization,
+	// relative to the memory request, to scale towards.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+
+	// metrics are additional pod, object or external metrics to scale on.
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+}
+
+// AppResources are the compute resources of the app container. Only cpu and
+// memory may be set.
+type AppResources struct {
+	// requests is the amount of each resource the container is scheduled with.
+	// +optional
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+
+	// limits is the maximum amount of each resource the container may use.
+	// +optional
+	Limits corev1.ResourceList `json:"limits,omitempty"`
+}
+
+// AppHealthCheck describes how the container's health is probed.
+type AppHealthCheck struct {
+	// path switches the probes to HTTP GET requests for this path. When
+	// empty the probes open a TCP connection instead.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path,omitempty"`
+
+	// port is probed instead of spec.port.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// headers are sent with HTTP probe requests.
+	// +optional
+	Headers []AppHTTPHeader `json:"headers,omitempty"`
+
+	// readiness tunes the readiness probe.
+	// +optional
+	Readiness *AppProbeSettings `json:"readiness,omitempty"`
+
+	// liveness tunes the liveness probe.
+	// +optional
+	Liveness *AppProbeSettings `json:"liveness,omitempty"`
+
+	// startup adds a startup probe that holds off the other probes until it
+	// succeeds. No startup probe is configured when unset.
+	// +optional
+	Startup *AppProbeSettings `json:"startup,omitempty"`
+}
+
+// AppHTTPHeader is a header sent with HTTP probe requests.
+type AppHTTPHeader struct {
+	// name of the header.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// value of the header.
+	// +required
+	Value string `json:"value"`
+}
+
+// AppProbeSettings tunes the timing and thresholds of a probe. Unset fields
+// keep the controller's defaults.
+type AppProbeSettings struct {
+	// initialDelaySeconds before the first probe.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// periodSeconds between probes.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// timeoutSeconds after which a probe fails.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// successThreshold is the number of consecutive successes after a
+	// failure for the probe to pass. Must be 1 for liveness and startup.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+
+	// failureThreshold is the number of consecutive failures for the probe
+	// to fail.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// AppRollbackPolicy configures what happens when a rollout fails.
+type AppRollbackPolicy struct {
+	// auto restores the image and env of the latest healthy revision when a
+	// rollout exceeds its progress deadline or its pods crash-loop.
+	// +optional
+	Auto bool `json:"auto,omitempty"`
+
+	// crashLoopThreshold is the number of container restarts of the new
+	// image after which the rollout is considered failed.
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	CrashLoopThreshold *int32 `json:"crashLoopThreshold,omitempty"`
+}
+
+// AppStrategy describes how new images are rolled out.
+type AppStrategy struct {
+	// type of rollout.
+	// +optional
+	// +kubebuilder:default=RollingUpdate
+	Type AppStrategyType `json:"type,omitempty"`
+
+	// canary configures the Canary strategy.
+	// +optional
+	Canary *CanaryStrategy `json:"canary,omitempty"`
+
+	// blueGreen configures the BlueGreen strategy.
+	// +optional
+	BlueGreen *BlueGreenStrategy `json:"blueGreen,omitempty"`
+}
+
+// CanaryStrategy lists the traffic steps of a canary rollout.
+type CanaryStrategy struct {
+	// steps are applied in order; each sends weight percent of traffic to the
+	// new version. The rollout is promoted after the last step.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Steps []CanaryStep `json:"steps"`
+}
+
+// CanaryStep is a single traffic shift of a canary rollout.
+type CanaryStep struct {
+	// weight is the percentage of traffic routed to the new version.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+
+	// pauseSeconds is how long to hold this step once the canary is ready.
+	// When unset the rollout waits for a manual promotion.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	PauseSeconds *int32 `json:"pauseSeconds,omitempty"`
+}
+
+// BlueGreenStrategy configures a blue/green rollout.
+type BlueGreenStrategy struct {
+	// autoPromotionSeconds promotes the preview automatically once it has
+	// been ready for this long. When unset promotion is manual.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	AutoPromotionSeconds *int32 `json:"autoPromotionSeconds,omitempty"`
 }
 
 // AppEnvVar is an environment variable with an optional Secret reference.
@@ -117,6 +865,138 @@ type AppDestination struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// AppReference names an App, possibly in another namespace.
+type AppReference struct {
+	// name of the App.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// namespace of the App. Defaults to the namespace of the referring
+	// object.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// AppPromotionPolicy configures how images are promoted into an App.
+type AppPromotionPolicy struct {
+	// from is the App whose successfully rolled out images are promoted
+	// into this one.
+	// +required
+	From AppReference `json:"from"`
+
+	// requireApproval holds each AppPromotion until it has been approved.
+	// +optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
+}
+
+// AppRevisionStatus describes what happened to a recorded revision.
+// +kubebuilder:validation:Enum=Succeeded;RolledBack
+type AppRevisionStatus string
+
+const (
+	// AppRevisionSucceeded is a revision that rolled out successfully.
+	AppRevisionSucceeded AppRevisionStatus = "Succeeded"
+	// AppRevisionRolledBack is a revision that was replaced by a rollback to
+	// an earlier revision.
+	AppRevisionRolledBack AppRevisionStatus = "RolledBack"
+)
+
+// AppRevision records a single successful rollout of an App.
+type AppRevision struct {
+	// revision is the monotonically increasing revision number.
+	// +required
+	Revision int64 `json:"revision"`
+
+	// image is the container image that was rolled out.
+	// +required
+	Image string `json:"image"`
+
+	// commitSha is the Git commit the image was built from, when known.
+	// +optional
+	CommitSHA string `json:"commitSha,omitempty"`
+
+	// replicas is the desired replica count at the time of the rollout.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// env is the environment the revision was rolled out with, kept so a
+	// rollback can restore it.
+	// +optional
+	Env []AppEnvVar `json:"env,omitempty"`
+
+	// envHash is a short digest of env used to tell rollouts apart.
+	// +optional
+	EnvHash string `json:"envHash,omitempty"`
+
+	// deployedAt is the timestamp at which the rollout became healthy.
+	// +required
+	DeployedAt metav1.Time `json:"deployedAt"`
+
+	// deployedBy is the user or system that initiated the rollout.
+	// +optional
+	DeployedBy string `json:"deployedBy,omitempty"`
+
+	// duration is the time in seconds the rollout took to become healthy.
+	// +optional
+	Duration int64 `json:"duration,omitempty"`
+
+	// status records whether the revision was later rolled back.
+	// +optional
+	Status AppRevisionStatus `json:"status,omitempty"`
+}
+
+// AppRolloutStatus is the progress of a Canary or BlueGreen rollout.
+type AppRolloutStatus struct {
+	// stableImage is the image serving production traffic.
+	// +optional
+	StableImage string `json:"stableImage,omitempty"`
+
+	// newImage is the image being rolled out; empty when no rollout is in
+	// progress.
+	// +optional
+	NewImage string `json:"newImage,omitempty"`
+
+	// currentStep is the zero-based index of the active canary step.
+	// +optional
+	CurrentStep int32 `json:"currentStep,omitempty"`
+
+	// weight is the percentage of traffic currently sent to newImage.
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+
+	// stepStartedAt is when the current step's pods became ready, or when the
+	// blue/green preview became ready.
+	// +optional
+	StepStartedAt *metav1.Time `json:"stepStartedAt,omitempty"`
+
+	// paused is true while the rollout waits for a manual promotion.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// promoting is true once newImage is being rolled onto the stable
+	// Deployment.
+	// +optional
+	Promoting bool `json:"promoting,omitempty"`
+
+	// abortedImage is the image of the last aborted rollout. It is not
+	// retried until spec.image changes.
+	// +optional
+	AbortedImage string `json:"abortedImage,omitempty"`
+
+	// observedPromoteRequest is the last promote request handled.
+	// +optional
+	ObservedPromoteRequest string `json:"observedPromoteRequest,omitempty"`
+
+	// observedAbortRequest is the last abort request handled.
+	// +optional
+	ObservedAbortRequest string `json:"observedAbortRequest,omitempty"`
+
+	// message describes the current rollout step.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // AppStatus defines the observed state of App.
 type AppStatus struct {
 	// phase is the high-level lifecycle phase of the App.
@@ -147,6 +1027,38 @@ type AppStatus struct {
 	// +optional
 	LastDeployedAt *metav1.Time `json:"lastDeployedAt,omitempty"`
 
+	// desiredReplicas is the replica count the HorizontalPodAutoscaler last
+	// asked for; only set while autoscaling is on.
+	// +optional
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+
+	// currentRevision is the revision number of the latest successful rollout.
+	// +optional
+	CurrentRevision int64 `json:"currentRevision,omitempty"`
+
+	// rolloutStartedAt is set while a rollout that has not yet become healthy
+	// is in progress.
+	// +optional
+	RolloutStartedAt *metav1.Time `json:"rolloutStartedAt,omitempty"`
+
+	// history lists the most recent successful rollouts, oldest first.
+	// +optional
+	History []AppRevision `json:"history,omitempty"`
+
+	// domains reports the certificate state of each custom domain.
+	// +optional
+	Domains []AppDomainStatus `json:"domains,omitempty"`
+
+	// rollout tracks an in-progress Canary or BlueGreen rollout.
+	// +optional
+	Rollout *AppRolloutStatus `json:"rollout,omitempty"`
+
+	// namespace is the namespace the App's workload was last deployed to.
+	// When spec.destination.namespace changes, the App's children are
+	// removed from here.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
 	// conditions represent the current state of the App resource.
 	// +listType=map
 	// +listMapKey=type
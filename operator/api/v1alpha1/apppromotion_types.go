@@ -0,0 +1,146 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AppPromotionDecision is the outcome of a promotion's review.
+// +kubebuilder:validation:Enum=Approved;Rejected
+type AppPromotionDecision string
+
+const (
+	// AppPromotionDecisionApproved lets the promotion proceed.
+	AppPromotionDecisionApproved AppPromotionDecision = "Approved"
+	// AppPromotionDecisionRejected discards the promotion.
+	AppPromotionDecisionRejected AppPromotionDecision = "Rejected"
+)
+
+// AppPromotionPhase is the lifecycle phase of an AppPromotion.
+// +kubebuilder:validation:Enum=PendingApproval;Promoted;Rejected;Failed
+type AppPromotionPhase string
+
+const (
+	// AppPromotionPhasePendingApproval is a promotion waiting to be approved.
+	AppPromotionPhasePendingApproval AppPromotionPhase = "PendingApproval"
+	// AppPromotionPhasePromoted is a promotion whose image was written to
+	// the App.
+	AppPromotionPhasePromoted AppPromotionPhase = "Promoted"
+	// AppPromotionPhaseRejected is a promotion that was turned down.
+	AppPromotionPhaseRejected AppPromotionPhase = "Rejected"
+	// AppPromotionPhaseFailed is a promotion that could not be carried out.
+	AppPromotionPhaseFailed AppPromotionPhase = "Failed"
+)
+
+// AppPromotionSpec defines the desired state of AppPromotion.
+type AppPromotionSpec struct {
+	// app is the App in the same namespace that receives the image. Its
+	// spec.promotion.from names the App the image is promoted from.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	App string `json:"app"`
+
+	// image is the image to promote. It must be one the source App rolled
+	// out successfully; defaults to the source App's current revision.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// requestedBy is the user who asked for the promotion.
+	// +optional
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// decision approves or rejects a promotion into an App that requires
+	// approval.
+	// +optional
+	Decision AppPromotionDecision `json:"decision,omitempty"`
+
+	// decidedBy is the user who approved or rejected the promotion.
+	// +optional
+	DecidedBy string `json:"decidedBy,omitempty"`
+}
+
+// AppPromotionStatus defines the observed state of AppPromotion.
+type AppPromotionStatus struct {
+	// phase is the lifecycle phase of the promotion.
+	// +optional
+	Phase AppPromotionPhase `json:"phase,omitempty"`
+
+	// source is the App the image was promoted from.
+	// +optional
+	Source *AppReference `json:"source,omitempty"`
+
+	// revision is the source App's revision that rolled out the image.
+	// +optional
+	Revision int64 `json:"revision,omitempty"`
+
+	// image is the promoted image.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// commitSha is the Git commit the image was built from, when known.
+	// +optional
+	CommitSHA string `json:"commitSha,omitempty"`
+
+	// promotedAt is the timestamp at which the image was written to the App.
+	// +optional
+	PromotedAt *metav1.Time `json:"promotedAt,omitempty"`
+
+	// message is a human-readable explanation of the current phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="App",type="string",JSONPath=".spec.app"
+// +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".status.image"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Requested By",type="string",JSONPath=".spec.requestedBy",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// AppPromotion is the Schema for the apppromotions API. Each AppPromotion
+// records one promotion of a verified image from an App's source
+// environment into the App, and is owned by that App.
+type AppPromotion struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is standard object metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec defines the desired state of AppPromotion.
+	// +required
+	Spec AppPromotionSpec `json:"spec"`
+
+	// status defines the observed state of AppPromotion.
+	// +optional
+	Status AppPromotionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AppPromotionList contains a list of AppPromotion.
+type AppPromotionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AppPromotion `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AppPromotion{}, &AppPromotionList{})
+}
@@ -62,10 +62,35 @@ type PipelineSpec struct {
 	// +optional
 	BuildArgs []BuildArg `json:"buildArgs,omitempty"`
 
+	// registrySecret is the name of a kubernetes.io/dockerconfigjson Secret in
+	// the same namespace holding credentials used to push to the registry.
+	// +optional
+	RegistrySecret string `json:"registrySecret,omitempty"`
+
+	// timeoutSeconds bounds the wall-clock duration of a single build run.
+	// +optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=3600
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+
+	// runHistoryLimit is the number of finished PipelineRuns to retain. Older
+	// runs are deleted together with their build Jobs.
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	RunHistoryLimit *int32 `json:"runHistoryLimit,omitempty"`
+
 	// suspended temporarily halts pipeline reconciliation without deleting
 	// the resource.
 	// +optional
 	Suspended bool `json:"suspended,omitempty"`
+
+	// runOnCreate starts a build as soon as the Pipeline is created. When
+	// false (the default) nothing is built until a run is requested through
+	// the run-requested-at annotation, the API or a Git push webhook.
+	// +optional
+	RunOnCreate bool `json:"runOnCreate,omitempty"`
 }
 
 // BuildArg is a key/value pair forwarded as a Docker build argument.
@@ -115,6 +140,27 @@ type PipelineStatus struct {
 	// +optional
 	Stages []PipelineStageStatus `json:"stages,omitempty"`
 
+	// runNumber is incremented every time a new run is started.
+	// +optional
+	RunNumber int64 `json:"runNumber,omitempty"`
+
+	// activeRun is the name of the PipelineRun currently executing.
+	// +optional
+	ActiveRun string `json:"activeRun,omitempty"`
+
+	// jobName is the name of the build Job backing the most recent run.
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// image is the image reference produced by the most recent run.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// observedRunRequest is the value of the run-requested-at annotation that
+	// the most recent run was started for.
+	// +optional
+	ObservedRunRequest string `json:"observedRunRequest,omitempty"`
+
 	// conditions represent the current state of the Pipeline resource.
 	// +listType=map
 	// +listMapKey=type
@@ -0,0 +1,145 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PipelineTrigger identifies what caused a PipelineRun to be created.
+// +kubebuilder:validation:Enum=Initial;Manual;Webhook
+type PipelineTrigger string
+
+const (
+	// PipelineTriggerInitial is the first run started when a Pipeline is created.
+	PipelineTriggerInitial PipelineTrigger = "Initial"
+	// PipelineTriggerManual is a run requested through the run-requested-at
+	// annotation or the API.
+	PipelineTriggerManual PipelineTrigger = "Manual"
+	// PipelineTriggerWebhook is a run started by a Git push event.
+	PipelineTriggerWebhook PipelineTrigger = "Webhook"
+)
+
+// PipelineRunSpec defines the desired state of PipelineRun.
+type PipelineRunSpec struct {
+	// pipelineRef is the name of the Pipeline in the same namespace that
+	// executes this run.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	PipelineRef string `json:"pipelineRef"`
+
+	// trigger records what caused this run.
+	// +optional
+	// +kubebuilder:default=Manual
+	Trigger PipelineTrigger `json:"trigger,omitempty"`
+
+	// revision is the Git ref or commit SHA to build. Defaults to the App's
+	// branch when empty.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// triggeredBy is a human-readable description of who or what started the run.
+	// +optional
+	TriggeredBy string `json:"triggeredBy,omitempty"`
+}
+
+// PipelineRunStatus defines the observed state of PipelineRun.
+type PipelineRunStatus struct {
+	// phase is the lifecycle phase of this run.
+	// +optional
+	Phase PipelinePhase `json:"phase,omitempty"`
+
+	// runNumber is the sequence number assigned when the run started.
+	// +optional
+	RunNumber int64 `json:"runNumber,omitempty"`
+
+	// commitSha is the commit that was checked out and built.
+	// +optional
+	CommitSHA string `json:"commitSha,omitempty"`
+
+	// image is the image reference produced by this run.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// jobName is the name of the build Job executing this run.
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// stages contains per-stage status entries for this run.
+	// +optional
+	Stages []PipelineStageStatus `json:"stages,omitempty"`
+
+	// startedAt is the timestamp when the build Job was created.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// finishedAt is the timestamp when the run reached a terminal phase.
+	// +optional
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+
+	// duration is the wall-clock duration of the run in seconds.
+	// +optional
+	Duration int64 `json:"duration,omitempty"`
+
+	// logChunks is the number of ConfigMaps named <run>-logs-<index> that
+	// hold the captured build output once the run has finished.
+	// +optional
+	LogChunks int32 `json:"logChunks,omitempty"`
+
+	// message is a human-readable explanation of the current phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Pipeline",type="string",JSONPath=".spec.pipelineRef"
+// +kubebuilder:printcolumn:name="Run",type="integer",JSONPath=".status.runNumber"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Commit",type="string",JSONPath=".status.commitSha",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// PipelineRun is the Schema for the pipelineruns API. Each PipelineRun records
+// a single execution of a Pipeline and is owned by it.
+type PipelineRun struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is standard object metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec defines the desired state of PipelineRun.
+	// +required
+	Spec PipelineRunSpec `json:"spec"`
+
+	// status defines the observed state of PipelineRun.
+	// +optional
+	Status PipelineRunStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PipelineRunList contains a list of PipelineRun.
+type PipelineRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PipelineRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PipelineRun{}, &PipelineRunList{})
+}
@@ -19,16 +19,23 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -36,6 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+	"github.com/nimi-io/FlowCD/operator/internal/activator"
 	"github.com/nimi-io/FlowCD/operator/internal/controller"
 	webhookv1alpha1 "github.com/nimi-io/FlowCD/operator/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
@@ -62,6 +70,9 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var activatorAddr, activatorService string
+	var routingBackend, ingressClass, ingressAnnotations, gateway string
+	var baseDomain string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -80,6 +91,19 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&activatorAddr, "activator-bind-address", ":8082",
+		"The address the activator proxy for idle Apps binds to, or 0 to disable it.")
+	flag.StringVar(&activatorService, "activator-service", "",
+		"The DNS name of the Service in front of the activator. Idle Apps are not scaled to zero while it is empty.")
+	flag.StringVar(&routingBackend, "routing-backend", string(platformv1alpha1.AppRoutingIngress),
+		"How Apps are routed unless they choose otherwise: Ingress or Gateway.")
+	flag.StringVar(&ingressClass, "ingress-class", "", "The IngressClass of App Ingresses. Empty uses the cluster default.")
+	flag.StringVar(&ingressAnnotations, "ingress-annotations", "nginx.ingress.kubernetes.io/proxy-body-size=0",
+		"Comma-separated key=value annotations set on every App Ingress and HTTPRoute.")
+	flag.StringVar(&gateway, "gateway", "",
+		"The Gateway HTTPRoutes attach to, as [namespace/]name[:section[,httpSection]].")
+	flag.StringVar(&baseDomain, "base-domain", "",
+		"The wildcard domain, e.g. *.apps.example.com, under which every App is served as <name>.<namespace>.<base>.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -162,6 +186,11 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "43938e5b.flowcd.io",
+		// Secrets are read straight from the API server so that their data,
+		// cluster-wide, is never held in the cache.
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -179,21 +208,92 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := (&controller.AppReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	routing := controller.RoutingDefaults{
+		Backend:          platformv1alpha1.AppRoutingBackend(routingBackend),
+		IngressClassName: ingressClass,
+		Annotations:      map[string]string{},
+	}
+	if routing.Backend != platformv1alpha1.AppRoutingIngress && routing.Backend != platformv1alpha1.AppRoutingGateway {
+		setupLog.Error(nil, "Invalid routing backend", "routing-backend", routingBackend)
+		os.Exit(1)
+	}
+	for _, kv := range strings.Split(ingressAnnotations, ",") {
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			setupLog.Error(nil, "Invalid ingress annotation", "annotation", kv)
+			os.Exit(1)
+		}
+		routing.Annotations[k] = v
+	}
+	if routing.Gateway, err = controller.ParseGatewayRef(gateway); err != nil {
+		setupLog.Error(err, "Invalid Gateway reference")
+		os.Exit(1)
+	}
+
+	baseDomain = strings.TrimPrefix(baseDomain, "*.")
+	if baseDomain != "" {
+		if msgs := validation.IsDNS1123Subdomain(baseDomain); len(msgs) > 0 {
+			setupLog.Error(nil, "Invalid base domain", "base-domain", baseDomain, "reason", strings.Join(msgs, ", "))
+			os.Exit(1)
+		}
+	}
+
+	appReconciler := &controller.AppReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorder("app-controller"),
+		Routing:    routing,
+		BaseDomain: baseDomain,
+	}
+	if activatorAddr != "0" {
+		var port int64
+		_, portStr, err := net.SplitHostPort(activatorAddr)
+		if err == nil {
+			port, err = strconv.ParseInt(portStr, 10, 32)
+		}
+		if err != nil {
+			setupLog.Error(err, "Invalid activator bind address", "address", activatorAddr)
+			os.Exit(1)
+		}
+		if err := (&activator.Activator{
+			Client:      mgr.GetClient(),
+			BindAddress: activatorAddr,
+			BaseDomain:  baseDomain,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "Failed to set up activator")
+			os.Exit(1)
+		}
+		appReconciler.ActivatorHost = activatorService
+		appReconciler.ActivatorPort = int32(port)
+	}
+	if err := appReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Failed to create controller", "controller", "App")
 		os.Exit(1)
 	}
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "Failed to create clientset")
+		os.Exit(1)
+	}
 	if err := (&controller.PipelineReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		PodLogs: clientset.CoreV1(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "Failed to create controller", "controller", "Pipeline")
+		os.Exit(1)
+	}
+	if err := (&controller.AppPromotionReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "Failed to create controller", "controller", "Pipeline")
+		setupLog.Error(err, "Failed to create controller", "controller", "AppPromotion")
 		os.Exit(1)
 	}
-	if err := webhookv1alpha1.SetupAppWebhookWithManager(mgr); err != nil {
+	if err := webhookv1alpha1.SetupAppWebhookWithManager(mgr, baseDomain); err != nil {
 		setupLog.Error(err, "Failed to set up webhook", "webhook", "App")
 		os.Exit(1)
 	}
@@ -0,0 +1,258 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package activator implements the reverse proxy that sits in front of Apps
+// with an idle policy. It records when each App last received a request and
+// wakes sleeping Apps by bumping that record, holding the request until the
+// App has a ready replica.
+package activator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+	"github.com/nimi-io/FlowCD/operator/internal/controller"
+)
+
+const (
+	// domainIndex indexes Apps by each entry of spec.domains and their
+	// default host.
+	domainIndex = "spec.domains"
+
+	defaultActivityInterval = 30 * time.Second
+	defaultWakeTimeout      = 60 * time.Second
+	pollInterval            = 500 * time.Millisecond
+)
+
+var log = logf.Log.WithName("activator")
+
+// Activator proxies requests to idle-enabled Apps by Host header.
+type Activator struct {
+	Client client.Client
+	// BindAddress is the address the proxy listens on.
+	BindAddress string
+	// ActivityInterval is the minimum time between two writes of an App's
+	// last request time. Defaults to 30s.
+	ActivityInterval time.Duration
+	// WakeTimeout bounds how long a request waits for a sleeping App.
+	// Defaults to 60s.
+	WakeTimeout time.Duration
+	// BaseDomain is the operator's base domain, under which each App is
+	// also reachable at its default host.
+	BaseDomain string
+
+	// target returns the upstream URL of an App; overridden in tests.
+	target func(app *platformv1alpha1.App) *url.URL
+}
+
+// SetupWithManager indexes Apps by domain and runs the activator with the
+// manager.
+func (a *Activator) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &platformv1alpha1.App{}, domainIndex, a.indexDomains); err != nil {
+		return fmt.Errorf("index App domains: %w", err)
+	}
+	return mgr.Add(a)
+}
+
+// indexDomains returns the domains and default host of an idle-enabled App.
+func (a *Activator) indexDomains(obj client.Object) []string {
+	app := obj.(*platformv1alpha1.App)
+	if app.Spec.Idle == nil {
+		return nil
+	}
+	domains := app.Spec.Domains
+	if host := controller.DefaultHost(app, a.BaseDomain); host != "" {
+		domains = append(slices.Clone(domains), host)
+	}
+	return domains
+}
+
+// NeedLeaderElection lets every replica of the operator serve traffic.
+func (a *Activator) NeedLeaderElection() bool { return false }
+
+// Start serves the proxy until ctx is cancelled.
+func (a *Activator) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              a.BindAddress,
+		Handler:           a,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	log.Info("Starting activator", "address", a.BindAddress)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP routes the request to the App owning its host, waking the App
+// first when it is asleep.
+func (a *Activator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	apps := &platformv1alpha1.AppList{}
+	if err := a.Client.List(ctx, apps, client.MatchingFields{domainIndex: host}); err != nil {
+		log.Error(err, "Failed to look up App", "host", host)
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if len(apps.Items) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if len(apps.Items) > 1 {
+		// Picking one would make routing depend on list order.
+		names := make([]string, 0, len(apps.Items))
+		for _, app := range apps.Items {
+			names = append(names, app.Namespace+"/"+app.Name)
+		}
+		log.Error(nil, "Host is claimed by more than one App", "host", host, "apps", names)
+		http.Error(w, "host is claimed by more than one app", http.StatusConflict)
+		return
+	}
+	app := &apps.Items[0]
+	if app.Spec.Suspended {
+		unavailable(w, "app is suspended")
+		return
+	}
+
+	sleeping := app.Status.Phase == platformv1alpha1.AppPhaseSleeping
+	if err := a.recordRequest(ctx, app, sleeping); err != nil {
+		log.Error(err, "Failed to record request", "app", app.Name, "namespace", app.Namespace)
+		if sleeping {
+			unavailable(w, "app is waking up")
+			return
+		}
+	}
+	if sleeping {
+		log.Info("Waking App", "app", app.Name, "namespace", app.Namespace)
+		var err error
+		if app, err = a.waitReady(ctx, client.ObjectKeyFromObject(app)); err != nil {
+			unavailable(w, "app is waking up")
+			return
+		}
+	}
+
+	a.proxy(app).ServeHTTP(w, r)
+}
+
+// recordRequest stores the request time on the App, at most once per
+// activity interval unless the App has to be woken.
+func (a *Activator) recordRequest(ctx context.Context, app *platformv1alpha1.App, wake bool) error {
+	now := time.Now()
+	interval := a.ActivityInterval
+	if interval == 0 {
+		interval = defaultActivityInterval
+	}
+	if last, err := time.Parse(time.RFC3339, app.Annotations[controller.LastRequestAnnotation]); err == nil && !wake && now.Sub(last) < interval {
+		return nil
+	}
+
+	patch := client.MergeFrom(app.DeepCopy())
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	app.Annotations[controller.LastRequestAnnotation] = now.UTC().Format(time.RFC3339)
+	return a.Client.Patch(ctx, app, patch)
+}
+
+// waitReady polls the App until it is awake with a ready replica.
+func (a *Activator) waitReady(ctx context.Context, key types.NamespacedName) (*platformv1alpha1.App, error) {
+	timeout := a.WakeTimeout
+	if timeout == 0 {
+		timeout = defaultWakeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		app := &platformv1alpha1.App{}
+		if err := a.Client.Get(ctx, key, app); err != nil {
+			return nil, err
+		}
+		if app.Status.Phase != platformv1alpha1.AppPhaseSleeping && app.Status.ReadyReplicas > 0 {
+			return app, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// proxy returns a reverse proxy to the App's Service that keeps the original
+// Host and forwarding headers set by the ingress controller.
+func (a *Activator) proxy(app *platformv1alpha1.App) *httputil.ReverseProxy {
+	target := serviceURL(app)
+	if a.target != nil {
+		target = a.target(app)
+	}
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.Host = pr.In.Host
+			for _, h := range []string{"X-Forwarded-For", "X-Forwarded-Host", "X-Forwarded-Proto"} {
+				if v, ok := pr.In.Header[h]; ok {
+					pr.Out.Header[h] = v
+				}
+			}
+		},
+	}
+}
+
+// serviceURL is the in-cluster address of the App's Service.
+func serviceURL(app *platformv1alpha1.App) *url.URL {
+	namespace := app.Namespace
+	if app.Spec.Destination != nil && app.Spec.Destination.Namespace != "" {
+		namespace = app.Spec.Destination.Namespace
+	}
+	port := app.Spec.Port
+	if port == 0 {
+		port = 8080
+	}
+	return &url.URL{Scheme: "http", Host: fmt.Sprintf("%s.%s.svc:%d", app.Name, namespace, port)}
+}
+
+// unavailable responds 503 asking the client to retry shortly.
+func unavailable(w http.ResponseWriter, msg string) {
+	w.Header().Set("Retry-After", "10")
+	http.Error(w, msg, http.StatusServiceUnavailable)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+	"github.com/nimi-io/FlowCD/operator/internal/controller"
+)
+
+func newActivator(t *testing.T, upstream *httptest.Server, apps ...client.Object) (*Activator, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := platformv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	target, _ := url.Parse(upstream.URL)
+	a := &Activator{
+		WakeTimeout: 2 * time.Second,
+		BaseDomain:  "apps.example.com",
+		target:      func(*platformv1alpha1.App) *url.URL { return target },
+	}
+	a.Client = fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(apps...).
+		WithStatusSubresource(&platformv1alpha1.App{}).
+		WithIndex(&platformv1alpha1.App{}, domainIndex, a.indexDomains).
+		Build()
+	return a, a.Client
+}
+
+func idleApp(phase platformv1alpha1.AppPhase, ready int32) *platformv1alpha1.App {
+	return &platformv1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: platformv1alpha1.AppSpec{
+			RepoUrl: "https://github.com/acme/web",
+			Domains: []string{"web.example.com"},
+			Idle:    &platformv1alpha1.AppIdlePolicy{AfterMinutes: 15},
+		},
+		Status: platformv1alpha1.AppStatus{Phase: phase, ReadyReplicas: ready},
+	}
+}
+
+func upstreamServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello from "+r.Host)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestActivatorProxiesAndRecordsRequests(t *testing.T) {
+	a, c := newActivator(t, upstreamServer(t), idleApp(platformv1alpha1.AppPhaseHealthy, 1))
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://web.example.com/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Body.String(); got != "hello from web.example.com" {
+		t.Fatalf("unexpected upstream response %q", got)
+	}
+
+	app := &platformv1alpha1.App{}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: "web", Namespace: "default"}, app); err != nil {
+		t.Fatal(err)
+	}
+	if app.Annotations[controller.LastRequestAnnotation] == "" {
+		t.Fatal("expected the request time to be recorded")
+	}
+}
+
+func TestActivatorServesDefaultHost(t *testing.T) {
+	a, _ := newActivator(t, upstreamServer(t), idleApp(platformv1alpha1.AppPhaseHealthy, 1))
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://web.default.apps.example.com/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestActivatorUnknownHost(t *testing.T) {
+	a, _ := newActivator(t, upstreamServer(t), idleApp(platformv1alpha1.AppPhaseHealthy, 1))
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://other.example.com/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestActivatorAmbiguousHost(t *testing.T) {
+	other := idleApp(platformv1alpha1.AppPhaseHealthy, 1)
+	other.Namespace = "staging"
+	a, _ := newActivator(t, upstreamServer(t), idleApp(platformv1alpha1.AppPhaseHealthy, 1), other)
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://web.example.com/", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+}
+
+func TestActivatorWakesSleepingApp(t *testing.T) {
+	a, c := newActivator(t, upstreamServer(t), idleApp(platformv1alpha1.AppPhaseSleeping, 0))
+
+	// Stand in for the controller: once the request is recorded, report the
+	// App as awake.
+	go func() {
+		ctx := context.Background()
+		for range 40 {
+			time.Sleep(50 * time.Millisecond)
+			app := &platformv1alpha1.App{}
+			if err := c.Get(ctx, client.ObjectKey{Name: "web", Namespace: "default"}, app); err != nil {
+				return
+			}
+			if app.Annotations[controller.LastRequestAnnotation] == "" {
+				continue
+			}
+			app.Status.Phase = platformv1alpha1.AppPhaseHealthy
+			app.Status.ReadyReplicas = 1
+			_ = c.Status().Update(ctx, app)
+			return
+		}
+	}()
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://web.example.com/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 after waking, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestActivatorWakeTimeout(t *testing.T) {
+	a, _ := newActivator(t, upstreamServer(t), idleApp(platformv1alpha1.AppPhaseSleeping, 0))
+	a.WakeTimeout = 200 * time.Millisecond
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://web.example.com/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header")
+	}
+}
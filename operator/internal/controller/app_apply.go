@@ -0,0 +1,218 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// fieldManager owns every field the operator applies to an App's
+	// children.
+	fieldManager = "flowcd-operator"
+
+	// conditionTypeOutOfSync reports whether the last reconcile had to
+	// correct children that were edited outside the App.
+	conditionTypeOutOfSync = "OutOfSync"
+
+	// maxDriftPaths bounds the fields listed in the OutOfSync message.
+	maxDriftPaths = 10
+)
+
+// apply server-side applies obj, which holds exactly the fields the App
+// manages, and replaces it with the object the API server returns. Fields
+// another manager changed since the last apply are forced back and recorded
+// as drift.
+func (r *AppReconciler) apply(ctx context.Context, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return err
+	}
+	var live client.Object
+	u := &unstructured.Unstructured{}
+	if typed, ok := obj.(*unstructured.Unstructured); ok {
+		u = typed.DeepCopy()
+		live = &unstructured.Unstructured{}
+		live.GetObjectKind().SetGroupVersionKind(gvk)
+	} else {
+		if u.Object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			return err
+		}
+		empty, err := r.Scheme.New(gvk)
+		if err != nil {
+			return err
+		}
+		live = empty.(client.Object)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), live); apierrors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		return err
+	}
+
+	u.SetGroupVersionKind(gvk)
+	u.SetResourceVersion("")
+	u.SetManagedFields(nil)
+	// Typed objects carry empty timestamps and status that are not ours to
+	// apply.
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "spec", "template", "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "status")
+
+	if err := r.Apply(ctx, client.ApplyConfigurationFromUnstructured(u),
+		client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return fmt.Errorf("apply %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+	if typed, ok := obj.(*unstructured.Unstructured); ok {
+		u.DeepCopyInto(typed)
+	} else if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return err
+	}
+
+	if live == nil {
+		return nil
+	}
+	paths, err := takenFields(live.GetManagedFields(), obj.GetManagedFields())
+	if err != nil || len(paths) == 0 {
+		return err
+	}
+	driftFrom(ctx).record(fmt.Sprintf("%s %s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName()), paths)
+	return nil
+}
+
+// takenFields returns the fields that other managers owned before an apply
+// and lost to it: the ones edited by hand since the operator last applied
+// them.
+func takenFields(before, after []metav1.ManagedFieldsEntry) ([]string, error) {
+	var paths []string
+	for _, entry := range before {
+		if entry.Manager == fieldManager || entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		owned, err := fieldSet(entry)
+		if err != nil {
+			return nil, err
+		}
+		kept := &fieldpath.Set{}
+		for _, other := range after {
+			if other.Manager == entry.Manager && other.Operation == entry.Operation && other.Subresource == "" && other.FieldsV1 != nil {
+				if kept, err = fieldSet(other); err != nil {
+					return nil, err
+				}
+			}
+		}
+		owned.Difference(kept).Leaves().Iterate(func(p fieldpath.Path) {
+			paths = append(paths, strings.TrimPrefix(p.String(), "."))
+		})
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func fieldSet(entry metav1.ManagedFieldsEntry) (*fieldpath.Set, error) {
+	set := &fieldpath.Set{}
+	if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+		return nil, fmt.Errorf("parse fields of manager %s: %w", entry.Manager, err)
+	}
+	return set, nil
+}
+
+// driftReport collects the drift corrected during one reconcile.
+type driftReport struct {
+	mu      sync.Mutex
+	objects []string
+	paths   []string
+}
+
+type driftReportKey struct{}
+
+// withDriftReport returns a context whose applies record the drift they
+// correct in a fresh report.
+func withDriftReport(ctx context.Context) context.Context {
+	return context.WithValue(ctx, driftReportKey{}, &driftReport{})
+}
+
+// driftFrom returns the context's report; applies outside a reconcile record
+// into a throwaway one.
+func driftFrom(ctx context.Context) *driftReport {
+	if report, ok := ctx.Value(driftReportKey{}).(*driftReport); ok {
+		return report
+	}
+	return &driftReport{}
+}
+
+func (d *driftReport) record(object string, paths []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.objects = append(d.objects, object)
+	for _, p := range paths {
+		d.paths = append(d.paths, object+": "+p)
+	}
+}
+
+// summary describes the corrected drift, or returns "" when there was none.
+func (d *driftReport) summary() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.paths) == 0 {
+		return ""
+	}
+	paths := d.paths
+	more := ""
+	if len(paths) > maxDriftPaths {
+		more = fmt.Sprintf(" and %d more", len(paths)-maxDriftPaths)
+		paths = paths[:maxDriftPaths]
+	}
+	return fmt.Sprintf("Corrected manual changes to %s: %s%s.",
+		strings.Join(d.objects, ", "), strings.Join(paths, "; "), more)
+}
+
+// setSyncCondition records on app.Status whether this reconcile corrected
+// drift, and emits an Event when it did. The caller persists the status.
+func (r *AppReconciler) setSyncCondition(ctx context.Context, app *platformv1alpha1.App) {
+	cond := metav1.Condition{
+		Type:               conditionTypeOutOfSync,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Synced",
+		Message:            "Live resources match the App.",
+		ObservedGeneration: app.Generation,
+	}
+	if msg := driftFrom(ctx).summary(); msg != "" {
+		cond.Status, cond.Reason, cond.Message = metav1.ConditionTrue, "DriftCorrected", msg
+		if r.Recorder != nil {
+			r.Recorder.Eventf(app, nil, corev1.EventTypeWarning, "DriftCorrected", "SelfHeal", "%s", msg)
+		}
+	}
+	meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+// defaultTargetCPUUtilization is used when autoscaling sets no metric at all.
+const defaultTargetCPUUtilization = int32(80)
+
+// autoscalingEnabled reports whether the App's replica count is owned by a
+// HorizontalPodAutoscaler.
+func autoscalingEnabled(app *platformv1alpha1.App) bool {
+	return app.Spec.Autoscaling != nil
+}
+
+// autoscalingBounds returns the App's minimum and maximum replica counts.
+func autoscalingBounds(app *platformv1alpha1.App) (minReplicas, maxReplicas int32) {
+	as := app.Spec.Autoscaling
+	minReplicas = 1
+	if as.MinReplicas != nil {
+		minReplicas = *as.MinReplicas
+	}
+	return minReplicas, max(minReplicas, as.MaxReplicas)
+}
+
+// autoscalerMetrics returns the metrics the App scales on.
+func autoscalerMetrics(as *platformv1alpha1.AppAutoscaling) []autoscalingv2.MetricSpec {
+	resourceMetric := func(name corev1.ResourceName, target int32) autoscalingv2.MetricSpec {
+		return autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: name,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: &target,
+				},
+			},
+		}
+	}
+
+	var metrics []autoscalingv2.MetricSpec
+	if as.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceCPU, *as.TargetCPUUtilizationPercentage))
+	}
+	if as.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceMemory, *as.TargetMemoryUtilizationPercentage))
+	}
+	metrics = append(metrics, as.Metrics...)
+	if len(metrics) == 0 {
+		metrics = append(metrics, resourceMetric(corev1.ResourceCPU, defaultTargetCPUUtilization))
+	}
+	return metrics
+}
+
+// reconcileAutoscaler creates, updates, or deletes the HorizontalPodAutoscaler
+// scaling the App's Deployment. It returns nil when autoscaling is off.
+func (r *AppReconciler) reconcileAutoscaler(ctx context.Context, app *platformv1alpha1.App, namespace string) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	existing := &autoscalingv2.HorizontalPodAutoscaler{}
+	getErr := r.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: namespace}, existing)
+
+	if !autoscalingEnabled(app) {
+		if apierrors.IsNotFound(getErr) {
+			return nil, nil
+		}
+		if getErr != nil {
+			return nil, getErr
+		}
+		return nil, r.Delete(ctx, existing)
+	}
+
+	minReplicas, maxReplicas := autoscalingBounds(app)
+	desired := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      app.Name,
+			Namespace: namespace,
+			Labels:    appLabels(app.Name),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       app.Name,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: maxReplicas,
+			Metrics:     autoscalerMetrics(app.Spec.Autoscaling),
+		},
+	}
+
+	if err := r.setOwner(app, desired); err != nil {
+		return nil, fmt.Errorf("set owner reference on HorizontalPodAutoscaler: %w", err)
+	}
+
+	if getErr != nil && !apierrors.IsNotFound(getErr) {
+		return nil, getErr
+	}
+	if err := r.apply(ctx, desired); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+// containerEnv converts App environment variables to container ones.
+func containerEnv(env []platformv1alpha1.AppEnvVar) []corev1.EnvVar {
+	envVars := make([]corev1.EnvVar, 0, len(env))
+	for _, e := range env {
+		ev := corev1.EnvVar{Name: e.Name}
+		if e.SecretKeyRef != nil {
+			ev.ValueFrom = &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: e.SecretKeyRef.Name},
+					Key:                  e.SecretKeyRef.Key,
+				},
+			}
+		} else {
+			ev.Value = e.Value
+		}
+		envVars = append(envVars, ev)
+	}
+	return envVars
+}
+
+// extraContainers converts sidecars or init containers to pod containers.
+func extraContainers(containers []platformv1alpha1.AppContainer) []corev1.Container {
+	if len(containers) == 0 {
+		return nil
+	}
+	out := make([]corev1.Container, 0, len(containers))
+	for _, c := range containers {
+		ports := make([]corev1.ContainerPort, 0, len(c.Ports))
+		for _, p := range c.Ports {
+			protocol := p.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			ports = append(ports, corev1.ContainerPort{Name: p.Name, ContainerPort: p.ContainerPort, Protocol: protocol})
+		}
+		mounts := make([]corev1.VolumeMount, 0, len(c.VolumeMounts))
+		for _, m := range c.VolumeMounts {
+			mounts = append(mounts, corev1.VolumeMount{Name: m.Name, MountPath: m.MountPath, SubPath: m.SubPath, ReadOnly: m.ReadOnly})
+		}
+		var resources corev1.ResourceRequirements
+		if c.Resources != nil {
+			resources = corev1.ResourceRequirements{Requests: c.Resources.Requests, Limits: c.Resources.Limits}
+		}
+		out = append(out, corev1.Container{
+			Name:         c.Name,
+			Image:        c.Image,
+			Command:      c.Command,
+			Args:         c.Args,
+			Env:          containerEnv(c.Env),
+			Ports:        ports,
+			VolumeMounts: mounts,
+			Resources:    resources,
+		})
+	}
+	return out
+}
+
+// appContainer returns the App's own container in a pod spec, or nil.
+func appContainer(spec *corev1.PodSpec, app *platformv1alpha1.App) *corev1.Container {
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == app.Name {
+			return &spec.Containers[i]
+		}
+	}
+	return nil
+}
@@ -19,8 +19,11 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -29,9 +32,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
@@ -49,6 +55,18 @@ const (
 type AppReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Recorder emits Events about automatic rollbacks. Optional.
+	Recorder events.EventRecorder
+	// ActivatorHost is the DNS name of the activator Service that idle Apps
+	// are routed through. spec.idle is ignored while it is empty.
+	ActivatorHost string
+	// ActivatorPort is the port the activator listens on.
+	ActivatorPort int32
+	// Routing holds the routing defaults for Apps.
+	Routing RoutingDefaults
+	// BaseDomain, when set, gives every App the host
+	// <name>.<namespace>.<BaseDomain> in addition to its custom domains.
+	BaseDomain string
 }
 
 // +kubebuilder:rbac:groups=platform.flowcd.io,resources=apps,verbs=get;list;watch;create;update;patch;delete
@@ -57,11 +75,20 @@ type AppReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile moves the current cluster state toward the desired state declared in App.
 func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
+	// Applies to the App's children report the drift they correct here.
+	ctx = withDriftReport(ctx)
 
 	// 1. Fetch the App instance.
 	app := &platformv1alpha1.App{}
@@ -76,6 +103,12 @@ func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	if !app.DeletionTimestamp.IsZero() {
 		if controllerutil.ContainsFinalizer(app, appFinalizer) {
 			log.Info("Running cleanup for App deletion", "name", app.Name)
+			if err := r.cleanupChildren(ctx, app); err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := r.releaseClaims(ctx, app, targetNamespace(app), nil); err != nil {
+				return ctrl.Result{}, err
+			}
 			// (Future: delete ArgoCD Application, external DNS, etc.)
 			controllerutil.RemoveFinalizer(app, appFinalizer)
 			if err := r.Update(ctx, app); err != nil {
@@ -94,9 +127,11 @@ func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	}
 
 	// 4. Determine the target namespace (needed for both suspend and normal paths).
-	targetNamespace := app.Namespace
-	if app.Spec.Destination != nil && app.Spec.Destination.Namespace != "" {
-		targetNamespace = app.Spec.Destination.Namespace
+	targetNamespace := targetNamespace(app)
+	if app.Status.Namespace != targetNamespace {
+		if err := r.moveNamespace(ctx, app, targetNamespace); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	// 5. Handle suspended apps — scale Deployment to zero.
@@ -111,150 +146,215 @@ func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return r.setPhase(ctx, app, platformv1alpha1.AppPhasePending, "No image configured; waiting for build pipeline.")
 	}
 
-	// 7. Reconcile Deployment.
-	deployment, err := r.reconcileDeployment(ctx, app, targetNamespace)
+	// 7. Put Apps that have gone without traffic for too long to sleep.
+	var idleRemaining time.Duration
+	if r.idleEnabled(app) {
+		idleRemaining = r.idleRemaining(app, time.Now())
+		if idleRemaining == 0 {
+			log.Info("App is idle", "name", app.Name)
+			return r.reconcileSleeping(ctx, app, targetNamespace)
+		}
+	}
+
+	// 8. Hold a new image back until its pre-deploy hooks have succeeded.
+	if result, ok, err := r.reconcilePreDeployHooks(ctx, app, targetNamespace); err != nil || !ok {
+		if err != nil {
+			_ = r.setDegradedCondition(ctx, app, "HookFailed", err.Error())
+		}
+		return result, err
+	}
+
+	// 9. Reconcile the App's volume claims, then its Deployment(s) according
+	// to the rollout strategy.
+	if err := r.reconcileVolumes(ctx, app, targetNamespace); err != nil {
+		_ = r.setDegradedCondition(ctx, app, "VolumeFailed", err.Error())
+		return ctrl.Result{}, err
+	}
+	var deployment *appsv1.Deployment
+	var err error
+	if rolloutStrategy(app) == platformv1alpha1.AppStrategyRollingUpdate {
+		deployment, err = r.reconcileDeployment(ctx, app, targetNamespace)
+	} else {
+		deployment, err = r.reconcileRollout(ctx, app, targetNamespace)
+	}
 	if err != nil {
 		_ = r.setDegradedCondition(ctx, app, "DeploymentFailed", err.Error())
 		return ctrl.Result{}, err
 	}
 
-	// 8. Reconcile Service.
+	// 10. Reconcile Services.
 	if err := r.reconcileService(ctx, app, targetNamespace); err != nil {
 		_ = r.setDegradedCondition(ctx, app, "ServiceFailed", err.Error())
 		return ctrl.Result{}, err
 	}
+	if err := r.reconcileActivatorService(ctx, app, targetNamespace); err != nil {
+		_ = r.setDegradedCondition(ctx, app, "ServiceFailed", err.Error())
+		return ctrl.Result{}, err
+	}
 
-	// 9. Reconcile Ingress for custom domains.
-	if err := r.reconcileIngress(ctx, app, targetNamespace); err != nil {
+	// 11. Reconcile the Ingress or HTTPRoutes for custom domains.
+	if err := r.reconcileRouting(ctx, app, targetNamespace); err != nil {
 		_ = r.setDegradedCondition(ctx, app, "IngressFailed", err.Error())
 		return ctrl.Result{}, err
 	}
 
-	// 10. Sync status from the Deployment.
-	return r.syncStatus(ctx, app, deployment)
+	// 12. Reconcile the HorizontalPodAutoscaler.
+	hpa, err := r.reconcileAutoscaler(ctx, app, targetNamespace)
+	if err != nil {
+		_ = r.setDegradedCondition(ctx, app, "AutoscalerFailed", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	// 13. Roll a failed rollout back when the App opts in.
+	rolledBack, err := r.autoRollback(ctx, app, targetNamespace, deployment)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if rolledBack {
+		// The spec change triggers the next reconcile.
+		return ctrl.Result{}, nil
+	}
+
+	// 14. Run the post-deploy hooks of a rolling update once it is ready.
+	if rolloutStrategy(app) == platformv1alpha1.AppStrategyRollingUpdate {
+		if err := r.reconcilePostDeployHooks(ctx, app, deployment); err != nil {
+			_ = r.setDegradedCondition(ctx, app, "HookFailed", err.Error())
+			return ctrl.Result{}, err
+		}
+	}
+
+	// 15. Sync status from the Deployment, and come back when an idle App
+	// is due to sleep.
+	result, err := r.syncStatus(ctx, app, deployment, hpa)
+	return requeueWithin(result, idleRemaining), err
 }
 
-// reconcileDeployment creates or updates the Deployment owned by this App.
+// reconcileDeployment creates or updates the Deployment owned by this App,
+// removing whatever a previous Canary or BlueGreen rollout left behind.
 func (r *AppReconciler) reconcileDeployment(ctx context.Context, app *platformv1alpha1.App, namespace string) (*appsv1.Deployment, error) {
-	replicas := int32(1)
-	if app.Spec.Replicas != nil {
-		replicas = *app.Spec.Replicas
+	if app.Status.Rollout != nil {
+		if err := r.cleanupRollout(ctx, app, namespace); err != nil {
+			return nil, err
+		}
+	}
+	desired := desiredDeployment(app, namespace, app.Name, app.Spec.Image, appReplicas(app), "")
+	// Old and new pods cannot share a ReadWriteOnce claim across nodes, so
+	// replace them instead of rolling.
+	if singleNodeClaims(app) {
+		desired.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
 	}
+	return r.applyDeployment(ctx, app, desired)
+}
+
+// desiredDeployment builds a Deployment running image for the App. A
+// non-empty track labels the pods so Services can tell rollout versions apart;
+// the selector of the App's own Deployment is never narrowed because
+// Deployment selectors are immutable.
+func desiredDeployment(app *platformv1alpha1.App, namespace, name, image string, replicas int32, track string) *appsv1.Deployment {
 	port := app.Spec.Port
 	if port == 0 {
 		port = 8080
 	}
 
 	labels := appLabels(app.Name)
-
-	// Build container env from spec.
-	envVars := make([]corev1.EnvVar, 0, len(app.Spec.Env))
-	for _, e := range app.Spec.Env {
-		ev := corev1.EnvVar{Name: e.Name}
-		if e.SecretKeyRef != nil {
-			ev.ValueFrom = &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{Name: e.SecretKeyRef.Name},
-					Key:                  e.SecretKeyRef.Key,
-				},
-			}
-		} else {
-			ev.Value = e.Value
+	selector := appLabels(app.Name)
+	podLabels := appLabels(app.Name)
+	if track != "" {
+		podLabels[rolloutTrackLabel] = track
+		if name != app.Name {
+			selector[rolloutTrackLabel] = track
 		}
-		envVars = append(envVars, ev)
 	}
 
-	desired := &appsv1.Deployment{
+	readiness, liveness, startup := appProbes(app)
+
+	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      app.Name,
+			Name:      name,
 			Namespace: namespace,
 			Labels:    labels,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
-			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
 			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
 				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
+					InitContainers: extraContainers(app.Spec.InitContainers),
+					Containers: append([]corev1.Container{
 						{
 							Name:  app.Name,
-							Image: app.Spec.Image,
+							Image: image,
 							Ports: []corev1.ContainerPort{
 								{Name: "http", ContainerPort: port, Protocol: corev1.ProtocolTCP},
 							},
-							Env: envVars,
-							ReadinessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									TCPSocket: &corev1.TCPSocketAction{
-										Port: intstr.FromInt32(port),
-									},
-								},
-								InitialDelaySeconds: 5,
-								PeriodSeconds:       10,
-							},
-							LivenessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									TCPSocket: &corev1.TCPSocketAction{
-										Port: intstr.FromInt32(port),
-									},
-								},
-								InitialDelaySeconds: 15,
-								PeriodSeconds:       20,
-							},
+							Env:            containerEnv(app.Spec.Env),
+							VolumeMounts:   appVolumeMounts(app),
+							Resources:      appResources(app),
+							ReadinessProbe: readiness,
+							LivenessProbe:  liveness,
+							StartupProbe:   startup,
 						},
-					},
+					}, extraContainers(app.Spec.Sidecars)...),
+					Volumes: podVolumes(app),
 				},
 			},
 		},
 	}
+}
 
-	// Only set owner reference when Deployment is in the same namespace.
-	if namespace == app.Namespace {
-		if err := controllerutil.SetControllerReference(app, desired, r.Scheme); err != nil {
-			return nil, fmt.Errorf("set owner reference: %w", err)
-		}
+// applyDeployment server-side applies desired. The replica count of an
+// autoscaled Deployment belongs to its HorizontalPodAutoscaler once the
+// Deployment is running, so the live count is applied back unchanged.
+func (r *AppReconciler) applyDeployment(ctx context.Context, app *platformv1alpha1.App, desired *appsv1.Deployment) (*appsv1.Deployment, error) {
+	if err := r.setOwner(app, desired); err != nil {
+		return nil, fmt.Errorf("set owner reference: %w", err)
 	}
 
-	existing := &appsv1.Deployment{}
-	err := r.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: namespace}, existing)
-	if apierrors.IsNotFound(err) {
-		if err := r.Create(ctx, desired); err != nil {
-			return nil, fmt.Errorf("create Deployment: %w", err)
+	if desired.Name == app.Name && autoscalingEnabled(app) {
+		existing := &appsv1.Deployment{}
+		err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil && existing.Spec.Replicas != nil && *existing.Spec.Replicas > 0 {
+			desired.Spec.Replicas = existing.Spec.Replicas
 		}
-		return desired, nil
 	}
-	if err != nil {
+	if err := r.apply(ctx, desired); err != nil {
 		return nil, err
 	}
-
-	// Patch: update image, replicas, and env.
-	patch := client.MergeFrom(existing.DeepCopy())
-	existing.Spec.Replicas = desired.Spec.Replicas
-	existing.Spec.Template.Spec.Containers[0].Image = desired.Spec.Template.Spec.Containers[0].Image
-	existing.Spec.Template.Spec.Containers[0].Env = desired.Spec.Template.Spec.Containers[0].Env
-	if err := r.Patch(ctx, existing, patch); err != nil {
-		return nil, fmt.Errorf("patch Deployment: %w", err)
-	}
-	return existing, nil
+	return desired, nil
 }
 
-// reconcileService creates or updates a ClusterIP Service for the App.
+// reconcileService creates or updates a ClusterIP Service for the App. Apps
+// with a Canary or BlueGreen strategy only route to the track currently
+// serving production traffic.
 func (r *AppReconciler) reconcileService(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	return r.applyService(ctx, app, namespace, app.Name, r.serviceTrack(app))
+}
+
+// applyService creates or updates the named ClusterIP Service selecting the
+// App's pods, narrowed to track when it is non-empty.
+func (r *AppReconciler) applyService(ctx context.Context, app *platformv1alpha1.App, namespace, name, track string) error {
 	port := app.Spec.Port
 	if port == 0 {
 		port = 8080
 	}
 	labels := appLabels(app.Name)
+	selector := appLabels(app.Name)
+	if track != "" {
+		selector[rolloutTrackLabel] = track
+	}
 
 	desired := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      app.Name,
+			Name:      name,
 			Namespace: namespace,
 			Labels:    labels,
 		},
 		Spec: corev1.ServiceSpec{
-			Selector: labels,
+			Selector: selector,
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "http",
@@ -267,127 +367,137 @@ func (r *AppReconciler) reconcileService(ctx context.Context, app *platformv1alp
 		},
 	}
 
-	if namespace == app.Namespace {
-		if err := controllerutil.SetControllerReference(app, desired, r.Scheme); err != nil {
-			return fmt.Errorf("set owner reference: %w", err)
-		}
+	if err := r.setOwner(app, desired); err != nil {
+		return fmt.Errorf("set owner reference: %w", err)
 	}
-
-	existing := &corev1.Service{}
-	err := r.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: namespace}, existing)
-	if apierrors.IsNotFound(err) {
-		return r.Create(ctx, desired)
-	}
-	if err != nil {
-		return err
-	}
-
-	patch := client.MergeFrom(existing.DeepCopy())
-	existing.Spec.Ports = desired.Spec.Ports
-	existing.Spec.Selector = desired.Spec.Selector
-	return r.Patch(ctx, existing, patch)
+	return r.apply(ctx, desired)
 }
 
-// reconcileIngress creates, updates, or deletes the Ingress for the App's custom domains.
+// reconcileIngress creates, updates, or deletes the Ingress for the App's
+// custom domains and default host.
 func (r *AppReconciler) reconcileIngress(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
-	ingressName := app.Name
-	existing := &networkingv1.Ingress{}
-	getErr := r.Get(ctx, types.NamespacedName{Name: ingressName, Namespace: namespace}, existing)
-
-	// If no domains are configured, delete any existing Ingress.
-	if len(app.Spec.Domains) == 0 {
-		if apierrors.IsNotFound(getErr) {
-			return nil
-		}
-		if getErr != nil {
-			return getErr
-		}
-		return r.Delete(ctx, existing)
+	// If the App has no host, delete any existing Ingress.
+	routes := r.hostRoutes(app)
+	if len(routes) == 0 {
+		return r.deleteIngress(ctx, namespace, app.Name)
 	}
 
-	port := app.Spec.Port
-	if port == 0 {
-		port = 8080
+	// Idle Apps are reached through the activator, which records requests
+	// and wakes the App when it is asleep.
+	rules := ingressRules(routes, app.Name, appPort(app))
+	if r.idleEnabled(app) {
+		rules = ingressRules(routes, activatorServiceName(app), r.ActivatorPort)
 	}
 
-	pathType := networkingv1.PathTypePrefix
-	rules := make([]networkingv1.IngressRule, 0, len(app.Spec.Domains))
-	for _, domain := range app.Spec.Domains {
-		rules = append(rules, networkingv1.IngressRule{
-			Host: domain,
-			IngressRuleValue: networkingv1.IngressRuleValue{
-				HTTP: &networkingv1.HTTPIngressRuleValue{
-					Paths: []networkingv1.HTTPIngressPath{
-						{
-							Path:     "/",
-							PathType: &pathType,
-							Backend: networkingv1.IngressBackend{
-								Service: &networkingv1.IngressServiceBackend{
-									Name: app.Name,
-									Port: networkingv1.ServiceBackendPort{
-										Number: port,
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		})
+	annotations := r.routeAnnotations(app)
+	for k, v := range issuerAnnotations(app) {
+		annotations[k] = v
+	}
+	if appRouting(app).ForceHTTPS {
+		annotations[forceSSLRedirectAnnotation] = "true"
 	}
 
-	labels := appLabels(app.Name)
-	desired := &networkingv1.Ingress{
+	return r.applyIngress(ctx, app, &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      ingressName,
-			Namespace: namespace,
-			Labels:    labels,
-			Annotations: map[string]string{
-				"nginx.ingress.kubernetes.io/proxy-body-size": "0",
-			},
+			Name:        app.Name,
+			Namespace:   namespace,
+			Labels:      appLabels(app.Name),
+			Annotations: annotations,
 		},
 		Spec: networkingv1.IngressSpec{
-			Rules: rules,
+			IngressClassName: r.ingressClassName(app),
+			TLS:              ingressTLS(app),
+			Rules:            rules,
 		},
-	}
+	})
+}
 
-	if namespace == app.Namespace {
-		if err := controllerutil.SetControllerReference(app, desired, r.Scheme); err != nil {
-			return fmt.Errorf("set owner reference on Ingress: %w", err)
-		}
+// applyIngress server-side applies desired. Annotations the App stops
+// setting are dropped along with the rest of its fields.
+func (r *AppReconciler) applyIngress(ctx context.Context, app *platformv1alpha1.App, desired *networkingv1.Ingress) error {
+	if err := r.setOwner(app, desired); err != nil {
+		return fmt.Errorf("set owner reference on Ingress: %w", err)
 	}
+	return r.apply(ctx, desired)
+}
 
-	if apierrors.IsNotFound(getErr) {
-		return r.Create(ctx, desired)
+// deleteIngress deletes the named Ingress if it exists.
+func (r *AppReconciler) deleteIngress(ctx context.Context, namespace, name string) error {
+	ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := r.Delete(ctx, ingress); err != nil && !apierrors.IsNotFound(err) {
+		return err
 	}
-	if getErr != nil {
-		return getErr
+	return nil
+}
+
+// ingressRules routes the paths of every host to port of the named Service.
+func ingressRules(routes []hostRoute, service string, port int32) []networkingv1.IngressRule {
+	pathType := networkingv1.PathTypePrefix
+	backend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: service,
+			Port: networkingv1.ServiceBackendPort{
+				Number: port,
+			},
+		},
 	}
 
-	patch := client.MergeFrom(existing.DeepCopy())
-	existing.Spec.Rules = desired.Spec.Rules
-	return r.Patch(ctx, existing, patch)
+	rules := make([]networkingv1.IngressRule, 0, len(routes))
+	for _, route := range routes {
+		paths := make([]networkingv1.HTTPIngressPath, 0, len(route.Paths))
+		for _, p := range route.Paths {
+			paths = append(paths, networkingv1.HTTPIngressPath{Path: p, PathType: &pathType, Backend: backend})
+		}
+		rules = append(rules, networkingv1.IngressRule{
+			Host: route.Host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{Paths: paths},
+			},
+		})
+	}
+	return rules
 }
 
-// syncStatus reads the Deployment state and reflects it back onto App.Status.
-func (r *AppReconciler) syncStatus(ctx context.Context, app *platformv1alpha1.App, deployment *appsv1.Deployment) (ctrl.Result, error) {
+// syncStatus reads the Deployment and HorizontalPodAutoscaler state and
+// reflects it back onto App.Status.
+func (r *AppReconciler) syncStatus(ctx context.Context, app *platformv1alpha1.App, deployment *appsv1.Deployment, hpa *autoscalingv2.HorizontalPodAutoscaler) (ctrl.Result, error) {
 	patch := client.MergeFrom(app.DeepCopy())
 
+	r.setSyncCondition(ctx, app)
+	app.Status.DesiredReplicas = 0
+	if hpa != nil {
+		app.Status.DesiredReplicas = hpa.Status.DesiredReplicas
+	}
+
 	app.Status.AvailableReplicas = deployment.Status.AvailableReplicas
 	app.Status.ReadyReplicas = deployment.Status.ReadyReplicas
 	app.Status.ImageTag = imageTag(app.Spec.Image)
+	if rolloutStrategy(app) == platformv1alpha1.AppStrategyRollingUpdate {
+		app.Status.Rollout = nil
+	} else if app.Status.Rollout != nil && app.Status.Rollout.StableImage != "" {
+		app.Status.ImageTag = imageTag(app.Status.Rollout.StableImage)
+	}
 
 	// Populate the primary URL: prefer the first custom domain, fall back to
-	// the in-cluster service address.
-	if len(app.Spec.Domains) > 0 {
-		app.Status.URL = "https://" + app.Spec.Domains[0]
+	// the host generated under the base domain.
+	if hosts := r.hosts(app); len(hosts) > 0 {
+		app.Status.URL = "https://" + hosts[0]
 	} else {
 		app.Status.URL = ""
 	}
 
-	desiredReplicas := int32(1)
-	if app.Spec.Replicas != nil {
-		desiredReplicas = *app.Spec.Replicas
+	domains, err := r.domainStatuses(ctx, app, deployment.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	app.Status.Domains = domains
+
+	desiredReplicas := appReplicas(app)
+
+	now := metav1.Now()
+	pending := rolloutPending(app)
+	if pending && app.Status.RolloutStartedAt == nil {
+		app.Status.RolloutStartedAt = &now
 	}
 
 	switch {
@@ -431,8 +541,11 @@ func (r *AppReconciler) syncStatus(ctx context.Context, app *platformv1alpha1.Ap
 			Message:            "All replicas are healthy.",
 			ObservedGeneration: app.Generation,
 		})
-		now := metav1.Now()
 		app.Status.LastDeployedAt = &now
+		if pending && rolloutSettled(app) && postDeployDone(app) {
+			recordRevision(app, now)
+			clearRolledBack(app)
+		}
 
 	case deployment.Status.AvailableReplicas < desiredReplicas:
 		app.Status.Phase = platformv1alpha1.AppPhaseDeploying
@@ -462,15 +575,70 @@ func (r *AppReconciler) syncStatus(ctx context.Context, app *platformv1alpha1.Ap
 		})
 	}
 
+	// A rolling update is not done until its post-deploy hooks succeed.
+	if app.Status.Phase == platformv1alpha1.AppPhaseHealthy && !postDeployDone(app) {
+		cond := meta.FindStatusCondition(app.Status.Conditions, conditionTypePostDeploy)
+		app.Status.Phase = platformv1alpha1.AppPhaseDeploying
+		reason, msg := "PostDeployHookRunning", "Waiting for the post-deploy hooks."
+		if cond != nil {
+			reason, msg = "PostDeployHook"+cond.Reason, cond.Message
+		}
+		if cond != nil && cond.Reason == hookReasonFailed {
+			app.Status.Phase = platformv1alpha1.AppPhaseFailed
+			meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+				Type:               conditionTypeDegraded,
+				Status:             metav1.ConditionTrue,
+				Reason:             reason,
+				Message:            msg,
+				ObservedGeneration: app.Generation,
+			})
+		}
+		meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+			Type:               conditionTypeProgressing,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            msg,
+			ObservedGeneration: app.Generation,
+		})
+	}
+
+	// A Canary or BlueGreen rollout in progress reports its own phase.
+	if phase := rolloutPhase(app); phase != "" && app.Status.Phase != platformv1alpha1.AppPhaseFailed {
+		app.Status.Phase = phase
+		progressing := metav1.ConditionTrue
+		if phase == platformv1alpha1.AppPhaseAborted {
+			progressing = metav1.ConditionFalse
+		}
+		meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+			Type:               conditionTypeProgressing,
+			Status:             progressing,
+			Reason:             string(phase),
+			Message:            app.Status.Rollout.Message,
+			ObservedGeneration: app.Generation,
+		})
+	}
+
 	if err := r.Status().Patch(ctx, app, patch); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// Re-queue while deploying so we pick up replica changes.
-	if app.Status.Phase == platformv1alpha1.AppPhaseDeploying {
-		return ctrl.Result{RequeueAfter: 5_000_000_000}, nil // 5 s
+	// Re-queue while deploying so we pick up replica changes, while a
+	// rollout is running so timed steps advance, and when a certificate is
+	// pending or about to expire.
+	result := ctrl.Result{}
+	if app.Status.Phase == platformv1alpha1.AppPhaseDeploying || rolloutActive(app) {
+		result.RequeueAfter = 5_000_000_000 // 5 s
 	}
-	return ctrl.Result{}, nil
+	return requeueWithin(result, tlsRecheckAfter(app.Status.Domains, now.Time)), nil
+}
+
+// requeueWithin shortens result so the App is reconciled again within d.
+// A zero d leaves result unchanged.
+func requeueWithin(result ctrl.Result, d time.Duration) ctrl.Result {
+	if d > 0 && (result.RequeueAfter == 0 || d < result.RequeueAfter) {
+		result.RequeueAfter = d
+	}
+	return result
 }
 
 // deploymentProgressDeadlineExceeded returns true when the Deployment's
@@ -486,21 +654,12 @@ func deploymentProgressDeadlineExceeded(d *appsv1.Deployment) bool {
 	return false
 }
 
-// reconcileSuspended scales the Deployment to zero and sets the Suspended phase.
+// reconcileSuspended scales the App's Deployments to zero and sets the
+// Suspended phase.
 func (r *AppReconciler) reconcileSuspended(ctx context.Context, app *platformv1alpha1.App, namespace string) (ctrl.Result, error) {
-	existing := &appsv1.Deployment{}
-	err := r.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: namespace}, existing)
-	if err != nil && !apierrors.IsNotFound(err) {
+	if err := r.scaleToZero(ctx, app, namespace); err != nil {
 		return ctrl.Result{}, err
 	}
-	if err == nil && (existing.Spec.Replicas == nil || *existing.Spec.Replicas != 0) {
-		patch := client.MergeFrom(existing.DeepCopy())
-		zero := int32(0)
-		existing.Spec.Replicas = &zero
-		if patchErr := r.Patch(ctx, existing, patch); patchErr != nil {
-			return ctrl.Result{}, patchErr
-		}
-	}
 	return r.setPhase(ctx, app, platformv1alpha1.AppPhaseSuspended, "App is suspended.")
 }
 
@@ -532,6 +691,46 @@ func (r *AppReconciler) setDegradedCondition(ctx context.Context, app *platformv
 	return r.Status().Patch(ctx, app, patch)
 }
 
+// appReplicas returns the App's desired replica count. For autoscaled Apps
+// this is the autoscaler's last desired count within the configured bounds.
+func appReplicas(app *platformv1alpha1.App) int32 {
+	if autoscalingEnabled(app) {
+		minReplicas, maxReplicas := autoscalingBounds(app)
+		return min(max(app.Status.DesiredReplicas, minReplicas), maxReplicas)
+	}
+	if app.Spec.Replicas != nil {
+		return *app.Spec.Replicas
+	}
+	return 1
+}
+
+// targetNamespace returns the namespace the App's workload runs in.
+func targetNamespace(app *platformv1alpha1.App) string {
+	if app.Spec.Destination != nil && app.Spec.Destination.Namespace != "" {
+		return app.Spec.Destination.Namespace
+	}
+	return app.Namespace
+}
+
+// appPort returns the port the app container listens on.
+func appPort(app *platformv1alpha1.App) int32 {
+	if app.Spec.Port == 0 {
+		return 8080
+	}
+	return app.Spec.Port
+}
+
+// appResources returns the app container's resource requirements.
+func appResources(app *platformv1alpha1.App) corev1.ResourceRequirements {
+	if app.Spec.Resources == nil {
+		return corev1.ResourceRequirements{}
+	}
+	return corev1.ResourceRequirements{
+		Requests: app.Spec.Resources.Requests,
+		Limits:   app.Spec.Resources.Limits,
+	}
+}
+
 // appLabels returns a standard label set for all resources owned by an App.
 func appLabels(name string) map[string]string {
 	return map[string]string{
@@ -552,10 +751,26 @@ func imageTag(image string) string {
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *AppReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &platformv1alpha1.App{}, tlsSecretIndex, indexTLSSecret); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&platformv1alpha1.App{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
+		Owns(&networkingv1.Ingress{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&batchv1.Job{}).
+		// Children in other namespaces carry back-reference labels instead
+		// of owner references.
+		Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(appForChild)).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(appForChild)).
+		Watches(&networkingv1.Ingress{}, handler.EnqueueRequestsFromMapFunc(appForChild)).
+		Watches(&autoscalingv2.HorizontalPodAutoscaler{}, handler.EnqueueRequestsFromMapFunc(appForChild)).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(appForChild)).
+		// Only TLS Secret changes matter here; watching metadata keeps Secret
+		// data, including the API's credentials, out of the cache.
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.secretToApps), builder.OnlyMetadata).
 		Named("app").
 		Complete(r)
 }
@@ -18,14 +18,28 @@ package controller
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -179,6 +193,59 @@ var _ = Describe("App Controller", func() {
 			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
 			Expect(app.Status.ImageTag).To(Equal("v1.0.0"))
 		})
+
+		It("should propagate resource requests and limits to the Deployment", func() {
+			Expect(reconcileOnce()).To(Succeed())
+
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Resources = &platformv1alpha1.AppResources{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+			}
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			res := d.Spec.Template.Spec.Containers[0].Resources
+			Expect(res.Requests.Cpu().String()).To(Equal("250m"))
+			Expect(res.Limits.Memory().String()).To(Equal("256Mi"))
+		})
+
+		It("should default to TCP probes and patch HTTP health checks onto the Deployment", func() {
+			Expect(reconcileOnce()).To(Succeed())
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			c := d.Spec.Template.Spec.Containers[0]
+			Expect(c.ReadinessProbe.TCPSocket).NotTo(BeNil())
+			Expect(c.StartupProbe).To(BeNil())
+
+			By("configuring an HTTP health check")
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			failures := int32(6)
+			app.Spec.HealthCheck = &platformv1alpha1.AppHealthCheck{
+				Path:     "/healthz",
+				Headers:  []platformv1alpha1.AppHTTPHeader{{Name: "X-Probe", Value: "1"}},
+				Liveness: &platformv1alpha1.AppProbeSettings{FailureThreshold: &failures},
+				Startup:  &platformv1alpha1.AppProbeSettings{},
+			}
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			c = d.Spec.Template.Spec.Containers[0]
+			Expect(c.ReadinessProbe.HTTPGet).NotTo(BeNil())
+			Expect(c.ReadinessProbe.HTTPGet.Path).To(Equal("/healthz"))
+			Expect(c.ReadinessProbe.HTTPGet.HTTPHeaders).To(HaveLen(1))
+			Expect(c.LivenessProbe.FailureThreshold).To(Equal(int32(6)))
+			Expect(c.LivenessProbe.PeriodSeconds).To(Equal(int32(20)))
+			Expect(c.StartupProbe).NotTo(BeNil())
+			Expect(c.StartupProbe.FailureThreshold).To(Equal(int32(30)))
+		})
 	})
 
 	Context("When domains are specified", func() {
@@ -324,4 +391,979 @@ var _ = Describe("App Controller", func() {
 			Expect(cond.Reason).To(Equal("ProgressDeadlineExceeded"))
 		})
 	})
+
+	Context("When autoscaling is enabled", func() {
+		BeforeEach(func() {
+			By("creating an autoscaled App")
+			app := makeApp("ghcr.io/example/test-app:v1", nil, false)
+			minReplicas := int32(2)
+			cpu := int32(70)
+			app.Spec.Autoscaling = &platformv1alpha1.AppAutoscaling{
+				MinReplicas:                    &minReplicas,
+				MaxReplicas:                    5,
+				TargetCPUUtilizationPercentage: &cpu,
+			}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			if err := k8sClient.Get(ctx, appNSN, hpa); err == nil {
+				_ = k8sClient.Delete(ctx, hpa)
+			}
+			cleanupApp()
+		})
+
+		It("should own an HPA and leave the Deployment's replicas to it", func() {
+			Expect(reconcileOnce()).To(Succeed())
+
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, appNSN, hpa)).To(Succeed())
+			Expect(*hpa.Spec.MinReplicas).To(Equal(int32(2)))
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
+			Expect(hpa.Spec.Metrics).To(HaveLen(1))
+			Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(70)))
+
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(*d.Spec.Replicas).To(Equal(int32(2)))
+
+			By("simulating the HPA scaling the Deployment to 4")
+			hpa.Status.DesiredReplicas = 4
+			hpa.Status.CurrentReplicas = 2
+			Expect(k8sClient.Status().Update(ctx, hpa)).To(Succeed())
+			four := int32(4)
+			d.Spec.Replicas = &four
+			Expect(k8sClient.Update(ctx, d)).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			d.Status.Replicas = 4
+			d.Status.ReadyReplicas = 2
+			d.Status.AvailableReplicas = 2
+			Expect(k8sClient.Status().Update(ctx, d)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(*d.Spec.Replicas).To(Equal(int32(4)))
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.DesiredReplicas).To(Equal(int32(4)))
+			Expect(app.Status.Phase).To(Equal(platformv1alpha1.AppPhaseDeploying))
+
+			By("becoming Healthy once the HPA's desired count is ready")
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			d.Status.ReadyReplicas = 4
+			d.Status.AvailableReplicas = 4
+			Expect(k8sClient.Status().Update(ctx, d)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.Phase).To(Equal(platformv1alpha1.AppPhaseHealthy))
+		})
+	})
+
+	Context("When TLS is configured", func() {
+		secretNSN := types.NamespacedName{Name: appName + "-cert", Namespace: namespace}
+
+		AfterEach(func() {
+			secret := &corev1.Secret{}
+			if err := k8sClient.Get(ctx, secretNSN, secret); err == nil {
+				_ = k8sClient.Delete(ctx, secret)
+			}
+			cleanupApp()
+		})
+
+		selfSignedPEM := func(domain string, notAfter time.Time) []byte {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			tmpl := &x509.Certificate{
+				SerialNumber: big.NewInt(1),
+				Subject:      pkix.Name{CommonName: domain},
+				DNSNames:     []string{domain},
+				NotBefore:    time.Now().Add(-time.Hour),
+				NotAfter:     notAfter,
+			}
+			der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+			Expect(err).NotTo(HaveOccurred())
+			return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		}
+
+		It("should request a certificate from the issuer and report it as pending", func() {
+			app := makeApp("ghcr.io/example/test-app:v1", []string{"test-app.example.com"}, false)
+			app.Spec.TLS = &platformv1alpha1.AppTLS{
+				Issuer: &platformv1alpha1.AppIssuerRef{Name: "letsencrypt", Kind: platformv1alpha1.AppIssuerKindClusterIssuer},
+			}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, appNSN, ingress)).To(Succeed())
+			Expect(ingress.Annotations).To(HaveKeyWithValue("cert-manager.io/cluster-issuer", "letsencrypt"))
+			Expect(ingress.Spec.TLS).To(HaveLen(1))
+			Expect(ingress.Spec.TLS[0].SecretName).To(Equal(appName + "-tls"))
+			Expect(ingress.Spec.TLS[0].Hosts).To(ConsistOf("test-app.example.com"))
+
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.Domains).To(HaveLen(1))
+			Expect(app.Status.Domains[0].SSLStatus).To(Equal(platformv1alpha1.AppSSLStatusPending))
+		})
+
+		It("should report certificates from an existing Secret per domain", func() {
+			expires := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second)
+			Expect(k8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretNSN.Name, Namespace: namespace},
+				Type:       corev1.SecretTypeTLS,
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       selfSignedPEM("test-app.example.com", expires),
+					corev1.TLSPrivateKeyKey: []byte("unused"),
+				},
+			})).To(Succeed())
+
+			app := makeApp("ghcr.io/example/test-app:v1", []string{"test-app.example.com", "www.example.com"}, false)
+			app.Spec.TLS = &platformv1alpha1.AppTLS{SecretName: secretNSN.Name}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, appNSN, ingress)).To(Succeed())
+			Expect(ingress.Annotations).NotTo(HaveKey("cert-manager.io/cluster-issuer"))
+			Expect(ingress.Spec.TLS[0].SecretName).To(Equal(secretNSN.Name))
+
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.Domains).To(HaveLen(2))
+			Expect(app.Status.Domains[0].SSLStatus).To(Equal(platformv1alpha1.AppSSLStatusValid))
+			Expect(app.Status.Domains[0].ExpiresAt.Time).To(BeTemporally("==", expires))
+			Expect(app.Status.Domains[1].SSLStatus).To(Equal(platformv1alpha1.AppSSLStatusFailed))
+		})
+	})
+
+	Context("When routing is configured", func() {
+		redirectNSN := types.NamespacedName{Name: appName + "-redirect-0", Namespace: namespace}
+
+		AfterEach(func() {
+			ingress := &networkingv1.Ingress{}
+			if err := k8sClient.Get(ctx, redirectNSN, ingress); err == nil {
+				_ = k8sClient.Delete(ctx, ingress)
+			}
+			cleanupApp()
+		})
+
+		It("should apply the Ingress class, annotations, paths and redirects", func() {
+			app := makeApp("ghcr.io/example/test-app:v1", []string{"test-app.example.com", "api.example.com"}, false)
+			app.Spec.Routing = &platformv1alpha1.AppRouting{
+				IngressClassName: "internal",
+				Annotations:      map[string]string{"nginx.ingress.kubernetes.io/proxy-read-timeout": "120"},
+				Paths: []platformv1alpha1.AppRoutePath{
+					{Path: "/v1", Domains: []string{"api.example.com"}},
+				},
+				Redirects:  []platformv1alpha1.AppRedirect{{From: "www.test-app.example.com", To: "test-app.example.com"}},
+				ForceHTTPS: true,
+			}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			r := &AppReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				Routing: RoutingDefaults{Annotations: map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "0"}},
+			}
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: appNSN})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("merging the operator's default annotations with the App's")
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, appNSN, ingress)).To(Succeed())
+			Expect(ingress.Spec.IngressClassName).To(HaveValue(Equal("internal")))
+			Expect(ingress.Annotations).To(HaveKeyWithValue("nginx.ingress.kubernetes.io/proxy-body-size", "0"))
+			Expect(ingress.Annotations).To(HaveKeyWithValue("nginx.ingress.kubernetes.io/proxy-read-timeout", "120"))
+			Expect(ingress.Annotations).To(HaveKeyWithValue("nginx.ingress.kubernetes.io/force-ssl-redirect", "true"))
+
+			By("routing each domain only on its own paths")
+			Expect(ingress.Spec.Rules).To(HaveLen(2))
+			Expect(ingress.Spec.Rules[0].Host).To(Equal("test-app.example.com"))
+			Expect(ingress.Spec.Rules[0].HTTP.Paths).To(HaveLen(1))
+			Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Path).To(Equal("/"))
+			Expect(ingress.Spec.Rules[1].Host).To(Equal("api.example.com"))
+			Expect(ingress.Spec.Rules[1].HTTP.Paths).To(HaveLen(1))
+			Expect(ingress.Spec.Rules[1].HTTP.Paths[0].Path).To(Equal("/v1"))
+
+			By("creating a redirect Ingress for the extra host")
+			redirect := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, redirectNSN, redirect)).To(Succeed())
+			Expect(redirect.Annotations).To(HaveKeyWithValue("nginx.ingress.kubernetes.io/permanent-redirect",
+				"https://test-app.example.com$request_uri"))
+			Expect(redirect.Spec.Rules).To(HaveLen(1))
+			Expect(redirect.Spec.Rules[0].Host).To(Equal("www.test-app.example.com"))
+
+			By("pruning the redirect Ingress once the redirect is removed")
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Routing.Redirects = nil
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: appNSN})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, redirectNSN, &networkingv1.Ingress{}))).To(BeTrue())
+		})
+
+		It("should parse Gateway references from the operator flag", func() {
+			gw, err := ParseGatewayRef("infra/public:https,http")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*gw).To(Equal(platformv1alpha1.AppGatewayRef{
+				Name: "public", Namespace: "infra", SectionName: "https", HTTPSectionName: "http",
+			}))
+			_, err = ParseGatewayRef("infra/")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When the operator has a base domain", func() {
+		AfterEach(cleanupApp)
+
+		It("should route the default host and report it as the URL without custom domains", func() {
+			app := makeApp("ghcr.io/example/test-app:v1", nil, false)
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			r := &AppReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BaseDomain: "apps.example.com"}
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: appNSN})
+			Expect(err).NotTo(HaveOccurred())
+
+			defaultHost := appName + "." + namespace + ".apps.example.com"
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, appNSN, ingress)).To(Succeed())
+			Expect(ingress.Spec.Rules).To(HaveLen(1))
+			Expect(ingress.Spec.Rules[0].Host).To(Equal(defaultHost))
+
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.URL).To(Equal("https://" + defaultHost))
+			Expect(app.Status.Domains).To(BeEmpty())
+
+			By("layering custom domains on top of the default host")
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Domains = []string{"test-app.example.com"}
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: appNSN})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, appNSN, ingress)).To(Succeed())
+			Expect(ingress.Spec.Rules).To(HaveLen(2))
+			Expect(ingress.Spec.Rules[0].Host).To(Equal("test-app.example.com"))
+			Expect(ingress.Spec.Rules[1].Host).To(Equal(defaultHost))
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.URL).To(Equal("https://test-app.example.com"))
+		})
+	})
+
+	Context("When the App has sidecars and hooks", func() {
+		hookJobs := func(stage string) []batchv1.Job {
+			jobs := &batchv1.JobList{}
+			Expect(k8sClient.List(ctx, jobs, client.InNamespace(namespace),
+				client.MatchingLabels{"app.kubernetes.io/part-of": appName, "platform.flowcd.io/hook": stage})).To(Succeed())
+			return jobs.Items
+		}
+
+		finishJob := func(job *batchv1.Job, succeeded bool) {
+			start := metav1.Now()
+			job.Status.StartTime = &start
+			if succeeded {
+				job.Status.CompletionTime = &start
+				job.Status.Succeeded = 1
+				job.Status.Conditions = []batchv1.JobCondition{
+					{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue},
+					{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+				}
+			} else {
+				job.Status.Failed = 1
+				job.Status.Conditions = []batchv1.JobCondition{
+					{Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded",
+						Message: "Job has reached the specified backoff limit"},
+				}
+			}
+			Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+		}
+
+		AfterEach(func() {
+			for _, stage := range []string{"pre", "post"} {
+				for _, job := range hookJobs(stage) {
+					_ = k8sClient.Delete(ctx, &job)
+				}
+			}
+			cleanupApp()
+		})
+
+		It("should run sidecars, init containers and shared volumes in the App's pods", func() {
+			app := makeApp("ghcr.io/example/test-app:v1", nil, false)
+			app.Spec.Volumes = []platformv1alpha1.AppVolume{{Name: "shared", MountPath: "/var/shared"}}
+			app.Spec.InitContainers = []platformv1alpha1.AppContainer{{
+				Name:         "seed",
+				Image:        "busybox:1.36",
+				Command:      []string{"sh", "-c", "echo ready > /seed/status"},
+				VolumeMounts: []platformv1alpha1.AppVolumeMount{{Name: "shared", MountPath: "/seed"}},
+			}}
+			app.Spec.Sidecars = []platformv1alpha1.AppContainer{{
+				Name:         "proxy",
+				Image:        "envoyproxy/envoy:v1.31",
+				Ports:        []platformv1alpha1.AppContainerPort{{Name: "admin", ContainerPort: 9901}},
+				Env:          []platformv1alpha1.AppEnvVar{{Name: "LOG_LEVEL", Value: "info"}},
+				VolumeMounts: []platformv1alpha1.AppVolumeMount{{Name: "shared", MountPath: "/shared", ReadOnly: true}},
+			}}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			pod := d.Spec.Template.Spec
+			Expect(pod.Volumes).To(HaveLen(1))
+			Expect(pod.Volumes[0].EmptyDir).NotTo(BeNil())
+			Expect(pod.InitContainers).To(HaveLen(1))
+			Expect(pod.InitContainers[0].VolumeMounts[0].MountPath).To(Equal("/seed"))
+			Expect(pod.Containers).To(HaveLen(2))
+			Expect(pod.Containers[0].Name).To(Equal(appName))
+			Expect(pod.Containers[0].VolumeMounts[0].MountPath).To(Equal("/var/shared"))
+			Expect(pod.Containers[1].Name).To(Equal("proxy"))
+			Expect(pod.Containers[1].Ports[0].ContainerPort).To(Equal(int32(9901)))
+
+			By("updating the sidecar image without touching the app container")
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Sidecars[0].Image = "envoyproxy/envoy:v1.32"
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal("ghcr.io/example/test-app:v1"))
+			Expect(d.Spec.Template.Spec.Containers[1].Image).To(Equal("envoyproxy/envoy:v1.32"))
+		})
+
+		It("should deploy a new image only after its pre-deploy hooks succeed", func() {
+			app := makeApp("ghcr.io/example/test-app:v1", nil, false)
+			app.Spec.Hooks = &platformv1alpha1.AppHooks{
+				PreDeploy: []platformv1alpha1.AppHook{{Name: "migrate", Command: []string{"./migrate", "up"}}},
+			}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			By("running the hook with the new image before any Deployment exists")
+			jobs := hookJobs("pre")
+			Expect(jobs).To(HaveLen(1))
+			Expect(jobs[0].Spec.Template.Spec.Containers[0].Image).To(Equal("ghcr.io/example/test-app:v1"))
+			Expect(jobs[0].Spec.Template.Labels).NotTo(HaveKey("app.kubernetes.io/name"))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, appNSN, &appsv1.Deployment{}))).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.Phase).To(Equal(platformv1alpha1.AppPhaseDeploying))
+			cond := meta.FindStatusCondition(app.Status.Conditions, "PreDeploySucceeded")
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal("Running"))
+
+			By("deploying once the hook succeeds")
+			finishJob(&jobs[0], true)
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, &appsv1.Deployment{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(app.Status.Conditions, "PreDeploySucceeded")).To(BeTrue())
+
+			By("keeping the running image when the hook fails for the next one")
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Image = "ghcr.io/example/test-app:v2"
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			jobs = hookJobs("pre")
+			Expect(jobs).To(HaveLen(1))
+			Expect(jobs[0].Spec.Template.Spec.Containers[0].Image).To(Equal("ghcr.io/example/test-app:v2"))
+			finishJob(&jobs[0], false)
+			Expect(reconcileOnce()).To(Succeed())
+
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal("ghcr.io/example/test-app:v1"))
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.Phase).To(Equal(platformv1alpha1.AppPhaseFailed))
+			cond = meta.FindStatusCondition(app.Status.Conditions, "PreDeploySucceeded")
+			Expect(cond.Reason).To(Equal("Failed"))
+			Expect(cond.Message).To(ContainSubstring("migrate"))
+		})
+	})
+
+	Context("When the App has volumes", func() {
+		claims := func() []corev1.PersistentVolumeClaim {
+			list := &corev1.PersistentVolumeClaimList{}
+			Expect(k8sClient.List(ctx, list, client.InNamespace(namespace),
+				client.MatchingLabels{"app.kubernetes.io/part-of": appName})).To(Succeed())
+			return list.Items
+		}
+
+		// released reports whether a claim is gone or being deleted; envtest
+		// keeps deleted claims around because of their protection finalizer.
+		released := func(name string) bool {
+			claim := &corev1.PersistentVolumeClaim{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, claim)
+			if errors.IsNotFound(err) {
+				return true
+			}
+			Expect(err).NotTo(HaveOccurred())
+			return !claim.DeletionTimestamp.IsZero()
+		}
+
+		AfterEach(func() {
+			cleanupApp()
+			for _, claim := range claims() {
+				patch := client.MergeFrom(claim.DeepCopy())
+				claim.Finalizers = nil
+				_ = k8sClient.Patch(ctx, &claim, patch)
+				_ = k8sClient.Delete(ctx, &claim)
+			}
+		})
+
+		It("should create claims and mount ConfigMaps and Secrets as files", func() {
+			app := makeApp("ghcr.io/example/test-app:v1", nil, false)
+			app.Spec.Volumes = []platformv1alpha1.AppVolume{
+				{Name: "data", MountPath: "/var/lib/data", PersistentVolumeClaim: &platformv1alpha1.AppPVCVolume{
+					Size: resource.MustParse("1Gi"),
+				}},
+				{Name: "config", MountPath: "/etc/app", ConfigMap: &platformv1alpha1.AppConfigMapVolume{
+					Name:  "app-config",
+					Items: []platformv1alpha1.AppKeyToPath{{Key: "app.yaml", Path: "app.yaml"}},
+				}},
+				{Name: "tls", MountPath: "/etc/tls", Secret: &platformv1alpha1.AppSecretVolume{Name: "app-tls", Optional: true}},
+				{Name: "scratch", MountPath: "/tmp"},
+			}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			claim := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: appName + "-data", Namespace: namespace}, claim)).To(Succeed())
+			Expect(claim.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+			Expect(claim.Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+			Expect(claim.Annotations).To(HaveKeyWithValue("platform.flowcd.io/reclaim-policy", "Retain"))
+			Expect(claim.OwnerReferences).To(BeEmpty())
+
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(d.Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+			pod := d.Spec.Template.Spec
+			Expect(pod.Volumes).To(HaveLen(4))
+			Expect(pod.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(appName + "-data"))
+			Expect(pod.Volumes[1].ConfigMap.Name).To(Equal("app-config"))
+			Expect(pod.Volumes[1].ConfigMap.Items).To(HaveLen(1))
+			Expect(pod.Volumes[2].Secret.SecretName).To(Equal("app-tls"))
+			Expect(*pod.Volumes[2].Secret.Optional).To(BeTrue())
+			Expect(pod.Volumes[3].EmptyDir).NotTo(BeNil())
+			mounts := pod.Containers[0].VolumeMounts
+			Expect(mounts).To(HaveLen(4))
+			Expect(mounts[0].ReadOnly).To(BeFalse())
+			Expect(mounts[1].ReadOnly).To(BeTrue())
+			Expect(mounts[2].ReadOnly).To(BeTrue())
+
+			By("growing the claim")
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Volumes[0].PersistentVolumeClaim.Size = resource.MustParse("5Gi")
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: appName + "-data", Namespace: namespace}, claim)).To(Succeed())
+			Expect(claim.Spec.Resources.Requests.Storage().String()).To(Equal("5Gi"))
+		})
+
+		It("should delete or retain claims according to their reclaim policy", func() {
+			app := makeApp("ghcr.io/example/test-app:v1", nil, false)
+			app.Spec.Volumes = []platformv1alpha1.AppVolume{
+				{Name: "cache", MountPath: "/cache", PersistentVolumeClaim: &platformv1alpha1.AppPVCVolume{
+					Size:          resource.MustParse("1Gi"),
+					AccessModes:   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+					ReclaimPolicy: platformv1alpha1.AppVolumeDelete,
+				}},
+				{Name: "data", MountPath: "/data", PersistentVolumeClaim: &platformv1alpha1.AppPVCVolume{
+					Size: resource.MustParse("1Gi"),
+				}},
+				{Name: "tmp", MountPath: "/var/tmp", PersistentVolumeClaim: &platformv1alpha1.AppPVCVolume{
+					Size:          resource.MustParse("1Gi"),
+					ReclaimPolicy: platformv1alpha1.AppVolumeDelete,
+				}},
+			}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(claims()).To(HaveLen(3))
+
+			By("removing a volume from the spec")
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Volumes = app.Spec.Volumes[:2]
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(released(appName + "-tmp")).To(BeTrue())
+			Expect(released(appName + "-cache")).To(BeFalse())
+
+			By("deleting the App")
+			Expect(k8sClient.Delete(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, appNSN, app))).To(BeTrue())
+			Expect(released(appName + "-cache")).To(BeTrue())
+			Expect(released(appName + "-data")).To(BeFalse())
+		})
+	})
+
+	Context("When the App deploys to another namespace", func() {
+		const target = "apps-target"
+		targetNSN := types.NamespacedName{Name: appName, Namespace: target}
+
+		BeforeEach(func() {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: target}}
+			if err := k8sClient.Create(ctx, ns); err != nil {
+				Expect(errors.IsAlreadyExists(err)).To(BeTrue())
+			}
+		})
+
+		AfterEach(func() {
+			cleanupApp()
+			_ = k8sClient.Delete(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: appName, Namespace: target}})
+			_ = k8sClient.Delete(ctx, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: appName, Namespace: target}})
+			_ = k8sClient.Delete(ctx, &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: appName, Namespace: target}})
+		})
+
+		It("should label its children with a back-reference and delete them with the App", func() {
+			app := makeApp("ghcr.io/example/test-app:v1", []string{"test-app.example.com"}, false)
+			app.Spec.Destination = &platformv1alpha1.AppDestination{Namespace: target}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			children := []client.Object{&appsv1.Deployment{}, &corev1.Service{}, &networkingv1.Ingress{}}
+			for _, child := range children {
+				Expect(k8sClient.Get(ctx, targetNSN, child)).To(Succeed())
+				Expect(child.GetOwnerReferences()).To(BeEmpty())
+				Expect(child.GetLabels()).To(HaveKeyWithValue("platform.flowcd.io/owner-name-hash", ownerNameHash(appName)))
+				Expect(child.GetAnnotations()).To(HaveKeyWithValue("platform.flowcd.io/owner-name", appName))
+				Expect(child.GetLabels()).To(HaveKeyWithValue("platform.flowcd.io/owner-namespace", namespace))
+				Expect(appForChild(ctx, child)).To(ConsistOf(reconcile.Request{NamespacedName: appNSN}))
+			}
+
+			By("deleting the App")
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, appNSN, app))).To(BeTrue())
+			for _, child := range children {
+				err := k8sClient.Get(ctx, targetNSN, child)
+				Expect(errors.IsNotFound(err) || !child.GetDeletionTimestamp().IsZero()).To(BeTrue())
+			}
+		})
+
+		It("should remove its children from the old namespace when the destination changes", func() {
+			app := makeApp("ghcr.io/example/test-app:v1", []string{"test-app.example.com"}, false)
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, &appsv1.Deployment{})).To(Succeed())
+
+			By("moving the App to another namespace")
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Destination = &platformv1alpha1.AppDestination{Namespace: target}
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(k8sClient.Get(ctx, targetNSN, &appsv1.Deployment{})).To(Succeed())
+			for _, child := range []client.Object{&appsv1.Deployment{}, &corev1.Service{}, &networkingv1.Ingress{}} {
+				err := k8sClient.Get(ctx, appNSN, child)
+				Expect(errors.IsNotFound(err) || !child.GetDeletionTimestamp().IsZero()).To(BeTrue())
+			}
+
+			By("moving it back")
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch = client.MergeFrom(app.DeepCopy())
+			app.Spec.Destination = nil
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, &appsv1.Deployment{})).To(Succeed())
+			err := k8sClient.Get(ctx, targetNSN, &appsv1.Deployment{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("When a child is edited by hand", func() {
+		AfterEach(cleanupApp)
+
+		It("should restore the applied fields and report the corrected drift", func() {
+			Expect(k8sClient.Create(ctx, makeApp("ghcr.io/example/test-app:v1", []string{"test-app.example.com"}, false))).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			By("editing the Deployment, Service and Ingress outside the App")
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			d.Spec.Template.Spec.Containers[0].Image = "ghcr.io/example/test-app:hotfix"
+			Expect(k8sClient.Update(ctx, d)).To(Succeed())
+			svc := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, appNSN, svc)).To(Succeed())
+			svc.Spec.Ports[0].TargetPort = intstr.FromInt32(9090)
+			Expect(k8sClient.Update(ctx, svc)).To(Succeed())
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, appNSN, ingress)).To(Succeed())
+			ingress.Spec.Rules[0].Host = "other.example.com"
+			Expect(k8sClient.Update(ctx, ingress)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal("ghcr.io/example/test-app:v1"))
+			Expect(k8sClient.Get(ctx, appNSN, svc)).To(Succeed())
+			Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(8080))
+			Expect(k8sClient.Get(ctx, appNSN, ingress)).To(Succeed())
+			Expect(ingress.Spec.Rules[0].Host).To(Equal("test-app.example.com"))
+
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			cond := meta.FindStatusCondition(app.Status.Conditions, "OutOfSync")
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal("DriftCorrected"))
+			Expect(cond.Message).To(ContainSubstring("Deployment default/test-app"))
+			Expect(cond.Message).To(ContainSubstring(".image"))
+			Expect(cond.Message).To(ContainSubstring("Service default/test-app"))
+			Expect(cond.Message).To(ContainSubstring("targetPort"))
+			Expect(cond.Message).To(ContainSubstring("Ingress default/test-app"))
+
+			By("reporting the App in sync once nothing else changed")
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(app.Status.Conditions, "OutOfSync")).To(BeTrue())
+		})
+	})
+
+	Context("When the App has an idle policy", func() {
+		activatorNSN := types.NamespacedName{Name: appName + "-activator", Namespace: namespace}
+
+		BeforeEach(func() {
+			By("creating an App that sleeps after a minute without traffic")
+			app := makeApp("ghcr.io/example/test-app:v1", []string{"test-app.example.com"}, false)
+			app.Spec.Idle = &platformv1alpha1.AppIdlePolicy{AfterMinutes: 1}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			svc := &corev1.Service{}
+			if err := k8sClient.Get(ctx, activatorNSN, svc); err == nil {
+				_ = k8sClient.Delete(ctx, svc)
+			}
+			cleanupApp()
+		})
+
+		It("should route through the activator, sleep when idle and wake on a request", func() {
+			r := &AppReconciler{
+				Client:        k8sClient,
+				Scheme:        k8sClient.Scheme(),
+				ActivatorHost: "activator.flowcd-system.svc.cluster.local",
+				ActivatorPort: 8082,
+			}
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: appNSN})
+			Expect(err).NotTo(HaveOccurred())
+
+			svc := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, activatorNSN, svc)).To(Succeed())
+			Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeExternalName))
+			Expect(svc.Spec.ExternalName).To(Equal("activator.flowcd-system.svc.cluster.local"))
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, appNSN, ingress)).To(Succeed())
+			backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+			Expect(backend.Name).To(Equal(activatorNSN.Name))
+			Expect(backend.Port.Number).To(Equal(int32(8082)))
+
+			By("becoming idle once the policy's period has passed")
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(r.idleRemaining(app, time.Now())).To(BeNumerically(">", 0))
+			Expect(r.idleRemaining(app, time.Now().Add(2*time.Minute))).To(BeZero())
+
+			By("scaling to zero while keeping the Service and Ingress")
+			_, err = r.reconcileSleeping(ctx, app, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(*d.Spec.Replicas).To(Equal(int32(0)))
+			Expect(k8sClient.Get(ctx, appNSN, &corev1.Service{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, &networkingv1.Ingress{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.Phase).To(Equal(platformv1alpha1.AppPhaseSleeping))
+
+			By("waking up when the activator records a request")
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Annotations = map[string]string{LastRequestAnnotation: time.Now().UTC().Format(time.RFC3339)}
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: appNSN})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(*d.Spec.Replicas).To(Equal(int32(1)))
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.Phase).To(Equal(platformv1alpha1.AppPhaseDeploying))
+		})
+	})
+
+	Context("When automatic rollback is enabled", func() {
+		BeforeEach(func() {
+			By("creating an App that opts into automatic rollback")
+			app := makeApp("ghcr.io/example/test-app:v1", nil, false)
+			app.Spec.Rollback = &platformv1alpha1.AppRollbackPolicy{Auto: true}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+		})
+
+		AfterEach(cleanupApp)
+
+		It("should restore the last healthy image when the rollout exceeds its deadline", func() {
+			recorder := events.NewFakeRecorder(10)
+			r := &AppReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Recorder: recorder}
+			reconcileWith := func() {
+				_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: appNSN})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("rolling out v1 successfully")
+			reconcileWith()
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			d.Status.Replicas = 1
+			d.Status.ReadyReplicas = 1
+			d.Status.AvailableReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, d)).To(Succeed())
+			reconcileWith()
+
+			By("rolling out a v2 that never becomes ready")
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Image = "ghcr.io/example/test-app:v2"
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			reconcileWith()
+
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			d.Status.ReadyReplicas = 0
+			d.Status.Conditions = []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: "False", Reason: "ProgressDeadlineExceeded"},
+			}
+			Expect(k8sClient.Status().Update(ctx, d)).To(Succeed())
+			reconcileWith()
+
+			By("verifying the App was rolled back")
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Spec.Image).To(Equal("ghcr.io/example/test-app:v1"))
+			Expect(app.Annotations).To(HaveKeyWithValue(triggeredByAnnotation, autoRollbackActor))
+			cond := meta.FindStatusCondition(app.Status.Conditions, conditionTypeRolledBack)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal("ProgressDeadlineExceeded"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("AutoRollback")))
+		})
+	})
+
+	Context("When rollouts become healthy", func() {
+		BeforeEach(func() {
+			By("creating an App with an image")
+			app := makeApp("ghcr.io/example/test-app:v1", nil, false)
+			err := k8sClient.Get(ctx, appNSN, &platformv1alpha1.App{})
+			if errors.IsNotFound(err) {
+				Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			}
+		})
+
+		AfterEach(cleanupApp)
+
+		markReady := func() {
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			d.Status.Replicas = 1
+			d.Status.ReadyReplicas = 1
+			d.Status.AvailableReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, d)).To(Succeed())
+		}
+
+		rollout := func(image string, annotations map[string]string) {
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			app.Spec.Image = image
+			if app.Annotations == nil {
+				app.Annotations = map[string]string{}
+			}
+			for k, v := range annotations {
+				app.Annotations[k] = v
+			}
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+			markReady()
+			Expect(reconcileOnce()).To(Succeed())
+		}
+
+		It("should record each healthy rollout in status.history", func() {
+			Expect(reconcileOnce()).To(Succeed())
+			markReady()
+			Expect(reconcileOnce()).To(Succeed())
+
+			rollout("ghcr.io/example/test-app:v2", map[string]string{
+				commitSHAAnnotation:   "abc123",
+				triggeredByAnnotation: "alice",
+			})
+
+			By("reconciling again without changes")
+			Expect(reconcileOnce()).To(Succeed())
+
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.History).To(HaveLen(2))
+			Expect(app.Status.CurrentRevision).To(Equal(int64(2)))
+			Expect(app.Status.RolloutStartedAt).To(BeNil())
+			latest := app.Status.History[1]
+			Expect(latest.Image).To(Equal("ghcr.io/example/test-app:v2"))
+			Expect(latest.CommitSHA).To(Equal("abc123"))
+			Expect(latest.DeployedBy).To(Equal("alice"))
+			Expect(latest.Status).To(Equal(platformv1alpha1.AppRevisionSucceeded))
+		})
+
+		It("should mark the replaced revision as rolled back", func() {
+			Expect(reconcileOnce()).To(Succeed())
+			markReady()
+			Expect(reconcileOnce()).To(Succeed())
+			rollout("ghcr.io/example/test-app:v2", nil)
+
+			By("rolling back to revision 1")
+			rollout("ghcr.io/example/test-app:v1", map[string]string{rollbackToAnnotation: "1"})
+
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Status.History).To(HaveLen(3))
+			Expect(app.Status.History[1].Status).To(Equal(platformv1alpha1.AppRevisionRolledBack))
+			Expect(app.Status.History[2].Image).To(Equal("ghcr.io/example/test-app:v1"))
+			Expect(app.Status.CurrentRevision).To(Equal(int64(3)))
+		})
+	})
+
+	Context("When the App uses a progressive rollout strategy", func() {
+		canaryNSN := types.NamespacedName{Name: appName + "-canary", Namespace: namespace}
+		previewNSN := types.NamespacedName{Name: appName + "-preview", Namespace: namespace}
+
+		AfterEach(func() {
+			for _, nsn := range []types.NamespacedName{canaryNSN, previewNSN} {
+				d := &appsv1.Deployment{}
+				if err := k8sClient.Get(ctx, nsn, d); err == nil {
+					_ = k8sClient.Delete(ctx, d)
+				}
+			}
+			cleanupApp()
+		})
+
+		createApp := func(strategy *platformv1alpha1.AppStrategy) {
+			app := makeApp("ghcr.io/example/test-app:v1", []string{"test-app.example.com"}, false)
+			app.Spec.Strategy = strategy
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+		}
+
+		markReady := func(nsn types.NamespacedName) {
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, nsn, d)).To(Succeed())
+			d.Status.ObservedGeneration = d.Generation
+			d.Status.Replicas = *d.Spec.Replicas
+			d.Status.UpdatedReplicas = *d.Spec.Replicas
+			d.Status.ReadyReplicas = *d.Spec.Replicas
+			d.Status.AvailableReplicas = *d.Spec.Replicas
+			Expect(k8sClient.Status().Update(ctx, d)).To(Succeed())
+		}
+
+		patchApp := func(mutate func(*platformv1alpha1.App)) {
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			patch := client.MergeFrom(app.DeepCopy())
+			mutate(app)
+			Expect(k8sClient.Patch(ctx, app, patch)).To(Succeed())
+		}
+
+		getApp := func() *platformv1alpha1.App {
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			return app
+		}
+
+		deployFirstImage := func() {
+			Expect(reconcileOnce()).To(Succeed())
+			markReady(appNSN)
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(getApp().Status.Phase).To(Equal(platformv1alpha1.AppPhaseHealthy))
+		}
+
+		It("should shift traffic to a canary step by step and promote it", func() {
+			noPause := int32(0)
+			createApp(&platformv1alpha1.AppStrategy{
+				Type: platformv1alpha1.AppStrategyCanary,
+				Canary: &platformv1alpha1.CanaryStrategy{Steps: []platformv1alpha1.CanaryStep{
+					{Weight: 20},
+					{Weight: 50, PauseSeconds: &noPause},
+				}},
+			})
+			deployFirstImage()
+
+			By("changing the image")
+			patchApp(func(a *platformv1alpha1.App) { a.Spec.Image = "ghcr.io/example/test-app:v2" })
+			Expect(reconcileOnce()).To(Succeed())
+
+			d := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal("ghcr.io/example/test-app:v1"))
+			Expect(k8sClient.Get(ctx, canaryNSN, d)).To(Succeed())
+			Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal("ghcr.io/example/test-app:v2"))
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, canaryNSN, ingress)).To(Succeed())
+			Expect(ingress.Annotations).To(HaveKeyWithValue("nginx.ingress.kubernetes.io/canary-weight", "20"))
+			Expect(getApp().Status.Phase).To(Equal(platformv1alpha1.AppPhaseCanary))
+
+			By("pausing at the first step until promoted")
+			markReady(canaryNSN)
+			Expect(reconcileOnce()).To(Succeed())
+			app := getApp()
+			Expect(app.Status.Phase).To(Equal(platformv1alpha1.AppPhasePaused))
+			Expect(app.Status.Rollout.CurrentStep).To(Equal(int32(0)))
+
+			patchApp(func(a *platformv1alpha1.App) {
+				a.Annotations = map[string]string{promoteRequestedAnnotation: "2026-01-01T00:00:00Z"}
+			})
+			Expect(reconcileOnce()).To(Succeed())
+			app = getApp()
+			Expect(app.Status.Rollout.CurrentStep).To(Equal(int32(1)))
+			Expect(app.Status.Rollout.Weight).To(Equal(int32(50)))
+
+			By("passing the timed last step and promoting")
+			Expect(reconcileOnce()).To(Succeed())
+			markReady(canaryNSN)
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(getApp().Status.Rollout.Promoting).To(BeTrue())
+
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(k8sClient.Get(ctx, appNSN, d)).To(Succeed())
+			Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal("ghcr.io/example/test-app:v2"))
+			markReady(appNSN)
+			Expect(reconcileOnce()).To(Succeed())
+
+			app = getApp()
+			Expect(app.Status.Phase).To(Equal(platformv1alpha1.AppPhaseHealthy))
+			Expect(app.Status.Rollout.StableImage).To(Equal("ghcr.io/example/test-app:v2"))
+			Expect(app.Status.History).To(HaveLen(2))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, canaryNSN, &appsv1.Deployment{}))).To(BeTrue())
+		})
+
+		It("should keep the stable version when a blue/green rollout is aborted", func() {
+			createApp(&platformv1alpha1.AppStrategy{Type: platformv1alpha1.AppStrategyBlueGreen})
+			deployFirstImage()
+
+			patchApp(func(a *platformv1alpha1.App) { a.Spec.Image = "ghcr.io/example/test-app:v2" })
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(getApp().Status.Phase).To(Equal(platformv1alpha1.AppPhasePreview))
+			markReady(previewNSN)
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(getApp().Status.Phase).To(Equal(platformv1alpha1.AppPhasePaused))
+
+			svc := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, appNSN, svc)).To(Succeed())
+			Expect(svc.Spec.Selector).To(HaveKeyWithValue(rolloutTrackLabel, trackStable))
+
+			By("aborting the rollout")
+			patchApp(func(a *platformv1alpha1.App) {
+				a.Annotations = map[string]string{abortRequestedAnnotation: "2026-01-01T00:00:00Z"}
+			})
+			Expect(reconcileOnce()).To(Succeed())
+
+			app := getApp()
+			Expect(app.Status.Phase).To(Equal(platformv1alpha1.AppPhaseAborted))
+			Expect(app.Status.Rollout.AbortedImage).To(Equal("ghcr.io/example/test-app:v2"))
+			Expect(app.Status.History).To(HaveLen(1))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, previewNSN, &appsv1.Deployment{}))).To(BeTrue())
+		})
+	})
 })
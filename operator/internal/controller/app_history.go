@@ -0,0 +1,125 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// commitSHAAnnotation records the Git commit the App's current image was
+	// built from.
+	commitSHAAnnotation = "platform.flowcd.io/commit-sha"
+	// triggeredByAnnotation records who or what last changed the App's image.
+	triggeredByAnnotation = "platform.flowcd.io/triggered-by"
+	// rollbackToAnnotation is set by the API to the revision number being
+	// restored, so the replaced revision can be marked as rolled back.
+	rollbackToAnnotation = "platform.flowcd.io/rollback-to"
+
+	defaultRevisionHistoryLimit = 10
+)
+
+// envHash returns a short, stable digest of an App's environment.
+func envHash(env []platformv1alpha1.AppEnvVar) string {
+	if len(env) == 0 {
+		return ""
+	}
+	raw, _ := json.Marshal(env)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:8])
+}
+
+// latestRevision returns the most recently recorded revision, if any.
+func latestRevision(app *platformv1alpha1.App) *platformv1alpha1.AppRevision {
+	if len(app.Status.History) == 0 {
+		return nil
+	}
+	return &app.Status.History[len(app.Status.History)-1]
+}
+
+// findRevision returns the recorded revision with the given number, if any.
+func findRevision(app *platformv1alpha1.App, revision int64) *platformv1alpha1.AppRevision {
+	for i := range app.Status.History {
+		if app.Status.History[i].Revision == revision {
+			return &app.Status.History[i]
+		}
+	}
+	return nil
+}
+
+// rolloutPending reports whether the App's image or environment differ from
+// the latest recorded revision. Replica-only changes are not rollouts.
+func rolloutPending(app *platformv1alpha1.App) bool {
+	latest := latestRevision(app)
+	return latest == nil || latest.Image != app.Spec.Image || latest.EnvHash != envHash(app.Spec.Env)
+}
+
+// recordRevision appends the App's current spec to status.history as a new
+// revision, marks the revision it replaced as rolled back when the rollout
+// was a rollback, and trims the history to the configured limit.
+func recordRevision(app *platformv1alpha1.App, now metav1.Time) {
+	hash := envHash(app.Spec.Env)
+	replicas := int32(1)
+	if app.Spec.Replicas != nil {
+		replicas = *app.Spec.Replicas
+	}
+
+	next := int64(1)
+	if latest := latestRevision(app); latest != nil {
+		next = latest.Revision + 1
+		if target, err := strconv.ParseInt(app.Annotations[rollbackToAnnotation], 10, 64); err == nil {
+			if t := findRevision(app, target); t != nil && t.Image == app.Spec.Image && t.EnvHash == hash {
+				latest.Status = platformv1alpha1.AppRevisionRolledBack
+			}
+		}
+	}
+
+	var duration int64
+	if app.Status.RolloutStartedAt != nil {
+		duration = int64(now.Sub(app.Status.RolloutStartedAt.Time).Seconds())
+	}
+
+	app.Status.History = append(app.Status.History, platformv1alpha1.AppRevision{
+		Revision:   next,
+		Image:      app.Spec.Image,
+		CommitSHA:  app.Annotations[commitSHAAnnotation],
+		Replicas:   replicas,
+		Env:        app.Spec.Env,
+		EnvHash:    hash,
+		DeployedAt: now,
+		DeployedBy: app.Annotations[triggeredByAnnotation],
+		Duration:   duration,
+		Status:     platformv1alpha1.AppRevisionSucceeded,
+	})
+	app.Status.CurrentRevision = next
+	app.Status.RolloutStartedAt = nil
+
+	limit := defaultRevisionHistoryLimit
+	if app.Spec.RevisionHistoryLimit != nil {
+		limit = int(*app.Spec.RevisionHistoryLimit)
+	}
+	if extra := len(app.Status.History) - limit; extra > 0 {
+		app.Status.History = app.Status.History[extra:]
+	}
+}
@@ -0,0 +1,359 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// hookLabel marks hook Jobs with their stage. Hook pods deliberately do
+	// not carry appLabels so Services and Deployments never select them.
+	hookLabel = "platform.flowcd.io/hook"
+
+	conditionTypePreDeploy  = "PreDeploySucceeded"
+	conditionTypePostDeploy = "PostDeploySucceeded"
+
+	hookReasonRunning   = "Running"
+	hookReasonFailed    = "Failed"
+	hookReasonSucceeded = "Succeeded"
+
+	// hookRecheck is how often running hooks are checked, as Jobs in another
+	// namespace than the App raise no events.
+	hookRecheck = 10 * time.Second
+
+	defaultHookTimeoutSeconds = int64(600)
+)
+
+// hookStage is when a hook runs relative to the rollout of a new image.
+type hookStage string
+
+const (
+	hookStagePreDeploy  hookStage = "pre"
+	hookStagePostDeploy hookStage = "post"
+)
+
+// hooks returns the App's hooks for the stage.
+func (s hookStage) hooks(app *platformv1alpha1.App) []platformv1alpha1.AppHook {
+	switch {
+	case app.Spec.Hooks == nil:
+		return nil
+	case s == hookStagePreDeploy:
+		return app.Spec.Hooks.PreDeploy
+	default:
+		return app.Spec.Hooks.PostDeploy
+	}
+}
+
+func (s hookStage) conditionType() string {
+	if s == hookStagePreDeploy {
+		return conditionTypePreDeploy
+	}
+	return conditionTypePostDeploy
+}
+
+func (s hookStage) String() string { return string(s) + "-deploy" }
+
+// hookState summarises the hooks of a stage.
+type hookState int
+
+const (
+	hooksSucceeded hookState = iota
+	hooksRunning
+	hooksFailed
+)
+
+// hookJobName names the Job running hook for image. The name changes with
+// the image and the hook's spec, so each new image runs every hook again.
+func hookJobName(app *platformv1alpha1.App, stage hookStage, hook platformv1alpha1.AppHook, image string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(image))
+	spec, _ := json.Marshal(hook)
+	_, _ = h.Write(spec)
+	return fmt.Sprintf("%s-%s-%s-%08x", app.Name, stage, hook.Name, h.Sum32())
+}
+
+func hookLabels(app *platformv1alpha1.App, stage hookStage) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/part-of":    app.Name,
+		"app.kubernetes.io/managed-by": "flowcd-operator",
+		hookLabel:                      string(stage),
+	}
+}
+
+// hookJob builds the Job running hook for image, with the App's environment
+// and resources.
+func hookJob(app *platformv1alpha1.App, namespace string, stage hookStage, hook platformv1alpha1.AppHook, image string) *batchv1.Job {
+	name := hookJobName(app, stage, hook, image)
+	if hook.Image != "" {
+		image = hook.Image
+	}
+	backoffLimit := int32(0)
+	if hook.BackoffLimit != nil {
+		backoffLimit = *hook.BackoffLimit
+	}
+	timeout := defaultHookTimeoutSeconds
+	if hook.TimeoutSeconds != nil {
+		timeout = *hook.TimeoutSeconds
+	}
+	labels := hookLabels(app, stage)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &timeout,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:      hook.Name,
+							Image:     image,
+							Command:   hook.Command,
+							Args:      hook.Args,
+							Env:       containerEnv(append(slices.Clone(app.Spec.Env), hook.Env...)),
+							Resources: appResources(app),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// runHooks runs the stage's hooks for image one after another, starting a
+// hook's Job only once the previous one succeeded. When start is false no
+// Job is created, so only hooks that already ran count. The stage's
+// condition is set on app.Status for the caller to persist.
+func (r *AppReconciler) runHooks(ctx context.Context, app *platformv1alpha1.App, namespace string, stage hookStage, image string, start bool) (hookState, error) {
+	hooks := stage.hooks(app)
+	if len(hooks) == 0 {
+		if meta.FindStatusCondition(app.Status.Conditions, stage.conditionType()) == nil {
+			return hooksSucceeded, nil
+		}
+		meta.RemoveStatusCondition(&app.Status.Conditions, stage.conditionType())
+		return hooksSucceeded, r.pruneHookJobs(ctx, app, namespace, stage, nil)
+	}
+
+	keep := map[string]bool{}
+	state := hooksSucceeded
+	reason := hookReasonSucceeded
+	msg := fmt.Sprintf("All %s hooks succeeded for %s.", stage, imageTag(image))
+	for _, hook := range hooks {
+		desired := hookJob(app, namespace, stage, hook, image)
+		keep[desired.Name] = true
+
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: namespace}, job)
+		if apierrors.IsNotFound(err) {
+			state, reason = hooksRunning, hookReasonRunning
+			if !start {
+				msg = fmt.Sprintf("Waiting for %s to be ready before running the %s hook %s.", imageTag(image), stage, hook.Name)
+				break
+			}
+			if err := r.setOwner(app, desired); err != nil {
+				return hooksRunning, fmt.Errorf("set owner reference on hook Job: %w", err)
+			}
+			if err := r.Create(ctx, desired); err != nil {
+				return hooksRunning, fmt.Errorf("create %s hook Job %s: %w", stage, desired.Name, err)
+			}
+			msg = fmt.Sprintf("Running the %s hook %s for %s.", stage, hook.Name, imageTag(image))
+			break
+		}
+		if err != nil {
+			return hooksRunning, err
+		}
+		if failed, why := jobHasCondition(job, batchv1.JobFailed); failed {
+			state, reason = hooksFailed, hookReasonFailed
+			msg = fmt.Sprintf("The %s hook %s failed for %s: %s", stage, hook.Name, imageTag(image), why)
+			break
+		}
+		if done, _ := jobHasCondition(job, batchv1.JobComplete); !done {
+			state, reason = hooksRunning, hookReasonRunning
+			msg = fmt.Sprintf("Running the %s hook %s for %s.", stage, hook.Name, imageTag(image))
+			break
+		}
+	}
+	if err := r.pruneHookJobs(ctx, app, namespace, stage, keep); err != nil {
+		return state, err
+	}
+
+	status := metav1.ConditionFalse
+	if state == hooksSucceeded {
+		status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+		Type:               stage.conditionType(),
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: app.Generation,
+	})
+	return state, nil
+}
+
+// pruneHookJobs deletes the stage's hook Jobs not in keep, along with their
+// pods.
+func (r *AppReconciler) pruneHookJobs(ctx context.Context, app *platformv1alpha1.App, namespace string, stage hookStage, keep map[string]bool) error {
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace(namespace), client.MatchingLabels(hookLabels(app, stage))); err != nil {
+		return err
+	}
+	for i := range jobs.Items {
+		if keep[jobs.Items[i].Name] {
+			continue
+		}
+		err := r.Delete(ctx, &jobs.Items[i], client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// hooksPassed reports whether the stage's hooks succeeded for the App's
+// current spec.
+func hooksPassed(app *platformv1alpha1.App, stage hookStage) bool {
+	if len(stage.hooks(app)) == 0 {
+		return true
+	}
+	cond := meta.FindStatusCondition(app.Status.Conditions, stage.conditionType())
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == app.Generation
+}
+
+// postDeployDone reports whether a rolling update has finished its
+// post-deploy hooks. Canary and BlueGreen rollouts run them before traffic
+// moves, so they are always done by the time the rollout settles.
+func postDeployDone(app *platformv1alpha1.App) bool {
+	return rolloutStrategy(app) != platformv1alpha1.AppStrategyRollingUpdate || hooksPassed(app, hookStagePostDeploy)
+}
+
+// imageDeployed reports whether spec.image is already past its pre-deploy
+// hooks: it runs in one of the App's Deployments, is part of the current
+// rollout, or is a revision in the history.
+func (r *AppReconciler) imageDeployed(ctx context.Context, app *platformv1alpha1.App, namespace string) (bool, error) {
+	image := app.Spec.Image
+	if ro := app.Status.Rollout; ro != nil && slices.Contains([]string{ro.StableImage, ro.NewImage, ro.AbortedImage}, image) {
+		return true, nil
+	}
+	for _, rev := range app.Status.History {
+		if rev.Image == image {
+			return true, nil
+		}
+	}
+	for _, name := range []string{app.Name, canaryName(app), previewName(app)} {
+		d := &appsv1.Deployment{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, d)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if c := appContainer(&d.Spec.Template.Spec, app); c != nil && c.Image == image {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reconcilePreDeployHooks holds a new spec.image back until its pre-deploy
+// hooks succeed. It reports whether the image may be deployed; when it may
+// not, the App's phase shows the hooks' progress.
+func (r *AppReconciler) reconcilePreDeployHooks(ctx context.Context, app *platformv1alpha1.App, namespace string) (ctrl.Result, bool, error) {
+	if len(hookStagePreDeploy.hooks(app)) == 0 {
+		if meta.FindStatusCondition(app.Status.Conditions, conditionTypePreDeploy) == nil {
+			return ctrl.Result{}, true, nil
+		}
+	} else {
+		deployed, err := r.imageDeployed(ctx, app, namespace)
+		if err != nil || deployed {
+			return ctrl.Result{}, deployed, err
+		}
+	}
+
+	patch := client.MergeFrom(app.DeepCopy())
+	state, err := r.runHooks(ctx, app, namespace, hookStagePreDeploy, app.Spec.Image, true)
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+	if state == hooksSucceeded {
+		return ctrl.Result{}, true, r.Status().Patch(ctx, app, patch)
+	}
+
+	cond := meta.FindStatusCondition(app.Status.Conditions, conditionTypePreDeploy)
+	result := ctrl.Result{RequeueAfter: hookRecheck}
+	app.Status.Phase = platformv1alpha1.AppPhaseDeploying
+	if state == hooksFailed {
+		result = ctrl.Result{}
+		app.Status.Phase = platformv1alpha1.AppPhaseFailed
+		meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+			Type:               conditionTypeDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             "PreDeployHookFailed",
+			Message:            cond.Message,
+			ObservedGeneration: app.Generation,
+		})
+	}
+	meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeProgressing,
+		Status:             metav1.ConditionTrue,
+		Reason:             "PreDeployHook" + cond.Reason,
+		Message:            cond.Message,
+		ObservedGeneration: app.Generation,
+	})
+	return result, false, r.Status().Patch(ctx, app, patch)
+}
+
+// reconcilePostDeployHooks runs the post-deploy hooks of a rolling update
+// once the Deployment is ready with spec.image.
+func (r *AppReconciler) reconcilePostDeployHooks(ctx context.Context, app *platformv1alpha1.App, deployment *appsv1.Deployment) error {
+	if len(hookStagePostDeploy.hooks(app)) == 0 &&
+		meta.FindStatusCondition(app.Status.Conditions, conditionTypePostDeploy) == nil {
+		return nil
+	}
+	patch := client.MergeFrom(app.DeepCopy())
+	c := appContainer(&deployment.Spec.Template.Spec, app)
+	ready := c != nil && c.Image == app.Spec.Image && deploymentReady(deployment, appReplicas(app))
+	if _, err := r.runHooks(ctx, app, deployment.Namespace, hookStagePostDeploy, app.Spec.Image, ready); err != nil {
+		return err
+	}
+	return r.Status().Patch(ctx, app, patch)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+// LastRequestAnnotation is set by the activator to the RFC 3339 time of the
+// last request it proxied to an App. Bumping it wakes a sleeping App.
+const LastRequestAnnotation = "platform.flowcd.io/last-request-at"
+
+// idleEnabled reports whether the App opts into scale-to-zero and an
+// activator is available to wake it again.
+func (r *AppReconciler) idleEnabled(app *platformv1alpha1.App) bool {
+	return app.Spec.Idle != nil && r.ActivatorHost != "" && len(r.hosts(app)) > 0
+}
+
+// activatorServiceName is the ExternalName Service routing the App's Ingress
+// through the activator.
+func activatorServiceName(app *platformv1alpha1.App) string { return app.Name + "-activator" }
+
+// lastActivity returns when the App last served a request, was created, or
+// rolled out a new revision, whichever is latest.
+func lastActivity(app *platformv1alpha1.App) time.Time {
+	last := app.CreationTimestamp.Time
+	if t, err := time.Parse(time.RFC3339, app.Annotations[LastRequestAnnotation]); err == nil && t.After(last) {
+		last = t
+	}
+	if rev := latestRevision(app); rev != nil && rev.DeployedAt.After(last) {
+		last = rev.DeployedAt.Time
+	}
+	return last
+}
+
+// idleRemaining returns how long the App may stay up before it is put to
+// sleep. It is zero once the App is idle. A pending rollout keeps the App
+// awake so new images still get deployed and verified.
+func (r *AppReconciler) idleRemaining(app *platformv1alpha1.App, now time.Time) time.Duration {
+	if rolloutActive(app) || (rolloutPending(app) && rolloutPhase(app) != platformv1alpha1.AppPhaseAborted) {
+		return time.Duration(app.Spec.Idle.AfterMinutes) * time.Minute
+	}
+	deadline := lastActivity(app).Add(time.Duration(app.Spec.Idle.AfterMinutes) * time.Minute)
+	return max(deadline.Sub(now), 0)
+}
+
+// reconcileSleeping scales an idle App to zero while keeping its Service and
+// routes, so the next request reaches the activator and wakes it.
+func (r *AppReconciler) reconcileSleeping(ctx context.Context, app *platformv1alpha1.App, namespace string) (ctrl.Result, error) {
+	if err := r.scaleToZero(ctx, app, namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileService(ctx, app, namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileActivatorService(ctx, app, namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileRouting(ctx, app, namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	patch := client.MergeFrom(app.DeepCopy())
+	app.Status.Phase = platformv1alpha1.AppPhaseSleeping
+	app.Status.ReadyReplicas = 0
+	app.Status.AvailableReplicas = 0
+	meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeAvailable,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Idle",
+		Message:            fmt.Sprintf("No requests for %d minutes; scaled to zero until the next request.", app.Spec.Idle.AfterMinutes),
+		ObservedGeneration: app.Generation,
+	})
+	return ctrl.Result{}, r.Status().Patch(ctx, app, patch)
+}
+
+// scaleToZero scales the App's main, canary and preview Deployments to zero
+// replicas.
+func (r *AppReconciler) scaleToZero(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	for _, name := range []string{app.Name, canaryName(app), previewName(app)} {
+		existing := &appsv1.Deployment{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, existing)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if existing.Spec.Replicas == nil || *existing.Spec.Replicas != 0 {
+			patch := client.MergeFrom(existing.DeepCopy())
+			zero := int32(0)
+			existing.Spec.Replicas = &zero
+			if err := r.Patch(ctx, existing, patch, client.FieldOwner(fieldManager)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileActivatorService creates or deletes the ExternalName Service that
+// points the App's Ingress at the activator.
+func (r *AppReconciler) reconcileActivatorService(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	name := activatorServiceName(app)
+	existing := &corev1.Service{}
+	getErr := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, existing)
+
+	if !r.idleEnabled(app) {
+		if apierrors.IsNotFound(getErr) {
+			return nil
+		}
+		if getErr != nil {
+			return getErr
+		}
+		return r.Delete(ctx, existing)
+	}
+
+	desired := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    appLabels(app.Name),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: r.ActivatorHost,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       r.ActivatorPort,
+					TargetPort: intstr.FromInt32(r.ActivatorPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+
+	if err := r.setOwner(app, desired); err != nil {
+		return fmt.Errorf("set owner reference on activator Service: %w", err)
+	}
+
+	if getErr != nil && !apierrors.IsNotFound(getErr) {
+		return getErr
+	}
+	return r.apply(ctx, desired)
+}
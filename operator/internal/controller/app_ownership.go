@@ -0,0 +1,175 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// ownerNameAnnotation and ownerNamespaceLabel point a child in another
+	// namespace back at its App, standing in for the owner reference
+	// Kubernetes does not allow across namespaces. App names may be longer
+	// than a label value, so the name is selected on by its hash in
+	// ownerNameHashLabel.
+	ownerNameAnnotation = "platform.flowcd.io/owner-name"
+	ownerNameHashLabel  = "platform.flowcd.io/owner-name-hash"
+	ownerNamespaceLabel = "platform.flowcd.io/owner-namespace"
+)
+
+// ownerNameHash returns the label value standing for an App name.
+func ownerNameHash(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:16])
+}
+
+// setOwner makes the App the controller of a child in its own namespace, and
+// labels a child in any other namespace with a back-reference so that the
+// finalizer can delete it and its changes map back to the App.
+func (r *AppReconciler) setOwner(app *platformv1alpha1.App, obj client.Object) error {
+	if obj.GetNamespace() == app.Namespace {
+		return controllerutil.SetControllerReference(app, obj, r.Scheme)
+	}
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ownerNameHashLabel] = ownerNameHash(app.Name)
+	labels[ownerNamespaceLabel] = app.Namespace
+	obj.SetLabels(labels)
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ownerNameAnnotation] = app.Name
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// ownerLabels selects the children an App has in other namespaces.
+func ownerLabels(app *platformv1alpha1.App) client.MatchingLabels {
+	return client.MatchingLabels{ownerNameHashLabel: ownerNameHash(app.Name), ownerNamespaceLabel: app.Namespace}
+}
+
+// appForChild maps a child in another namespace to the App that created it,
+// so drift in that namespace triggers a reconcile. Children in the App's own
+// namespace are mapped by their owner reference instead.
+func appForChild(_ context.Context, obj client.Object) []reconcile.Request {
+	name, namespace := obj.GetAnnotations()[ownerNameAnnotation], obj.GetLabels()[ownerNamespaceLabel]
+	if name == "" || namespace == "" || obj.GetLabels()[ownerNameHashLabel] != ownerNameHash(name) {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}}
+}
+
+// cleanupChildren deletes everything the App created outside its own
+// namespace, which garbage collection cannot reach. Children in the App's
+// namespace are left to their owner references.
+func (r *AppReconciler) cleanupChildren(ctx context.Context, app *platformv1alpha1.App) error {
+	return r.deleteChildren(ctx, nil, ownerLabels(app))
+}
+
+// cleanupNamespace deletes the App's children from a namespace it no longer
+// deploys to. In the App's own namespace they are found by their owner
+// reference, elsewhere by their back-reference labels.
+func (r *AppReconciler) cleanupNamespace(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	if namespace != app.Namespace {
+		return r.deleteChildren(ctx, nil, ownerLabels(app), client.InNamespace(namespace))
+	}
+	owned := func(obj client.Object) bool { return metav1.IsControlledBy(obj, app) }
+	return r.deleteChildren(ctx, owned, client.InNamespace(namespace))
+}
+
+// moveNamespace removes the App's children and claims from the namespace it
+// was last deployed to once its destination changes, then records the new
+// one. The old workload goes before the new one is up, so that two copies
+// never run side by side.
+func (r *AppReconciler) moveNamespace(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	if prev := app.Status.Namespace; prev != "" {
+		logf.FromContext(ctx).Info("Destination namespace changed; removing old children", "from", prev, "to", namespace)
+		if err := r.cleanupNamespace(ctx, app, prev); err != nil {
+			return err
+		}
+		if err := r.releaseClaims(ctx, app, prev, nil); err != nil {
+			return err
+		}
+	}
+	patch := client.MergeFrom(app.DeepCopy())
+	app.Status.Namespace = namespace
+	return r.Status().Patch(ctx, app, patch)
+}
+
+// deleteChildren deletes the objects of every kind the App creates that
+// match opts and, when set, the match func.
+func (r *AppReconciler) deleteChildren(ctx context.Context, match func(client.Object) bool, opts ...client.ListOption) error {
+	lists := []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&corev1.ServiceList{},
+		&networkingv1.IngressList{},
+		&autoscalingv2.HorizontalPodAutoscalerList{},
+		&batchv1.JobList{},
+	}
+	routes := &unstructured.UnstructuredList{}
+	routes.SetGroupVersionKind(httpRouteGVK.GroupVersion().WithKind(httpRouteGVK.Kind + "List"))
+	lists = append(lists, routes)
+
+	for _, list := range lists {
+		err := r.List(ctx, list, opts...)
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := meta.EachListItem(list, func(item runtime.Object) error {
+			obj := item.(client.Object)
+			if match != nil && !match(obj) {
+				return nil
+			}
+			// Jobs would otherwise leave their pods behind.
+			err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("delete %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
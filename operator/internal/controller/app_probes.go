@@ -0,0 +1,95 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+// Probe defaults. Every field is set explicitly so patches compare equal to
+// what the API server stores and do not roll the Deployment needlessly.
+var (
+	defaultReadinessProbe = corev1.Probe{InitialDelaySeconds: 5, PeriodSeconds: 10, TimeoutSeconds: 1, SuccessThreshold: 1, FailureThreshold: 3}
+	defaultLivenessProbe  = corev1.Probe{InitialDelaySeconds: 15, PeriodSeconds: 20, TimeoutSeconds: 1, SuccessThreshold: 1, FailureThreshold: 3}
+	defaultStartupProbe   = corev1.Probe{PeriodSeconds: 10, TimeoutSeconds: 1, SuccessThreshold: 1, FailureThreshold: 30}
+)
+
+// appProbes builds the container's readiness, liveness and startup probes
+// from spec.healthCheck. Without a health check the container gets the TCP
+// readiness and liveness probes on the app port and no startup probe.
+func appProbes(app *platformv1alpha1.App) (readiness, liveness, startup *corev1.Probe) {
+	hc := app.Spec.HealthCheck
+	if hc == nil {
+		hc = &platformv1alpha1.AppHealthCheck{}
+	}
+	port := app.Spec.Port
+	if port == 0 {
+		port = 8080
+	}
+	if hc.Port != nil {
+		port = *hc.Port
+	}
+
+	handler := corev1.ProbeHandler{
+		TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(port)},
+	}
+	if hc.Path != "" {
+		headers := make([]corev1.HTTPHeader, 0, len(hc.Headers))
+		for _, h := range hc.Headers {
+			headers = append(headers, corev1.HTTPHeader{Name: h.Name, Value: h.Value})
+		}
+		handler = corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:        hc.Path,
+				Port:        intstr.FromInt32(port),
+				Scheme:      corev1.URISchemeHTTP,
+				HTTPHeaders: headers,
+			},
+		}
+	}
+
+	readiness = buildProbe(handler, defaultReadinessProbe, hc.Readiness)
+	liveness = buildProbe(handler, defaultLivenessProbe, hc.Liveness)
+	if hc.Startup != nil {
+		startup = buildProbe(handler, defaultStartupProbe, hc.Startup)
+	}
+	return readiness, liveness, startup
+}
+
+// buildProbe applies the overrides in settings to defaults.
+func buildProbe(handler corev1.ProbeHandler, defaults corev1.Probe, settings *platformv1alpha1.AppProbeSettings) *corev1.Probe {
+	p := defaults
+	p.ProbeHandler = handler
+	if settings == nil {
+		return &p
+	}
+	for dst, src := range map[*int32]*int32{
+		&p.InitialDelaySeconds: settings.InitialDelaySeconds,
+		&p.PeriodSeconds:       settings.PeriodSeconds,
+		&p.TimeoutSeconds:      settings.TimeoutSeconds,
+		&p.SuccessThreshold:    settings.SuccessThreshold,
+		&p.FailureThreshold:    settings.FailureThreshold,
+	} {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	return &p
+}
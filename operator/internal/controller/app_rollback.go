@@ -0,0 +1,150 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// conditionTypeRolledBack is True after the controller rolled a failed
+	// rollout back, until the next rollout succeeds.
+	conditionTypeRolledBack = "RolledBack"
+
+	// autoRollbackActor is recorded as the initiator of automatic rollbacks.
+	autoRollbackActor = "flowcd-operator"
+
+	defaultCrashLoopThreshold = 3
+)
+
+// rolloutFailure returns why the App's pending rollout is considered failed,
+// or "" while it may still succeed. A rollout fails when the Deployment
+// exceeds its progress deadline or a container running the new image has
+// restarted at least the configured number of times.
+func (r *AppReconciler) rolloutFailure(ctx context.Context, app *platformv1alpha1.App, namespace string, deployment *appsv1.Deployment) (reason, message string, err error) {
+	if deploymentProgressDeadlineExceeded(deployment) {
+		return "ProgressDeadlineExceeded", "the Deployment exceeded its progress deadline", nil
+	}
+
+	threshold := int32(defaultCrashLoopThreshold)
+	if t := app.Spec.Rollback.CrashLoopThreshold; t != nil {
+		threshold = *t
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabels(appLabels(app.Name))); err != nil {
+		return "", "", err
+	}
+	for _, pod := range pods.Items {
+		if !podRunsImage(&pod, app.Name, app.Spec.Image) {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name == app.Name && cs.RestartCount >= threshold {
+				return "CrashLoopBackOff", fmt.Sprintf("pod %s restarted %d times", pod.Name, cs.RestartCount), nil
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// podRunsImage reports whether the named container of pod is specified with
+// image. The pod spec is used because container statuses report the image as
+// resolved by the runtime.
+func podRunsImage(pod *corev1.Pod, container, image string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == container {
+			return c.Image == image
+		}
+	}
+	return false
+}
+
+// autoRollback restores the image and env of the latest healthy revision
+// when the App opts into automatic rollback and its pending rollout has
+// failed. It reports whether the App was rolled back.
+func (r *AppReconciler) autoRollback(ctx context.Context, app *platformv1alpha1.App, namespace string, deployment *appsv1.Deployment) (bool, error) {
+	if app.Spec.Rollback == nil || !app.Spec.Rollback.Auto || !rolloutPending(app) {
+		return false, nil
+	}
+	target := latestRevision(app)
+	if target == nil {
+		// Nothing healthy to go back to.
+		return false, nil
+	}
+	reason, cause, err := r.rolloutFailure(ctx, app, namespace, deployment)
+	if err != nil || reason == "" {
+		return false, err
+	}
+
+	failedImage := app.Spec.Image
+	patch := client.MergeFrom(app.DeepCopy())
+	app.Spec.Image = target.Image
+	app.Spec.Env = target.Env
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	app.Annotations[commitSHAAnnotation] = target.CommitSHA
+	app.Annotations[triggeredByAnnotation] = autoRollbackActor
+	// The failed image never became a revision, so there is nothing to mark
+	// as rolled back.
+	delete(app.Annotations, rollbackToAnnotation)
+	if err := r.Patch(ctx, app, patch); err != nil {
+		return false, fmt.Errorf("roll back App: %w", err)
+	}
+
+	msg := fmt.Sprintf("Rolled back from %s to revision %d (%s): %s.", imageTag(failedImage), target.Revision, imageTag(target.Image), cause)
+	logf.FromContext(ctx).Info("Rolled back failed rollout", "name", app.Name, "reason", reason, "image", failedImage, "revision", target.Revision)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(app, nil, corev1.EventTypeWarning, "AutoRollback", "Rollback", "%s", msg)
+	}
+
+	statusPatch := client.MergeFrom(app.DeepCopy())
+	app.Status.RolloutStartedAt = nil
+	meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeRolledBack,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: app.Generation,
+	})
+	return true, r.Status().Patch(ctx, app, statusPatch)
+}
+
+// clearRolledBack marks a previous automatic rollback as superseded once a
+// new rollout has succeeded.
+func clearRolledBack(app *platformv1alpha1.App) {
+	if !meta.IsStatusConditionTrue(app.Status.Conditions, conditionTypeRolledBack) {
+		return
+	}
+	meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+		Type:               conditionTypeRolledBack,
+		Status:             metav1.ConditionFalse,
+		Reason:             "RolloutSucceeded",
+		Message:            fmt.Sprintf("Revision %d rolled out successfully.", app.Status.CurrentRevision),
+		ObservedGeneration: app.Generation,
+	})
+}
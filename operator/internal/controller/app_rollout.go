@@ -0,0 +1,433 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// rolloutTrackLabel tells the pods of the stable, canary and preview
+	// Deployments apart.
+	rolloutTrackLabel = "platform.flowcd.io/track"
+
+	trackStable  = "stable"
+	trackCanary  = "canary"
+	trackPreview = "preview"
+
+	// promoteRequestedAnnotation is set by the API to request that a paused
+	// rollout moves on; it is acknowledged in status.rollout.observedPromoteRequest.
+	promoteRequestedAnnotation = "platform.flowcd.io/promote-requested-at"
+	// abortRequestedAnnotation is set by the API to abort the current
+	// rollout; it is acknowledged in status.rollout.observedAbortRequest.
+	abortRequestedAnnotation = "platform.flowcd.io/abort-requested-at"
+)
+
+// rolloutStrategy returns the App's rollout strategy, defaulting to
+// RollingUpdate.
+func rolloutStrategy(app *platformv1alpha1.App) platformv1alpha1.AppStrategyType {
+	if app.Spec.Strategy == nil || app.Spec.Strategy.Type == "" {
+		return platformv1alpha1.AppStrategyRollingUpdate
+	}
+	return app.Spec.Strategy.Type
+}
+
+func canaryName(app *platformv1alpha1.App) string  { return app.Name + "-canary" }
+func previewName(app *platformv1alpha1.App) string { return app.Name + "-preview" }
+
+// serviceTrack returns the track the App's main Service routes to. Canary
+// Apps without any host have no Ingress to weight, so their traffic is
+// split across both tracks by replica count instead.
+func (r *AppReconciler) serviceTrack(app *platformv1alpha1.App) string {
+	switch rolloutStrategy(app) {
+	case platformv1alpha1.AppStrategyCanary:
+		if len(r.hosts(app)) == 0 {
+			return ""
+		}
+	case platformv1alpha1.AppStrategyBlueGreen:
+		if ro := app.Status.Rollout; ro != nil && ro.Promoting {
+			return trackPreview
+		}
+	default:
+		return ""
+	}
+	return trackStable
+}
+
+// rolloutActive reports whether a Canary or BlueGreen rollout is in progress.
+func rolloutActive(app *platformv1alpha1.App) bool {
+	return app.Status.Rollout != nil && app.Status.Rollout.NewImage != ""
+}
+
+// rolloutSettled reports whether spec.image is what the stable Deployment
+// serves, i.e. whether a healthy App may record a new revision.
+func rolloutSettled(app *platformv1alpha1.App) bool {
+	if rolloutStrategy(app) == platformv1alpha1.AppStrategyRollingUpdate {
+		return true
+	}
+	ro := app.Status.Rollout
+	return ro != nil && ro.NewImage == "" && ro.StableImage == app.Spec.Image
+}
+
+// rolloutPhase returns the phase reported for the App's rollout, or "" when
+// no Canary or BlueGreen rollout is in progress or was aborted.
+func rolloutPhase(app *platformv1alpha1.App) platformv1alpha1.AppPhase {
+	ro := app.Status.Rollout
+	if ro == nil || rolloutStrategy(app) == platformv1alpha1.AppStrategyRollingUpdate {
+		return ""
+	}
+	switch {
+	case ro.NewImage == "":
+		if ro.AbortedImage != "" && ro.AbortedImage == app.Spec.Image {
+			return platformv1alpha1.AppPhaseAborted
+		}
+		return ""
+	case ro.Promoting:
+		return platformv1alpha1.AppPhasePromoting
+	case ro.Paused:
+		return platformv1alpha1.AppPhasePaused
+	case rolloutStrategy(app) == platformv1alpha1.AppStrategyCanary:
+		return platformv1alpha1.AppPhaseCanary
+	default:
+		return platformv1alpha1.AppPhasePreview
+	}
+}
+
+// resetRollout clears the progress of the current rollout.
+func resetRollout(ro *platformv1alpha1.AppRolloutStatus) {
+	ro.NewImage = ""
+	ro.CurrentStep = 0
+	ro.Weight = 0
+	ro.StepStartedAt = nil
+	ro.Paused = false
+	ro.Promoting = false
+}
+
+// canaryReplicas sizes the canary Deployment for the given traffic weight,
+// running at least one pod.
+func canaryReplicas(replicas, weight int32) int32 {
+	return max(1, (replicas*weight+99)/100)
+}
+
+// deploymentReady reports whether every replica of d runs its current pod
+// template.
+func deploymentReady(d *appsv1.Deployment, replicas int32) bool {
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas >= replicas &&
+		d.Status.ReadyReplicas >= replicas &&
+		d.Status.Replicas == d.Status.UpdatedReplicas
+}
+
+// reconcileRollout drives a Canary or BlueGreen rollout one step forward and
+// returns the stable Deployment. A new spec.image is first run next to the
+// stable version (a weighted canary or a preview), then promoted onto the
+// stable Deployment once the steps pass or a promotion is requested. Progress
+// is persisted in status.rollout.
+func (r *AppReconciler) reconcileRollout(ctx context.Context, app *platformv1alpha1.App, namespace string) (*appsv1.Deployment, error) {
+	statusPatch := client.MergeFrom(app.DeepCopy())
+	if app.Status.Rollout == nil {
+		app.Status.Rollout = &platformv1alpha1.AppRolloutStatus{}
+	}
+	ro := app.Status.Rollout
+	replicas := appReplicas(app)
+	now := metav1.Now()
+
+	// Adopt whatever the Deployment already runs as the stable version; a
+	// first deployment goes straight to stable.
+	if ro.StableImage == "" {
+		ro.StableImage = app.Spec.Image
+		existing := &appsv1.Deployment{}
+		err := r.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: namespace}, existing)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			if c := appContainer(&existing.Spec.Template.Spec, app); c != nil {
+				ro.StableImage = c.Image
+			}
+		}
+	}
+
+	wasActive := ro.NewImage != ""
+	promote := false
+	if req := app.Annotations[promoteRequestedAnnotation]; req != "" && req != ro.ObservedPromoteRequest {
+		ro.ObservedPromoteRequest = req
+		promote = ro.NewImage != ""
+	}
+	if req := app.Annotations[abortRequestedAnnotation]; req != "" && req != ro.ObservedAbortRequest {
+		ro.ObservedAbortRequest = req
+		if ro.NewImage != "" {
+			ro.AbortedImage = ro.NewImage
+			resetRollout(ro)
+			ro.Message = fmt.Sprintf("Rollout of %s aborted; %s keeps serving.", imageTag(ro.AbortedImage), imageTag(ro.StableImage))
+			promote = false
+		}
+	}
+
+	switch {
+	case app.Spec.Image == ro.StableImage || app.Spec.Image == ro.AbortedImage:
+		if ro.NewImage != "" {
+			resetRollout(ro)
+			ro.Message = fmt.Sprintf("Rollout cancelled; %s keeps serving.", imageTag(ro.StableImage))
+		}
+	case app.Spec.Image != ro.NewImage:
+		resetRollout(ro)
+		ro.NewImage = app.Spec.Image
+		ro.AbortedImage = ""
+		ro.Message = fmt.Sprintf("Starting rollout of %s.", imageTag(ro.NewImage))
+	}
+
+	stableImage := ro.StableImage
+	if ro.Promoting {
+		stableImage = ro.NewImage
+	}
+	stable, err := r.applyDeployment(ctx, app, desiredDeployment(app, namespace, app.Name, stableImage, replicas, trackStable))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case ro.NewImage == "":
+		if wasActive {
+			if err := r.cleanupRollout(ctx, app, namespace); err != nil {
+				return nil, err
+			}
+		}
+	case ro.Promoting:
+		if deploymentReady(stable, replicas) {
+			ro.StableImage = ro.NewImage
+			resetRollout(ro)
+			ro.Message = fmt.Sprintf("Promoted %s.", imageTag(ro.StableImage))
+			// Point the Service back at the stable track before the
+			// preview pods go away.
+			if err := r.reconcileService(ctx, app, namespace); err != nil {
+				return nil, err
+			}
+			if err := r.cleanupRollout(ctx, app, namespace); err != nil {
+				return nil, err
+			}
+		} else {
+			ro.Message = fmt.Sprintf("Promoting %s to the stable Deployment.", imageTag(ro.NewImage))
+		}
+	case rolloutStrategy(app) == platformv1alpha1.AppStrategyCanary:
+		if err := r.stepCanary(ctx, app, namespace, promote, now); err != nil {
+			return nil, err
+		}
+	default:
+		if err := r.stepBlueGreen(ctx, app, namespace, promote, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.Status().Patch(ctx, app, statusPatch); err != nil {
+		return nil, err
+	}
+	return stable, nil
+}
+
+// stepCanary runs the canary at the current step's weight and advances to
+// the next step once the canary is ready and the step's pause has elapsed or
+// a promotion was requested. The last step hands over to promotion.
+func (r *AppReconciler) stepCanary(ctx context.Context, app *platformv1alpha1.App, namespace string, promote bool, now metav1.Time) error {
+	ro := app.Status.Rollout
+	var steps []platformv1alpha1.CanaryStep
+	if app.Spec.Strategy.Canary != nil {
+		steps = app.Spec.Strategy.Canary.Steps
+	}
+	if len(steps) == 0 {
+		ro.Promoting = true
+		ro.Weight = 100
+		ro.Message = fmt.Sprintf("No canary steps configured; promoting %s.", imageTag(ro.NewImage))
+		return nil
+	}
+	if int(ro.CurrentStep) >= len(steps) {
+		ro.CurrentStep = int32(len(steps) - 1)
+	}
+	step := steps[ro.CurrentStep]
+
+	replicas := canaryReplicas(appReplicas(app), step.Weight)
+	canary, err := r.applyDeployment(ctx, app, desiredDeployment(app, namespace, canaryName(app), ro.NewImage, replicas, trackCanary))
+	if err != nil {
+		return err
+	}
+	ready := deploymentReady(canary, replicas)
+
+	// The canary takes no traffic until its post-deploy hooks succeed.
+	hooks, err := r.runHooks(ctx, app, namespace, hookStagePostDeploy, ro.NewImage, ready)
+	if err != nil {
+		return err
+	}
+	ro.Weight = step.Weight
+	if hooks != hooksSucceeded {
+		ro.Weight = 0
+	}
+	if err := r.applyService(ctx, app, namespace, canaryName(app), trackCanary); err != nil {
+		return err
+	}
+	if err := r.reconcileCanaryIngress(ctx, app, namespace, ro.Weight); err != nil {
+		return err
+	}
+
+	stepLabel := fmt.Sprintf("Step %d/%d", ro.CurrentStep+1, len(steps))
+	if !ready {
+		ro.Message = fmt.Sprintf("%s: waiting for %s canary pods at %d%% of traffic.", stepLabel, imageTag(ro.NewImage), ro.Weight)
+		return nil
+	}
+	if hooks != hooksSucceeded {
+		ro.Message = fmt.Sprintf("%s: %s", stepLabel, meta.FindStatusCondition(app.Status.Conditions, conditionTypePostDeploy).Message)
+		return nil
+	}
+	if ro.StepStartedAt == nil {
+		ro.StepStartedAt = &now
+	}
+	var pause time.Duration
+	if step.PauseSeconds != nil {
+		pause = time.Duration(*step.PauseSeconds) * time.Second
+	}
+	if !promote && (step.PauseSeconds == nil || now.Sub(ro.StepStartedAt.Time) < pause) {
+		ro.Paused = step.PauseSeconds == nil
+		if ro.Paused {
+			ro.Message = fmt.Sprintf("%s: %d%% of traffic on %s; waiting for promotion.", stepLabel, step.Weight, imageTag(ro.NewImage))
+		} else {
+			remaining := pause - now.Sub(ro.StepStartedAt.Time)
+			ro.Message = fmt.Sprintf("%s: %d%% of traffic on %s; next step in %s.", stepLabel, step.Weight, imageTag(ro.NewImage), remaining.Round(time.Second))
+		}
+		return nil
+	}
+
+	ro.Paused = false
+	ro.StepStartedAt = nil
+	if int(ro.CurrentStep)+1 < len(steps) {
+		ro.CurrentStep++
+		ro.Weight = steps[ro.CurrentStep].Weight
+		ro.Message = fmt.Sprintf("Step %d/%d: shifting %d%% of traffic to %s.", ro.CurrentStep+1, len(steps), ro.Weight, imageTag(ro.NewImage))
+		return nil
+	}
+	ro.Promoting = true
+	ro.Message = fmt.Sprintf("Promoting %s to the stable Deployment.", imageTag(ro.NewImage))
+	return nil
+}
+
+// stepBlueGreen runs the new version at full size behind the preview Service
+// and switches traffic to it once it is ready and promotion is requested or
+// the automatic promotion delay has elapsed.
+func (r *AppReconciler) stepBlueGreen(ctx context.Context, app *platformv1alpha1.App, namespace string, promote bool, now metav1.Time) error {
+	ro := app.Status.Rollout
+	replicas := appReplicas(app)
+	preview, err := r.applyDeployment(ctx, app, desiredDeployment(app, namespace, previewName(app), ro.NewImage, replicas, trackPreview))
+	if err != nil {
+		return err
+	}
+	if err := r.applyService(ctx, app, namespace, previewName(app), trackPreview); err != nil {
+		return err
+	}
+	ready := deploymentReady(preview, replicas)
+
+	// Traffic only switches once the preview's post-deploy hooks succeed.
+	hooks, err := r.runHooks(ctx, app, namespace, hookStagePostDeploy, ro.NewImage, ready)
+	if err != nil {
+		return err
+	}
+	if !ready {
+		ro.Message = fmt.Sprintf("Starting preview of %s.", imageTag(ro.NewImage))
+		return nil
+	}
+	if hooks != hooksSucceeded {
+		ro.Message = meta.FindStatusCondition(app.Status.Conditions, conditionTypePostDeploy).Message
+		return nil
+	}
+	if ro.StepStartedAt == nil {
+		ro.StepStartedAt = &now
+	}
+
+	var auto *time.Duration
+	if bg := app.Spec.Strategy.BlueGreen; bg != nil && bg.AutoPromotionSeconds != nil {
+		d := time.Duration(*bg.AutoPromotionSeconds) * time.Second
+		auto = &d
+	}
+	if promote || (auto != nil && now.Sub(ro.StepStartedAt.Time) >= *auto) {
+		ro.Paused = false
+		ro.Promoting = true
+		ro.Weight = 100
+		ro.Message = fmt.Sprintf("Switched traffic to %s; promoting it to the stable Deployment.", imageTag(ro.NewImage))
+		return nil
+	}
+	ro.Paused = auto == nil
+	if ro.Paused {
+		ro.Message = fmt.Sprintf("Preview of %s is ready on Service %s; waiting for promotion.", imageTag(ro.NewImage), previewName(app))
+	} else {
+		remaining := *auto - now.Sub(ro.StepStartedAt.Time)
+		ro.Message = fmt.Sprintf("Preview of %s is ready on Service %s; promoting in %s.", imageTag(ro.NewImage), previewName(app), remaining.Round(time.Second))
+	}
+	return nil
+}
+
+// reconcileCanaryIngress maintains the ingress-nginx canary Ingress that
+// sends weight percent of each custom domain's traffic to the canary Service.
+// Gateway-routed Apps weight the backends of their HTTPRoutes instead.
+func (r *AppReconciler) reconcileCanaryIngress(ctx context.Context, app *platformv1alpha1.App, namespace string, weight int32) error {
+	if len(r.hosts(app)) == 0 || r.routingBackend(app) == platformv1alpha1.AppRoutingGateway {
+		return r.deleteIngress(ctx, namespace, canaryName(app))
+	}
+
+	annotations := r.routeAnnotations(app)
+	annotations["nginx.ingress.kubernetes.io/canary"] = "true"
+	annotations["nginx.ingress.kubernetes.io/canary-weight"] = strconv.Itoa(int(weight))
+	return r.applyIngress(ctx, app, &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        canaryName(app),
+			Namespace:   namespace,
+			Labels:      appLabels(app.Name),
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: r.ingressClassName(app),
+			Rules:            ingressRules(r.hostRoutes(app), canaryName(app), appPort(app)),
+		},
+	})
+}
+
+// cleanupRollout deletes the canary and preview resources of the App.
+func (r *AppReconciler) cleanupRollout(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	objs := []client.Object{
+		&networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: canaryName(app), Namespace: namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: canaryName(app), Namespace: namespace}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: canaryName(app), Namespace: namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: previewName(app), Namespace: namespace}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: previewName(app), Namespace: namespace}},
+	}
+	for _, obj := range objs {
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete %s: %w", obj.GetName(), err)
+		}
+	}
+	return nil
+}
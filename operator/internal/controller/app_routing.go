@@ -0,0 +1,453 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// redirectLabel marks the Ingresses that only redirect a host.
+	redirectLabel = "platform.flowcd.io/redirect"
+
+	permanentRedirectAnnotation = "nginx.ingress.kubernetes.io/permanent-redirect"
+	forceSSLRedirectAnnotation  = "nginx.ingress.kubernetes.io/force-ssl-redirect"
+)
+
+// httpRouteGVK is the Gateway API HTTPRoute kind. It is handled as
+// unstructured so the operator runs on clusters without the Gateway API CRDs.
+var httpRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+
+// RoutingDefaults are the operator-wide routing settings used for whatever
+// an App's spec.routing leaves unset.
+type RoutingDefaults struct {
+	// Backend defaults to Ingress.
+	Backend platformv1alpha1.AppRoutingBackend
+	// IngressClassName is left unset on Ingresses when empty, selecting the
+	// cluster's default IngressClass.
+	IngressClassName string
+	// Annotations are set on every Ingress and HTTPRoute.
+	Annotations map[string]string
+	// Gateway is the Gateway HTTPRoutes attach to.
+	Gateway *platformv1alpha1.AppGatewayRef
+}
+
+// appRouting returns the App's routing settings, which may be empty.
+func appRouting(app *platformv1alpha1.App) *platformv1alpha1.AppRouting {
+	if app.Spec.Routing == nil {
+		return &platformv1alpha1.AppRouting{}
+	}
+	return app.Spec.Routing
+}
+
+// routingBackend returns whether the App is routed by Ingress or Gateway.
+func (r *AppReconciler) routingBackend(app *platformv1alpha1.App) platformv1alpha1.AppRoutingBackend {
+	if b := appRouting(app).Backend; b != "" {
+		return b
+	}
+	if r.Routing.Backend != "" {
+		return r.Routing.Backend
+	}
+	return platformv1alpha1.AppRoutingIngress
+}
+
+// routeAnnotations returns the operator's default annotations overridden by
+// the App's own. The result may be modified.
+func (r *AppReconciler) routeAnnotations(app *platformv1alpha1.App) map[string]string {
+	annotations := maps.Clone(r.Routing.Annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	maps.Copy(annotations, appRouting(app).Annotations)
+	return annotations
+}
+
+// ingressClassName returns the IngressClass of the App's Ingresses, or nil
+// for the cluster default.
+func (r *AppReconciler) ingressClassName(app *platformv1alpha1.App) *string {
+	name := appRouting(app).IngressClassName
+	if name == "" {
+		name = r.Routing.IngressClassName
+	}
+	if name == "" {
+		return nil
+	}
+	return &name
+}
+
+// gatewayRef returns the Gateway the App's HTTPRoutes attach to, if any.
+func (r *AppReconciler) gatewayRef(app *platformv1alpha1.App) *platformv1alpha1.AppGatewayRef {
+	if gw := appRouting(app).Gateway; gw != nil {
+		return gw
+	}
+	return r.Routing.Gateway
+}
+
+// hostRoute lists the path prefixes routed to the App on one host.
+type hostRoute struct {
+	Host  string
+	Paths []string
+}
+
+// DefaultHost returns the hostname generated for the App under the
+// platform's base domain, <name>.<namespace>.<base>, or "" without one.
+func DefaultHost(app *platformv1alpha1.App, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	return app.Name + "." + app.Namespace + "." + baseDomain
+}
+
+// hosts returns every host the App is served on: its custom domains followed
+// by the generated default host.
+func (r *AppReconciler) hosts(app *platformv1alpha1.App) []string {
+	hosts := slices.Clone(app.Spec.Domains)
+	if host := DefaultHost(app, r.BaseDomain); host != "" && !slices.Contains(hosts, host) {
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// hostRoutes returns the routed paths of every host, in hosts order. Paths
+// restricted to some domains are never routed on the default host.
+func (r *AppReconciler) hostRoutes(app *platformv1alpha1.App) []hostRoute {
+	routing := appRouting(app)
+	hosts := r.hosts(app)
+	routes := make([]hostRoute, 0, len(hosts))
+	for _, host := range hosts {
+		var paths []string
+		for _, p := range routing.Paths {
+			if (len(p.Domains) == 0 || slices.Contains(p.Domains, host)) && !slices.Contains(paths, p.Path) {
+				paths = append(paths, p.Path)
+			}
+		}
+		if len(paths) == 0 {
+			paths = []string{"/"}
+		}
+		routes = append(routes, hostRoute{Host: host, Paths: paths})
+	}
+	return routes
+}
+
+// redirectScheme is the scheme redirects point at.
+func redirectScheme(app *platformv1alpha1.App) string {
+	if app.Spec.TLS != nil || appRouting(app).ForceHTTPS {
+		return "https"
+	}
+	return "http"
+}
+
+func redirectName(app *platformv1alpha1.App, i int) string {
+	return fmt.Sprintf("%s-redirect-%d", app.Name, i)
+}
+
+// reconcileRouting routes the App's custom domains and redirects through the
+// configured backend and removes whatever the other backend left behind.
+func (r *AppReconciler) reconcileRouting(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	if r.routingBackend(app) == platformv1alpha1.AppRoutingGateway {
+		if err := r.deleteIngress(ctx, namespace, app.Name); err != nil {
+			return err
+		}
+		if err := r.pruneRedirectIngresses(ctx, app, namespace, nil); err != nil {
+			return err
+		}
+		return r.reconcileHTTPRoutes(ctx, app, namespace)
+	}
+
+	if err := r.pruneHTTPRoutes(ctx, app, namespace, nil); err != nil {
+		return err
+	}
+	if err := r.reconcileIngress(ctx, app, namespace); err != nil {
+		return err
+	}
+	return r.reconcileRedirectIngresses(ctx, app, namespace)
+}
+
+// reconcileRedirectIngresses maintains one ingress-nginx Ingress per
+// redirect, as the redirect target is set per Ingress.
+func (r *AppReconciler) reconcileRedirectIngresses(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	routing := appRouting(app)
+	keep := map[string]bool{}
+	for i, rd := range routing.Redirects {
+		name := redirectName(app, i)
+		keep[name] = true
+
+		labels := appLabels(app.Name)
+		labels[redirectLabel] = "true"
+		annotations := r.routeAnnotations(app)
+		annotations[permanentRedirectAnnotation] = redirectScheme(app) + "://" + rd.To + "$request_uri"
+		if routing.ForceHTTPS {
+			annotations[forceSSLRedirectAnnotation] = "true"
+		}
+
+		// Redirected hosts need a certificate of their own to answer HTTPS.
+		var tls []networkingv1.IngressTLS
+		if app.Spec.TLS != nil {
+			secret := app.Spec.TLS.SecretName
+			if secret == "" {
+				secret = name + "-tls"
+				maps.Copy(annotations, issuerAnnotations(app))
+			}
+			tls = []networkingv1.IngressTLS{{Hosts: []string{rd.From}, SecretName: secret}}
+		}
+
+		desired := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: r.ingressClassName(app),
+				TLS:              tls,
+				Rules:            ingressRules([]hostRoute{{Host: rd.From, Paths: []string{"/"}}}, app.Name, appPort(app)),
+			},
+		}
+		if err := r.applyIngress(ctx, app, desired); err != nil {
+			return fmt.Errorf("redirect %s: %w", rd.From, err)
+		}
+	}
+	return r.pruneRedirectIngresses(ctx, app, namespace, keep)
+}
+
+// pruneRedirectIngresses deletes the App's redirect Ingresses not in keep.
+func (r *AppReconciler) pruneRedirectIngresses(ctx context.Context, app *platformv1alpha1.App, namespace string, keep map[string]bool) error {
+	labels := appLabels(app.Name)
+	labels[redirectLabel] = "true"
+	ingresses := &networkingv1.IngressList{}
+	if err := r.List(ctx, ingresses, client.InNamespace(namespace), client.MatchingLabels(labels)); err != nil {
+		return err
+	}
+	for i := range ingresses.Items {
+		if keep[ingresses.Items[i].Name] {
+			continue
+		}
+		if err := r.Delete(ctx, &ingresses.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileHTTPRoutes maintains the App's HTTPRoutes: one per set of domains
+// sharing the same paths, one per redirect, and one redirecting plain HTTP
+// to HTTPS when forced.
+func (r *AppReconciler) reconcileHTTPRoutes(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	routing := appRouting(app)
+	gw := r.gatewayRef(app)
+	hosts := r.hostRoutes(app)
+	if gw == nil {
+		if len(hosts) == 0 && len(routing.Redirects) == 0 {
+			return r.pruneHTTPRoutes(ctx, app, namespace, nil)
+		}
+		return errors.New("no Gateway configured; set spec.routing.gateway or the operator's --gateway flag")
+	}
+	if routing.ForceHTTPS && gw.HTTPSectionName == "" {
+		return errors.New("forceHTTPS with a Gateway requires gateway.httpSectionName")
+	}
+
+	var routes []*unstructured.Unstructured
+
+	// Group domains by their paths, keeping the order of first appearance.
+	var groups [][]hostRoute
+	for _, hr := range hosts {
+		i := slices.IndexFunc(groups, func(g []hostRoute) bool { return slices.Equal(g[0].Paths, hr.Paths) })
+		if i < 0 {
+			groups = append(groups, []hostRoute{hr})
+		} else {
+			groups[i] = append(groups[i], hr)
+		}
+	}
+	backendRefs := r.httpRouteBackends(app)
+	for i, group := range groups {
+		name := app.Name
+		if i > 0 {
+			name = fmt.Sprintf("%s-routes-%d", app.Name, i)
+		}
+		hostnames := make([]any, 0, len(group))
+		for _, hr := range group {
+			hostnames = append(hostnames, hr.Host)
+		}
+		rules := make([]any, 0, len(group[0].Paths))
+		for _, p := range group[0].Paths {
+			rules = append(rules, map[string]any{
+				"matches":     []any{map[string]any{"path": map[string]any{"type": "PathPrefix", "value": p}}},
+				"backendRefs": backendRefs,
+			})
+		}
+		routes = append(routes, r.desiredHTTPRoute(app, namespace, name, parentRef(gw, gw.SectionName), hostnames, rules))
+	}
+
+	var redirectHosts []any
+	for i, rd := range routing.Redirects {
+		redirectHosts = append(redirectHosts, rd.From)
+		filter := map[string]any{
+			"type": "RequestRedirect",
+			"requestRedirect": map[string]any{
+				"scheme":     redirectScheme(app),
+				"hostname":   rd.To,
+				"statusCode": int64(301),
+			},
+		}
+		routes = append(routes, r.desiredHTTPRoute(app, namespace, redirectName(app, i),
+			parentRef(gw, gw.SectionName), []any{rd.From}, []any{map[string]any{"filters": []any{filter}}}))
+	}
+
+	if routing.ForceHTTPS {
+		var hostnames []any
+		for _, hr := range hosts {
+			hostnames = append(hostnames, hr.Host)
+		}
+		hostnames = append(hostnames, redirectHosts...)
+		filter := map[string]any{
+			"type":            "RequestRedirect",
+			"requestRedirect": map[string]any{"scheme": "https", "statusCode": int64(301)},
+		}
+		routes = append(routes, r.desiredHTTPRoute(app, namespace, app.Name+"-https-redirect",
+			parentRef(gw, gw.HTTPSectionName), hostnames, []any{map[string]any{"filters": []any{filter}}}))
+	}
+
+	keep := map[string]bool{}
+	for _, route := range routes {
+		keep[route.GetName()] = true
+		if err := r.applyHTTPRoute(ctx, app, route); err != nil {
+			return err
+		}
+	}
+	return r.pruneHTTPRoutes(ctx, app, namespace, keep)
+}
+
+// httpRouteBackends returns the backends of the App's routes. Idle Apps are
+// reached through the activator, which only proxies to the stable track; a
+// canary rollout splits traffic by weight between that and the canary.
+func (r *AppReconciler) httpRouteBackends(app *platformv1alpha1.App) []any {
+	backend := func(name string, port int32, weight int64) map[string]any {
+		return map[string]any{"name": name, "port": int64(port), "weight": weight}
+	}
+	stableName, stablePort := app.Name, appPort(app)
+	if r.idleEnabled(app) {
+		stableName, stablePort = activatorServiceName(app), r.ActivatorPort
+	}
+	ro := app.Status.Rollout
+	if rolloutStrategy(app) == platformv1alpha1.AppStrategyCanary && ro != nil &&
+		ro.NewImage != "" && !ro.Promoting && ro.Weight > 0 {
+		return []any{
+			backend(stableName, stablePort, int64(100-ro.Weight)),
+			backend(canaryName(app), appPort(app), int64(ro.Weight)),
+		}
+	}
+	return []any{backend(stableName, stablePort, 1)}
+}
+
+// parentRef references a listener of the Gateway, or all of them when
+// section is empty.
+func parentRef(gw *platformv1alpha1.AppGatewayRef, section string) map[string]any {
+	ref := map[string]any{
+		"group": "gateway.networking.k8s.io",
+		"kind":  "Gateway",
+		"name":  gw.Name,
+	}
+	if gw.Namespace != "" {
+		ref["namespace"] = gw.Namespace
+	}
+	if section != "" {
+		ref["sectionName"] = section
+	}
+	return ref
+}
+
+// desiredHTTPRoute builds an HTTPRoute of the App.
+func (r *AppReconciler) desiredHTTPRoute(app *platformv1alpha1.App, namespace, name string, parent map[string]any, hostnames, rules []any) *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteGVK)
+	route.SetName(name)
+	route.SetNamespace(namespace)
+	route.SetLabels(appLabels(app.Name))
+	route.SetAnnotations(r.routeAnnotations(app))
+	route.Object["spec"] = map[string]any{
+		"parentRefs": []any{parent},
+		"hostnames":  hostnames,
+		"rules":      rules,
+	}
+	return route
+}
+
+// applyHTTPRoute server-side applies desired.
+func (r *AppReconciler) applyHTTPRoute(ctx context.Context, app *platformv1alpha1.App, desired *unstructured.Unstructured) error {
+	if err := r.setOwner(app, desired); err != nil {
+		return fmt.Errorf("set owner reference on HTTPRoute: %w", err)
+	}
+	return r.apply(ctx, desired)
+}
+
+// pruneHTTPRoutes deletes the App's HTTPRoutes not in keep. Clusters without
+// the Gateway API have none.
+func (r *AppReconciler) pruneHTTPRoutes(ctx context.Context, app *platformv1alpha1.App, namespace string, keep map[string]bool) error {
+	routes := &unstructured.UnstructuredList{}
+	routes.SetGroupVersionKind(httpRouteGVK.GroupVersion().WithKind(httpRouteGVK.Kind + "List"))
+	err := r.List(ctx, routes, client.InNamespace(namespace), client.MatchingLabels(appLabels(app.Name)))
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for i := range routes.Items {
+		if keep[routes.Items[i].GetName()] {
+			continue
+		}
+		if err := r.Delete(ctx, &routes.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseGatewayRef parses a Gateway reference of the form
+// [namespace/]name[:section[,httpSection]]. An empty string is no Gateway.
+func ParseGatewayRef(s string) (*platformv1alpha1.AppGatewayRef, error) {
+	if s == "" {
+		return nil, nil
+	}
+	ref := &platformv1alpha1.AppGatewayRef{}
+	rest, sections, _ := strings.Cut(s, ":")
+	ref.SectionName, ref.HTTPSectionName, _ = strings.Cut(sections, ",")
+	if ns, name, ok := strings.Cut(rest, "/"); ok {
+		ref.Namespace, rest = ns, name
+	}
+	if rest == "" {
+		return nil, fmt.Errorf("invalid Gateway reference %q", s)
+	}
+	ref.Name = rest
+	return ref, nil
+}
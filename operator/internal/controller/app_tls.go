@@ -0,0 +1,255 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	clusterIssuerAnnotation = "cert-manager.io/cluster-issuer"
+	issuerAnnotation        = "cert-manager.io/issuer"
+
+	// tlsSecretIndex indexes Apps by the <namespace>/<name> of their TLS
+	// Secret.
+	tlsSecretIndex = "spec.tls.secret"
+
+	// tlsPendingRecheck is how often a pending certificate is checked again.
+	tlsPendingRecheck = 30 * time.Second
+)
+
+// certificateGVK is the cert-manager Certificate kind. It is read as
+// unstructured so the operator runs on clusters without cert-manager.
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// tlsSecretName returns the Secret holding the App's certificate, or "" when
+// the App does not configure TLS. Issued certificates are stored in
+// <app>-tls, which is also the name cert-manager gives the Certificate.
+func tlsSecretName(app *platformv1alpha1.App) string {
+	switch {
+	case app.Spec.TLS == nil:
+		return ""
+	case app.Spec.TLS.SecretName != "":
+		return app.Spec.TLS.SecretName
+	default:
+		return app.Name + "-tls"
+	}
+}
+
+// ingressTLS returns the Ingress tls block covering every custom domain. The
+// default host is left to the ingress controller's wildcard certificate.
+func ingressTLS(app *platformv1alpha1.App) []networkingv1.IngressTLS {
+	secret := tlsSecretName(app)
+	if secret == "" {
+		return nil
+	}
+	return []networkingv1.IngressTLS{{Hosts: app.Spec.Domains, SecretName: secret}}
+}
+
+// issuerAnnotations returns the cert-manager annotations that request a
+// certificate for an Ingress.
+func issuerAnnotations(app *platformv1alpha1.App) map[string]string {
+	if app.Spec.TLS == nil || app.Spec.TLS.Issuer == nil {
+		return nil
+	}
+	if app.Spec.TLS.Issuer.Kind == platformv1alpha1.AppIssuerKindIssuer {
+		return map[string]string{issuerAnnotation: app.Spec.TLS.Issuer.Name}
+	}
+	return map[string]string{clusterIssuerAnnotation: app.Spec.TLS.Issuer.Name}
+}
+
+// domainStatuses reports the certificate state of each of the App's custom
+// domains from the TLS Secret and, for issued certificates, the cert-manager
+// Certificate.
+func (r *AppReconciler) domainStatuses(ctx context.Context, app *platformv1alpha1.App, namespace string) ([]platformv1alpha1.AppDomainStatus, error) {
+	if len(app.Spec.Domains) == 0 {
+		return nil, nil
+	}
+	statuses := make([]platformv1alpha1.AppDomainStatus, 0, len(app.Spec.Domains))
+	if app.Spec.TLS == nil {
+		for _, domain := range app.Spec.Domains {
+			statuses = append(statuses, platformv1alpha1.AppDomainStatus{Domain: domain, SSLStatus: platformv1alpha1.AppSSLStatusNone})
+		}
+		return statuses, nil
+	}
+
+	issued := app.Spec.TLS.Issuer != nil
+	name := tlsSecretName(app)
+
+	// waiting explains a missing or incomplete certificate: a pending
+	// issuance, or a failure when nothing will fix it on its own.
+	waiting := platformv1alpha1.AppDomainStatus{
+		SSLStatus: platformv1alpha1.AppSSLStatusPending,
+		Message:   "Waiting for cert-manager to issue the certificate.",
+	}
+	if issued {
+		msg, err := r.certificateFailure(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		if msg != "" {
+			waiting = platformv1alpha1.AppDomainStatus{SSLStatus: platformv1alpha1.AppSSLStatusFailed, Message: msg}
+		}
+	}
+
+	var cert *x509.Certificate
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret)
+	switch {
+	case apierrors.IsNotFound(err):
+		if !issued {
+			waiting = platformv1alpha1.AppDomainStatus{
+				SSLStatus: platformv1alpha1.AppSSLStatusFailed,
+				Message:   fmt.Sprintf("Secret %s not found.", name),
+			}
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if cert, err = parseCertificate(secret); err != nil && !issued {
+			waiting = platformv1alpha1.AppDomainStatus{
+				SSLStatus: platformv1alpha1.AppSSLStatusFailed,
+				Message:   fmt.Sprintf("Secret %s: %v.", name, err),
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, domain := range app.Spec.Domains {
+		st := platformv1alpha1.AppDomainStatus{Domain: domain}
+		switch {
+		case cert == nil:
+			st.SSLStatus, st.Message = waiting.SSLStatus, waiting.Message
+		case cert.VerifyHostname(domain) != nil:
+			if issued {
+				st.SSLStatus, st.Message = waiting.SSLStatus, waiting.Message
+			} else {
+				st.SSLStatus = platformv1alpha1.AppSSLStatusFailed
+				st.Message = fmt.Sprintf("The certificate in Secret %s does not cover this domain.", name)
+			}
+		default:
+			expires := metav1.NewTime(cert.NotAfter)
+			st.ExpiresAt = &expires
+			switch {
+			case now.After(cert.NotAfter):
+				st.SSLStatus = platformv1alpha1.AppSSLStatusFailed
+				st.Message = fmt.Sprintf("The certificate expired at %s.", cert.NotAfter.UTC().Format(time.RFC3339))
+			case now.Before(cert.NotBefore):
+				st.SSLStatus = platformv1alpha1.AppSSLStatusPending
+				st.Message = fmt.Sprintf("The certificate is not valid before %s.", cert.NotBefore.UTC().Format(time.RFC3339))
+			default:
+				st.SSLStatus = platformv1alpha1.AppSSLStatusValid
+			}
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+// certificateFailure returns the message of a failed issuance of the named
+// cert-manager Certificate, or "" when it has not failed or does not exist.
+func (r *AppReconciler) certificateFailure(ctx context.Context, namespace, name string) (string, error) {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, cert)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if cond["type"] == "Issuing" && cond["status"] == "False" && cond["reason"] == "Failed" {
+			msg, _ := cond["message"].(string)
+			return "Certificate issuance failed: " + msg, nil
+		}
+	}
+	return "", nil
+}
+
+// parseCertificate returns the leaf certificate of a TLS Secret.
+func parseCertificate(secret *corev1.Secret) (*x509.Certificate, error) {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM certificate in " + corev1.TLSCertKey)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// tlsRecheckAfter returns when the App's certificates should be checked
+// again: soon while any is pending, else when the first valid one expires.
+func tlsRecheckAfter(statuses []platformv1alpha1.AppDomainStatus, now time.Time) time.Duration {
+	var next time.Duration
+	for _, st := range statuses {
+		switch st.SSLStatus {
+		case platformv1alpha1.AppSSLStatusPending:
+			return tlsPendingRecheck
+		case platformv1alpha1.AppSSLStatusValid:
+			if d := st.ExpiresAt.Sub(now); next == 0 || d < next {
+				next = d
+			}
+		}
+	}
+	return next
+}
+
+// indexTLSSecret indexes an App by the Secret holding its certificate.
+func indexTLSSecret(obj client.Object) []string {
+	app := obj.(*platformv1alpha1.App)
+	name := tlsSecretName(app)
+	if name == "" {
+		return nil
+	}
+	return []string{targetNamespace(app) + "/" + name}
+}
+
+// secretToApps maps a Secret event to the Apps serving its certificate.
+func (r *AppReconciler) secretToApps(ctx context.Context, obj client.Object) []reconcile.Request {
+	apps := &platformv1alpha1.AppList{}
+	if err := r.List(ctx, apps, client.MatchingFields{tlsSecretIndex: obj.GetNamespace() + "/" + obj.GetName()}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(apps.Items))
+	for _, app := range apps.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&app)})
+	}
+	return requests
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// volumeLabel names the spec.volumes entry a claim was created for.
+	volumeLabel = "platform.flowcd.io/volume"
+
+	// reclaimPolicyAnnotation records a claim's reclaim policy so it is
+	// still honoured once the volume has left the spec.
+	reclaimPolicyAnnotation = "platform.flowcd.io/reclaim-policy"
+)
+
+// claimName is the PersistentVolumeClaim backing a volume.
+func claimName(app *platformv1alpha1.App, v platformv1alpha1.AppVolume) string {
+	return app.Name + "-" + v.Name
+}
+
+// podVolumes returns the pod volumes backing spec.volumes.
+func podVolumes(app *platformv1alpha1.App) []corev1.Volume {
+	if len(app.Spec.Volumes) == 0 {
+		return nil
+	}
+	volumes := make([]corev1.Volume, 0, len(app.Spec.Volumes))
+	for _, v := range app.Spec.Volumes {
+		var source corev1.VolumeSource
+		switch {
+		case v.PersistentVolumeClaim != nil:
+			source.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName(app, v)}
+		case v.ConfigMap != nil:
+			source.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: v.ConfigMap.Name},
+				Items:                keyToPaths(v.ConfigMap.Items),
+				Optional:             optional(v.ConfigMap.Optional),
+			}
+		case v.Secret != nil:
+			source.Secret = &corev1.SecretVolumeSource{
+				SecretName: v.Secret.Name,
+				Items:      keyToPaths(v.Secret.Items),
+				Optional:   optional(v.Secret.Optional),
+			}
+		default:
+			source.EmptyDir = &corev1.EmptyDirVolumeSource{}
+			if v.EmptyDir != nil {
+				source.EmptyDir.Medium = v.EmptyDir.Medium
+				source.EmptyDir.SizeLimit = v.EmptyDir.SizeLimit
+			}
+		}
+		volumes = append(volumes, corev1.Volume{Name: v.Name, VolumeSource: source})
+	}
+	return volumes
+}
+
+func keyToPaths(items []platformv1alpha1.AppKeyToPath) []corev1.KeyToPath {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]corev1.KeyToPath, 0, len(items))
+	for _, item := range items {
+		out = append(out, corev1.KeyToPath{Key: item.Key, Path: item.Path})
+	}
+	return out
+}
+
+// optional returns a pointer for the optional flag of a volume source, nil
+// when unset so the API server's default is kept.
+func optional(b bool) *bool {
+	if !b {
+		return nil
+	}
+	return &b
+}
+
+// appVolumeMounts mounts every volume with a mountPath into the app
+// container.
+func appVolumeMounts(app *platformv1alpha1.App) []corev1.VolumeMount {
+	var mounts []corev1.VolumeMount
+	for _, v := range app.Spec.Volumes {
+		if v.MountPath == "" {
+			continue
+		}
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      v.Name,
+			MountPath: v.MountPath,
+			SubPath:   v.SubPath,
+			ReadOnly:  v.ReadOnly || v.ConfigMap != nil || v.Secret != nil,
+		})
+	}
+	return mounts
+}
+
+// claimAccessModes returns the access modes of a claim template.
+func claimAccessModes(pvc *platformv1alpha1.AppPVCVolume) []corev1.PersistentVolumeAccessMode {
+	if len(pvc.AccessModes) == 0 {
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	return pvc.AccessModes
+}
+
+// singleNodeClaims reports whether the App mounts a claim that only pods on
+// one node can use, so old and new pods cannot run side by side.
+func singleNodeClaims(app *platformv1alpha1.App) bool {
+	for _, v := range app.Spec.Volumes {
+		if v.PersistentVolumeClaim == nil {
+			continue
+		}
+		modes := claimAccessModes(v.PersistentVolumeClaim)
+		if !slices.Contains(modes, corev1.ReadWriteMany) && !slices.Contains(modes, corev1.ReadOnlyMany) {
+			return true
+		}
+	}
+	return false
+}
+
+// reclaimPolicy returns the reclaim policy of a claim template.
+func reclaimPolicy(pvc *platformv1alpha1.AppPVCVolume) platformv1alpha1.AppVolumeReclaimPolicy {
+	if pvc.ReclaimPolicy == "" {
+		return platformv1alpha1.AppVolumeRetain
+	}
+	return pvc.ReclaimPolicy
+}
+
+// reconcileVolumes creates the App's PersistentVolumeClaims, grows them when
+// their size is raised, and releases claims of removed volumes according to
+// their reclaim policy. Claims are not owned by the App so that retained
+// ones survive its deletion.
+func (r *AppReconciler) reconcileVolumes(ctx context.Context, app *platformv1alpha1.App, namespace string) error {
+	keep := map[string]bool{}
+	for _, v := range app.Spec.Volumes {
+		pvc := v.PersistentVolumeClaim
+		if pvc == nil {
+			continue
+		}
+		name := claimName(app, v)
+		keep[name] = true
+		labels := appLabels(app.Name)
+		labels[volumeLabel] = v.Name
+		policy := string(reclaimPolicy(pvc))
+
+		existing := &corev1.PersistentVolumeClaim{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, existing)
+		if apierrors.IsNotFound(err) {
+			desired := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        name,
+					Namespace:   namespace,
+					Labels:      labels,
+					Annotations: map[string]string{reclaimPolicyAnnotation: policy},
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      claimAccessModes(pvc),
+					StorageClassName: pvc.StorageClassName,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: pvc.Size},
+					},
+				},
+			}
+			if err := r.Create(ctx, desired); err != nil {
+				return fmt.Errorf("create PersistentVolumeClaim %s: %w", name, err)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		patch := client.MergeFrom(existing.DeepCopy())
+		if existing.Labels == nil {
+			existing.Labels = map[string]string{}
+		}
+		for k, val := range labels {
+			existing.Labels[k] = val
+		}
+		if existing.Annotations == nil {
+			existing.Annotations = map[string]string{}
+		}
+		existing.Annotations[reclaimPolicyAnnotation] = policy
+		// Claims can only grow.
+		if current := existing.Spec.Resources.Requests[corev1.ResourceStorage]; pvc.Size.Cmp(current) > 0 {
+			if existing.Spec.Resources.Requests == nil {
+				existing.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			existing.Spec.Resources.Requests[corev1.ResourceStorage] = pvc.Size
+		}
+		if err := r.Patch(ctx, existing, patch); err != nil {
+			return fmt.Errorf("patch PersistentVolumeClaim %s: %w", name, err)
+		}
+	}
+	return r.releaseClaims(ctx, app, namespace, keep)
+}
+
+// releaseClaims deletes the App's claims not in keep whose reclaim policy is
+// Delete. Retained claims are left untouched and are adopted again should a
+// volume of the same name come back.
+func (r *AppReconciler) releaseClaims(ctx context.Context, app *platformv1alpha1.App, namespace string, keep map[string]bool) error {
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, claims, client.InNamespace(namespace),
+		client.MatchingLabels(appLabels(app.Name)), client.HasLabels{volumeLabel}); err != nil {
+		return err
+	}
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		if keep[claim.Name] || claim.Annotations[reclaimPolicyAnnotation] != string(platformv1alpha1.AppVolumeDelete) {
+			continue
+		}
+		if err := r.Delete(ctx, claim); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete PersistentVolumeClaim %s: %w", claim.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+// AppPromotionReconciler reconciles an AppPromotion object.
+type AppPromotionReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=platform.flowcd.io,resources=apppromotions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=platform.flowcd.io,resources=apppromotions/status,verbs=get;update;patch
+
+// Reconcile writes a promotion's image to its App once the image is verified
+// in the source App and, where the App requires it, the promotion has been
+// approved. Finished promotions are left as a record.
+func (r *AppPromotionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	promotion := &platformv1alpha1.AppPromotion{}
+	if err := r.Get(ctx, req.NamespacedName, promotion); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	switch promotion.Status.Phase {
+	case platformv1alpha1.AppPromotionPhasePromoted, platformv1alpha1.AppPromotionPhaseRejected,
+		platformv1alpha1.AppPromotionPhaseFailed:
+		return ctrl.Result{}, nil
+	}
+
+	app := &platformv1alpha1.App{}
+	if err := r.Get(ctx, types.NamespacedName{Name: promotion.Spec.App, Namespace: promotion.Namespace}, app); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.finish(ctx, promotion, platformv1alpha1.AppPromotionPhaseFailed,
+				fmt.Sprintf("App %s not found.", promotion.Spec.App))
+		}
+		return ctrl.Result{}, err
+	}
+	if metav1.GetControllerOf(promotion) == nil {
+		if err := controllerutil.SetControllerReference(app, promotion, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Update(ctx, promotion); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	policy := app.Spec.Promotion
+	if policy == nil {
+		return ctrl.Result{}, r.finish(ctx, promotion, platformv1alpha1.AppPromotionPhaseFailed,
+			fmt.Sprintf("App %s has no spec.promotion.from to promote from.", app.Name))
+	}
+	source := policy.From
+	if source.Namespace == "" {
+		source.Namespace = app.Namespace
+	}
+	sourceApp := &platformv1alpha1.App{}
+	if err := r.Get(ctx, types.NamespacedName{Name: source.Name, Namespace: source.Namespace}, sourceApp); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.finish(ctx, promotion, platformv1alpha1.AppPromotionPhaseFailed,
+				fmt.Sprintf("Source App %s/%s not found.", source.Namespace, source.Name))
+		}
+		return ctrl.Result{}, err
+	}
+	rev := verifiedRevision(sourceApp, promotion.Spec.Image)
+	if rev == nil {
+		msg := fmt.Sprintf("App %s/%s has no successful rollout to promote.", source.Namespace, source.Name)
+		if promotion.Spec.Image != "" {
+			msg = fmt.Sprintf("Image %s was never rolled out successfully by App %s/%s.", promotion.Spec.Image, source.Namespace, source.Name)
+		}
+		return ctrl.Result{}, r.finish(ctx, promotion, platformv1alpha1.AppPromotionPhaseFailed, msg)
+	}
+
+	patch := client.MergeFrom(promotion.DeepCopy())
+	promotion.Status.Source = &source
+	promotion.Status.Revision = rev.Revision
+	promotion.Status.Image = rev.Image
+	promotion.Status.CommitSHA = rev.CommitSHA
+
+	switch {
+	case promotion.Spec.Decision == platformv1alpha1.AppPromotionDecisionRejected:
+		promotion.Status.Phase = platformv1alpha1.AppPromotionPhaseRejected
+		promotion.Status.Message = fmt.Sprintf("Rejected by %s.", promotion.Spec.DecidedBy)
+		return ctrl.Result{}, r.Status().Patch(ctx, promotion, patch)
+	case policy.RequireApproval && promotion.Spec.Decision != platformv1alpha1.AppPromotionDecisionApproved:
+		promotion.Status.Phase = platformv1alpha1.AppPromotionPhasePendingApproval
+		promotion.Status.Message = fmt.Sprintf("Promoting %s into %s requires approval.", imageTag(rev.Image), app.Name)
+		return ctrl.Result{}, r.Status().Patch(ctx, promotion, patch)
+	}
+
+	log.Info("Promoting image", "app", app.Name, "image", rev.Image, "from", source.Namespace+"/"+source.Name)
+	appPatch := client.MergeFrom(app.DeepCopy())
+	app.Spec.Image = rev.Image
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	app.Annotations[commitSHAAnnotation] = rev.CommitSHA
+	if promotion.Spec.RequestedBy != "" {
+		app.Annotations[triggeredByAnnotation] = promotion.Spec.RequestedBy
+	}
+	if err := r.Patch(ctx, app, appPatch); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	promotion.Status.Phase = platformv1alpha1.AppPromotionPhasePromoted
+	promotion.Status.PromotedAt = &now
+	promotion.Status.Message = fmt.Sprintf("Promoted %s from %s/%s revision %d.", imageTag(rev.Image), source.Namespace, source.Name, rev.Revision)
+	if promotion.Spec.DecidedBy != "" {
+		promotion.Status.Message += fmt.Sprintf(" Approved by %s.", promotion.Spec.DecidedBy)
+	}
+	return ctrl.Result{}, r.Status().Patch(ctx, promotion, patch)
+}
+
+// finish moves the promotion to a terminal phase.
+func (r *AppPromotionReconciler) finish(ctx context.Context, promotion *platformv1alpha1.AppPromotion, phase platformv1alpha1.AppPromotionPhase, msg string) error {
+	patch := client.MergeFrom(promotion.DeepCopy())
+	promotion.Status.Phase = phase
+	promotion.Status.Message = msg
+	return r.Status().Patch(ctx, promotion, patch)
+}
+
+// verifiedRevision returns the App's successful revision that rolled out
+// image, or its current revision when image is empty. It returns nil when
+// there is none, so only images proven in the source environment are
+// promoted.
+func verifiedRevision(app *platformv1alpha1.App, image string) *platformv1alpha1.AppRevision {
+	history := app.Status.History
+	for i := len(history) - 1; i >= 0; i-- {
+		rev := &history[i]
+		if rev.Status == platformv1alpha1.AppRevisionRolledBack {
+			continue
+		}
+		if image == "" && rev.Revision == app.Status.CurrentRevision || image != "" && rev.Image == image {
+			return rev
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AppPromotionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&platformv1alpha1.AppPromotion{}).
+		Named("apppromotion").
+		Complete(r)
+}
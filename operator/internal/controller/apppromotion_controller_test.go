@@ -0,0 +1,173 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+var _ = Describe("AppPromotion Controller", func() {
+	const (
+		stagingName   = "promo-staging"
+		prodName      = "promo-prod"
+		promotionName = "promo-prod-1"
+		namespace     = "default"
+	)
+
+	ctx := context.Background()
+	prodNSN := types.NamespacedName{Name: prodName, Namespace: namespace}
+	promotionNSN := types.NamespacedName{Name: promotionName, Namespace: namespace}
+
+	// ─── helpers ──────────────────────────────────────────────────────────────
+
+	makeApps := func(requireApproval bool) {
+		staging := &platformv1alpha1.App{
+			ObjectMeta: metav1.ObjectMeta{Name: stagingName, Namespace: namespace},
+			Spec: platformv1alpha1.AppSpec{
+				RepoUrl: "https://github.com/example/web",
+				Image:   "ghcr.io/example/web:v3",
+			},
+		}
+		Expect(k8sClient.Create(ctx, staging)).To(Succeed())
+		staging.Status.CurrentRevision = 3
+		staging.Status.History = []platformv1alpha1.AppRevision{
+			{Revision: 1, Image: "ghcr.io/example/web:v1", DeployedAt: metav1.Now(), Status: platformv1alpha1.AppRevisionSucceeded},
+			{Revision: 2, Image: "ghcr.io/example/web:v2", DeployedAt: metav1.Now(), Status: platformv1alpha1.AppRevisionRolledBack},
+			{Revision: 3, Image: "ghcr.io/example/web:v3", CommitSHA: "abc123", DeployedAt: metav1.Now(), Status: platformv1alpha1.AppRevisionSucceeded},
+		}
+		Expect(k8sClient.Status().Update(ctx, staging)).To(Succeed())
+
+		prod := &platformv1alpha1.App{
+			ObjectMeta: metav1.ObjectMeta{Name: prodName, Namespace: namespace},
+			Spec: platformv1alpha1.AppSpec{
+				RepoUrl: "https://github.com/example/web",
+				Image:   "ghcr.io/example/web:v1",
+				Promotion: &platformv1alpha1.AppPromotionPolicy{
+					From:            platformv1alpha1.AppReference{Name: stagingName},
+					RequireApproval: requireApproval,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, prod)).To(Succeed())
+	}
+
+	makePromotion := func(image string) {
+		Expect(k8sClient.Create(ctx, &platformv1alpha1.AppPromotion{
+			ObjectMeta: metav1.ObjectMeta{Name: promotionName, Namespace: namespace},
+			Spec: platformv1alpha1.AppPromotionSpec{
+				App:         prodName,
+				Image:       image,
+				RequestedBy: "alice@example.com",
+			},
+		})).To(Succeed())
+	}
+
+	reconcileOnce := func() *platformv1alpha1.AppPromotion {
+		r := &AppPromotionReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: promotionNSN})
+		Expect(err).NotTo(HaveOccurred())
+		promotion := &platformv1alpha1.AppPromotion{}
+		Expect(k8sClient.Get(ctx, promotionNSN, promotion)).To(Succeed())
+		return promotion
+	}
+
+	prodImage := func() string {
+		prod := &platformv1alpha1.App{}
+		Expect(k8sClient.Get(ctx, prodNSN, prod)).To(Succeed())
+		return prod.Spec.Image
+	}
+
+	AfterEach(func() {
+		_ = k8sClient.Delete(ctx, &platformv1alpha1.AppPromotion{ObjectMeta: metav1.ObjectMeta{Name: promotionName, Namespace: namespace}})
+		for _, name := range []string{stagingName, prodName} {
+			_ = k8sClient.Delete(ctx, &platformv1alpha1.App{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}})
+		}
+	})
+
+	// ─── test cases ───────────────────────────────────────────────────────────
+
+	It("should promote the source App's current revision and record it", func() {
+		makeApps(false)
+		makePromotion("")
+
+		promotion := reconcileOnce()
+		Expect(promotion.Status.Phase).To(Equal(platformv1alpha1.AppPromotionPhasePromoted))
+		Expect(promotion.Status.Revision).To(Equal(int64(3)))
+		Expect(promotion.Status.CommitSHA).To(Equal("abc123"))
+		Expect(promotion.Status.Source.Name).To(Equal(stagingName))
+		Expect(promotion.Status.PromotedAt).NotTo(BeNil())
+		Expect(metav1.GetControllerOf(promotion).Name).To(Equal(prodName))
+
+		prod := &platformv1alpha1.App{}
+		Expect(k8sClient.Get(ctx, prodNSN, prod)).To(Succeed())
+		Expect(prod.Spec.Image).To(Equal("ghcr.io/example/web:v3"))
+		Expect(prod.Annotations).To(HaveKeyWithValue("platform.flowcd.io/triggered-by", "alice@example.com"))
+		Expect(prod.Annotations).To(HaveKeyWithValue("platform.flowcd.io/commit-sha", "abc123"))
+	})
+
+	It("should refuse images the source App never rolled out successfully", func() {
+		makeApps(false)
+		makePromotion("ghcr.io/example/web:v2")
+
+		promotion := reconcileOnce()
+		Expect(promotion.Status.Phase).To(Equal(platformv1alpha1.AppPromotionPhaseFailed))
+		Expect(prodImage()).To(Equal("ghcr.io/example/web:v1"))
+	})
+
+	It("should hold the promotion until it is approved", func() {
+		makeApps(true)
+		makePromotion("ghcr.io/example/web:v1")
+
+		promotion := reconcileOnce()
+		Expect(promotion.Status.Phase).To(Equal(platformv1alpha1.AppPromotionPhasePendingApproval))
+		Expect(promotion.Status.Image).To(Equal("ghcr.io/example/web:v1"))
+
+		By("approving it")
+		patch := client.MergeFrom(promotion.DeepCopy())
+		promotion.Spec.Decision = platformv1alpha1.AppPromotionDecisionApproved
+		promotion.Spec.DecidedBy = "bob@example.com"
+		Expect(k8sClient.Patch(ctx, promotion, patch)).To(Succeed())
+		promotion = reconcileOnce()
+		Expect(promotion.Status.Phase).To(Equal(platformv1alpha1.AppPromotionPhasePromoted))
+		Expect(promotion.Status.Message).To(ContainSubstring("bob@example.com"))
+		Expect(prodImage()).To(Equal("ghcr.io/example/web:v1"))
+	})
+
+	It("should record a rejected promotion without touching the App", func() {
+		makeApps(true)
+		makePromotion("")
+		Expect(reconcileOnce().Status.Phase).To(Equal(platformv1alpha1.AppPromotionPhasePendingApproval))
+
+		promotion := &platformv1alpha1.AppPromotion{}
+		Expect(k8sClient.Get(ctx, promotionNSN, promotion)).To(Succeed())
+		patch := client.MergeFrom(promotion.DeepCopy())
+		promotion.Spec.Decision = platformv1alpha1.AppPromotionDecisionRejected
+		promotion.Spec.DecidedBy = "bob@example.com"
+		Expect(k8sClient.Patch(ctx, promotion, patch)).To(Succeed())
+		Expect(reconcileOnce().Status.Phase).To(Equal(platformv1alpha1.AppPromotionPhaseRejected))
+		Expect(prodImage()).To(Equal("ghcr.io/example/web:v1"))
+	})
+})
@@ -18,17 +18,25 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
 )
@@ -39,17 +47,35 @@ const (
 	pipelineConditionReady       = "Ready"
 	pipelineConditionProgressing = "Progressing"
 	pipelineConditionDegraded    = "Degraded"
+
+	// buildPollInterval is how often a running build is re-examined to
+	// refresh per-stage durations.
+	buildPollInterval = 10 * time.Second
+
+	// defaultRunHistoryLimit is the number of finished runs kept when
+	// spec.runHistoryLimit is unset.
+	defaultRunHistoryLimit = 10
 )
 
 // PipelineReconciler reconciles a Pipeline object.
 type PipelineReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// PodLogs reads build pod logs so they can be persisted when a run
+	// finishes. Log capture is skipped when nil.
+	PodLogs corev1client.PodsGetter
 }
 
 // +kubebuilder:rbac:groups=platform.flowcd.io,resources=pipelines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=platform.flowcd.io,resources=pipelines/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=platform.flowcd.io,resources=pipelines/finalizers,verbs=update
+// +kubebuilder:rbac:groups=platform.flowcd.io,resources=pipelineruns,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=platform.flowcd.io,resources=pipelineruns/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch
 
 // Reconcile moves the current cluster state toward the desired state declared in Pipeline.
 func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -102,13 +128,471 @@ func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, fmt.Errorf("get App %q: %w", pipeline.Spec.AppRef, err)
 	}
 
-	// 6. Pipeline is configured correctly — mark it Ready/Pending (no build
-	//    engine wired yet; a future build controller will drive phase
-	//    transitions to Running/Succeeded/Failed).
+	// 6. Drive the active run from its build Job.
+	if pipeline.Status.ActiveRun != "" {
+		return r.syncRun(ctx, pipeline, app)
+	}
+
+	// 7. Record a PipelineRun for any new run request.
+	runs, err := r.listRuns(ctx, pipeline)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if recovered, err := r.recoverOrphanedRun(ctx, pipeline, runs); err != nil {
+		return ctrl.Result{}, err
+	} else if recovered {
+		return r.syncRun(ctx, pipeline, app)
+	}
+	if trigger, ok := runRequested(pipeline, runs); ok {
+		run, err := r.createRun(ctx, pipeline, trigger)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		runs = append(runs, *run)
+	}
+
+	// 8. Start the oldest pending run, if any.
+	if next := nextPendingRun(runs); next != nil {
+		log.Info("Starting pipeline run", "pipeline", pipeline.Name, "run", next.Name)
+		return r.startRun(ctx, pipeline, next, app)
+	}
+
+	// 9. Prune finished runs beyond the retention limit.
+	if err := r.pruneRuns(ctx, pipeline, runs); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// 10. Idle — the Pipeline is configured and waiting for the next request.
 	patch := client.MergeFrom(pipeline.DeepCopy())
-	if pipeline.Status.Phase == "" || pipeline.Status.Phase == platformv1alpha1.PipelinePhaseDegraded {
+	switch pipeline.Status.Phase {
+	case "", platformv1alpha1.PipelinePhaseDegraded, platformv1alpha1.PipelinePhaseSuspended:
 		pipeline.Status.Phase = platformv1alpha1.PipelinePhasePending
 	}
+	markPipelineConfigured(pipeline)
+	if err := r.Status().Patch(ctx, pipeline, patch); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// runRequested reports whether a new PipelineRun should be recorded: either
+// the Pipeline opted into runOnCreate and has never run, or its
+// run-requested-at annotation has changed since the last request was
+// observed.
+func runRequested(pipeline *platformv1alpha1.Pipeline, runs []platformv1alpha1.PipelineRun) (platformv1alpha1.PipelineTrigger, bool) {
+	if pipeline.Spec.RunOnCreate && pipeline.Status.RunNumber == 0 && len(runs) == 0 {
+		return platformv1alpha1.PipelineTriggerInitial, true
+	}
+	req := pipeline.Annotations[pipelineRunRequestAnnotation]
+	if req != "" && req != pipeline.Status.ObservedRunRequest {
+		return platformv1alpha1.PipelineTriggerManual, true
+	}
+	return "", false
+}
+
+// listRuns returns every PipelineRun that references the Pipeline.
+func (r *PipelineReconciler) listRuns(ctx context.Context, pipeline *platformv1alpha1.Pipeline) ([]platformv1alpha1.PipelineRun, error) {
+	list := &platformv1alpha1.PipelineRunList{}
+	if err := r.List(ctx, list, client.InNamespace(pipeline.Namespace)); err != nil {
+		return nil, fmt.Errorf("list PipelineRuns: %w", err)
+	}
+	runs := make([]platformv1alpha1.PipelineRun, 0, len(list.Items))
+	for _, run := range list.Items {
+		if run.Spec.PipelineRef == pipeline.Name {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// createRun records a new pending PipelineRun owned by the Pipeline.
+func (r *PipelineReconciler) createRun(ctx context.Context, pipeline *platformv1alpha1.Pipeline, trigger platformv1alpha1.PipelineTrigger) (*platformv1alpha1.PipelineRun, error) {
+	run := &platformv1alpha1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: pipeline.Name + "-",
+			Namespace:    pipeline.Namespace,
+			Labels:       map[string]string{pipelineLabel: pipeline.Name},
+		},
+		Spec: platformv1alpha1.PipelineRunSpec{
+			PipelineRef: pipeline.Name,
+			Trigger:     trigger,
+			TriggeredBy: "system",
+		},
+	}
+	if err := controllerutil.SetControllerReference(pipeline, run, r.Scheme); err != nil {
+		return nil, fmt.Errorf("set owner reference on PipelineRun: %w", err)
+	}
+	if err := r.Create(ctx, run); err != nil {
+		return nil, fmt.Errorf("create PipelineRun: %w", err)
+	}
+
+	if trigger == platformv1alpha1.PipelineTriggerManual {
+		patch := client.MergeFrom(pipeline.DeepCopy())
+		pipeline.Status.ObservedRunRequest = pipeline.Annotations[pipelineRunRequestAnnotation]
+		if err := r.Status().Patch(ctx, pipeline, patch); err != nil {
+			return nil, err
+		}
+	}
+	return run, nil
+}
+
+// nextPendingRun returns the oldest run that has not been started yet.
+func nextPendingRun(runs []platformv1alpha1.PipelineRun) *platformv1alpha1.PipelineRun {
+	var next *platformv1alpha1.PipelineRun
+	for i := range runs {
+		run := &runs[i]
+		if run.Status.Phase != "" && run.Status.Phase != platformv1alpha1.PipelinePhasePending {
+			continue
+		}
+		if next == nil || run.CreationTimestamp.Before(&next.CreationTimestamp) {
+			next = run
+		}
+	}
+	return next
+}
+
+// startRun claims the next run number for a pending run on the Pipeline, then
+// creates its build Job and moves the run to Running. Runs created outside the
+// controller (e.g. by the API server) are adopted first.
+func (r *PipelineReconciler) startRun(ctx context.Context, pipeline *platformv1alpha1.Pipeline, run *platformv1alpha1.PipelineRun, app *platformv1alpha1.App) (ctrl.Result, error) {
+	if metav1.GetControllerOf(run) == nil || run.Labels[pipelineLabel] != pipeline.Name {
+		runPatch := client.MergeFrom(run.DeepCopy())
+		if metav1.GetControllerOf(run) == nil {
+			if err := controllerutil.SetControllerReference(pipeline, run, r.Scheme); err != nil {
+				return ctrl.Result{}, fmt.Errorf("adopt PipelineRun: %w", err)
+			}
+		}
+		if run.Labels == nil {
+			run.Labels = map[string]string{}
+		}
+		run.Labels[pipelineLabel] = pipeline.Name
+		if err := r.Patch(ctx, run, runPatch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("adopt PipelineRun: %w", err)
+		}
+	}
+
+	number := pipeline.Status.RunNumber + 1
+	image := buildImageRef(pipeline, number)
+	jobName := buildJobName(pipeline, number)
+	now := metav1.Now()
+	stages := buildStages(nil, now.Time)
+
+	// Claim the run number on the Pipeline before anything else, so a failed
+	// step below is picked up by syncRun instead of leaving a run that nothing
+	// points at. The optimistic lock keeps two reconciles from claiming the
+	// same number.
+	patch := client.MergeFromWithOptions(pipeline.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	pipeline.Status.Phase = platformv1alpha1.PipelinePhaseRunning
+	pipeline.Status.RunNumber = number
+	pipeline.Status.ActiveRun = run.Name
+	pipeline.Status.JobName = jobName
+	pipeline.Status.Image = image
+	pipeline.Status.LastRunAt = &now
+	pipeline.Status.LastRunDuration = 0
+	pipeline.Status.Stages = stages
+	pipeline.Status.CurrentStage = currentStage(stages)
+	meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+		Type:               pipelineConditionProgressing,
+		Status:             metav1.ConditionTrue,
+		Reason:             "BuildStarted",
+		Message:            fmt.Sprintf("Build Job %q started for run %d.", jobName, number),
+		ObservedGeneration: pipeline.Generation,
+	})
+	markPipelineConfigured(pipeline)
+	if err := r.Status().Patch(ctx, pipeline, patch); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return r.launchRun(ctx, pipeline, run, app)
+}
+
+// launchRun creates the build Job for the run the Pipeline claimed and marks
+// the run Running. A Job name taken by another run fails the run.
+func (r *PipelineReconciler) launchRun(ctx context.Context, pipeline *platformv1alpha1.Pipeline, run *platformv1alpha1.PipelineRun, app *platformv1alpha1.App) (ctrl.Result, error) {
+	if err := r.ensureBuildJob(ctx, pipeline, run, app); err != nil {
+		if errors.Is(err, errBuildJobConflict) {
+			return r.finishRun(ctx, pipeline, run, nil, platformv1alpha1.PipelinePhaseFailed, "BuildJobConflict", err.Error())
+		}
+		return ctrl.Result{}, err
+	}
+	if err := r.markRunStarted(ctx, pipeline, run); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: buildPollInterval}, nil
+}
+
+// errBuildJobConflict reports a build Job that already exists under the
+// run's Job name but was created for another run.
+var errBuildJobConflict = errors.New("build Job belongs to another run")
+
+// ensureBuildJob creates the build Job for run under the number and image
+// the Pipeline claimed. A Job that already exists is only adopted when it was
+// created for this run.
+func (r *PipelineReconciler) ensureBuildJob(ctx context.Context, pipeline *platformv1alpha1.Pipeline, run *platformv1alpha1.PipelineRun, app *platformv1alpha1.App) error {
+	job := buildJob(pipeline, app, pipeline.Status.RunNumber, run.Spec.Revision, pipeline.Status.Image)
+	job.Labels[pipelineRunLabel] = run.Name
+	if err := controllerutil.SetControllerReference(run, job, r.Scheme); err != nil {
+		return fmt.Errorf("set owner reference on Job: %w", err)
+	}
+	err := r.Create(ctx, job)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("create build Job: %w", err)
+	}
+	existing := &batchv1.Job{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), existing); err != nil {
+		return fmt.Errorf("get build Job: %w", err)
+	}
+	if existing.Labels[pipelineRunLabel] != run.Name {
+		return fmt.Errorf("%w: %q is labelled for run %q", errBuildJobConflict, existing.Name, existing.Labels[pipelineRunLabel])
+	}
+	return nil
+}
+
+// markRunStarted moves run to Running, copying the number, image and Job the
+// Pipeline claimed for it.
+func (r *PipelineReconciler) markRunStarted(ctx context.Context, pipeline *platformv1alpha1.Pipeline, run *platformv1alpha1.PipelineRun) error {
+	startedAt := pipeline.Status.LastRunAt
+	if startedAt == nil {
+		now := metav1.Now()
+		startedAt = &now
+	}
+	runPatch := client.MergeFrom(run.DeepCopy())
+	run.Status.Phase = platformv1alpha1.PipelinePhaseRunning
+	run.Status.RunNumber = pipeline.Status.RunNumber
+	run.Status.Image = pipeline.Status.Image
+	run.Status.JobName = pipeline.Status.JobName
+	run.Status.StartedAt = startedAt
+	run.Status.Stages = pipeline.Status.Stages
+	run.Status.Message = fmt.Sprintf("Build Job %q started.", pipeline.Status.JobName)
+	return r.Status().Patch(ctx, run, runPatch)
+}
+
+// recoverOrphanedRun points the Pipeline back at a run left Running without
+// an ActiveRun, so syncRun can finish it. It reports whether one was found.
+func (r *PipelineReconciler) recoverOrphanedRun(ctx context.Context, pipeline *platformv1alpha1.Pipeline, runs []platformv1alpha1.PipelineRun) (bool, error) {
+	for i := range runs {
+		run := &runs[i]
+		if run.Status.Phase != platformv1alpha1.PipelinePhaseRunning {
+			continue
+		}
+		patch := client.MergeFromWithOptions(pipeline.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		pipeline.Status.Phase = platformv1alpha1.PipelinePhaseRunning
+		pipeline.Status.ActiveRun = run.Name
+		pipeline.Status.JobName = run.Status.JobName
+		pipeline.Status.Image = run.Status.Image
+		pipeline.Status.LastRunAt = run.Status.StartedAt
+		if run.Status.RunNumber > pipeline.Status.RunNumber {
+			pipeline.Status.RunNumber = run.Status.RunNumber
+		}
+		if err := r.Status().Patch(ctx, pipeline, patch); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// syncRun refreshes stage status from the build pod and finalizes the active
+// run once its build Job has completed or failed. On success the resulting
+// image is written back to App.spec.image.
+func (r *PipelineReconciler) syncRun(ctx context.Context, pipeline *platformv1alpha1.Pipeline, app *platformv1alpha1.App) (ctrl.Result, error) {
+	run := &platformv1alpha1.PipelineRun{}
+	err := r.Get(ctx, types.NamespacedName{Name: pipeline.Status.ActiveRun, Namespace: pipeline.Namespace}, run)
+	if apierrors.IsNotFound(err) {
+		return r.finishRun(ctx, pipeline, nil, nil, platformv1alpha1.PipelinePhaseFailed, "PipelineRunMissing",
+			fmt.Sprintf("PipelineRun %q no longer exists.", pipeline.Status.ActiveRun))
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if run.Status.JobName == "" {
+		// startRun claimed this run but stopped before creating its Job or
+		// marking it; finish starting it.
+		return r.launchRun(ctx, pipeline, run, app)
+	}
+
+	job := &batchv1.Job{}
+	err = r.Get(ctx, types.NamespacedName{Name: run.Status.JobName, Namespace: pipeline.Namespace}, job)
+	if apierrors.IsNotFound(err) {
+		return r.finishRun(ctx, pipeline, run, nil, platformv1alpha1.PipelinePhaseFailed, "BuildJobMissing",
+			fmt.Sprintf("Build Job %q no longer exists.", run.Status.JobName))
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pod, err := r.latestBuildPod(ctx, pipeline, run.Status.RunNumber)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if ok, _ := jobHasCondition(job, batchv1.JobComplete); ok {
+		appPatch := client.MergeFrom(app.DeepCopy())
+		app.Spec.Image = run.Status.Image
+		if app.Annotations == nil {
+			app.Annotations = map[string]string{}
+		}
+		app.Annotations[commitSHAAnnotation] = run.Status.CommitSHA
+		if sha := buildCommitSHA(pod); sha != "" {
+			app.Annotations[commitSHAAnnotation] = sha
+		}
+		app.Annotations[triggeredByAnnotation] = runActor(run)
+		delete(app.Annotations, rollbackToAnnotation)
+		if err := r.Patch(ctx, app, appPatch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("update App image: %w", err)
+		}
+		appStatusPatch := client.MergeFrom(app.DeepCopy())
+		now := metav1.Now()
+		app.Status.LastBuildAt = &now
+		if err := r.Status().Patch(ctx, app, appStatusPatch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("update App status: %w", err)
+		}
+		return r.finishRun(ctx, pipeline, run, pod, platformv1alpha1.PipelinePhaseSucceeded, "BuildSucceeded",
+			fmt.Sprintf("Built and pushed %s.", run.Status.Image))
+	}
+	if ok, msg := jobHasCondition(job, batchv1.JobFailed); ok {
+		if msg == "" {
+			msg = "Build Job failed."
+		}
+		return r.finishRun(ctx, pipeline, run, pod, platformv1alpha1.PipelinePhaseFailed, "BuildFailed", msg)
+	}
+
+	stages := buildStages(pod, time.Now())
+
+	runPatch := client.MergeFrom(run.DeepCopy())
+	run.Status.Stages = stages
+	if sha := buildCommitSHA(pod); sha != "" {
+		run.Status.CommitSHA = sha
+	}
+	if err := r.Status().Patch(ctx, run, runPatch); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	patch := client.MergeFrom(pipeline.DeepCopy())
+	pipeline.Status.Stages = stages
+	pipeline.Status.CurrentStage = currentStage(stages)
+	if err := r.Status().Patch(ctx, pipeline, patch); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: buildPollInterval}, nil
+}
+
+// finishRun records the terminal phase of the active run on both the
+// PipelineRun (when it still exists) and the Pipeline.
+func (r *PipelineReconciler) finishRun(ctx context.Context, pipeline *platformv1alpha1.Pipeline, run *platformv1alpha1.PipelineRun, pod *corev1.Pod, phase platformv1alpha1.PipelinePhase, reason, msg string) (ctrl.Result, error) {
+	now := metav1.Now()
+	stages := buildStages(pod, now.Time)
+	if phase == platformv1alpha1.PipelinePhaseFailed {
+		for i := range stages {
+			if stages[i].Phase == platformv1alpha1.PipelinePhaseRunning {
+				stages[i].Phase = platformv1alpha1.PipelinePhaseFailed
+			}
+		}
+	}
+	var duration int64
+	if pipeline.Status.LastRunAt != nil {
+		duration = int64(now.Sub(pipeline.Status.LastRunAt.Time).Seconds())
+	}
+
+	if run != nil {
+		runPatch := client.MergeFrom(run.DeepCopy())
+		run.Status.Phase = phase
+		run.Status.Stages = stages
+		run.Status.FinishedAt = &now
+		if run.Status.StartedAt != nil {
+			duration = int64(now.Sub(run.Status.StartedAt.Time).Seconds())
+		}
+		run.Status.Duration = duration
+		run.Status.Message = msg
+		if sha := buildCommitSHA(pod); sha != "" {
+			run.Status.CommitSHA = sha
+		}
+		if pod != nil && r.PodLogs != nil {
+			chunks, err := r.storeBuildLogs(ctx, pipeline, run, pod)
+			if err != nil {
+				// Losing the logs must not keep the run from finishing.
+				logf.FromContext(ctx).Error(err, "Failed to store build logs", "pipelinerun", run.Name)
+			}
+			run.Status.LogChunks = chunks
+		}
+		if err := r.Status().Patch(ctx, run, runPatch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	patch := client.MergeFrom(pipeline.DeepCopy())
+	pipeline.Status.Phase = phase
+	pipeline.Status.Stages = stages
+	pipeline.Status.CurrentStage = ""
+	pipeline.Status.ActiveRun = ""
+	pipeline.Status.LastRunDuration = duration
+	meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+		Type:               pipelineConditionProgressing,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: pipeline.Generation,
+	})
+	if err := r.Status().Patch(ctx, pipeline, patch); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// pruneRuns deletes the oldest finished runs beyond spec.runHistoryLimit.
+// Pending and running runs are never pruned.
+func (r *PipelineReconciler) pruneRuns(ctx context.Context, pipeline *platformv1alpha1.Pipeline, runs []platformv1alpha1.PipelineRun) error {
+	limit := defaultRunHistoryLimit
+	if pipeline.Spec.RunHistoryLimit != nil {
+		limit = int(*pipeline.Spec.RunHistoryLimit)
+	}
+	finished := make([]platformv1alpha1.PipelineRun, 0, len(runs))
+	for _, run := range runs {
+		if run.Status.Phase == platformv1alpha1.PipelinePhaseSucceeded || run.Status.Phase == platformv1alpha1.PipelinePhaseFailed {
+			finished = append(finished, run)
+		}
+	}
+	if len(finished) <= limit {
+		return nil
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].Status.RunNumber > finished[j].Status.RunNumber
+	})
+	for i := range finished[limit:] {
+		run := &finished[limit+i]
+		if err := r.Delete(ctx, run, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete PipelineRun %q: %w", run.Name, err)
+		}
+	}
+	return nil
+}
+
+// latestBuildPod returns the most recently created pod of the given run, or
+// nil when the Job has not created one yet.
+func (r *PipelineReconciler) latestBuildPod(ctx context.Context, pipeline *platformv1alpha1.Pipeline, run int64) (*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods,
+		client.InNamespace(pipeline.Namespace),
+		client.MatchingLabels(buildLabels(pipeline, run)),
+	); err != nil {
+		return nil, fmt.Errorf("list build pods: %w", err)
+	}
+	var latest *corev1.Pod
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if latest == nil || latest.CreationTimestamp.Before(&p.CreationTimestamp) {
+			latest = p
+		}
+	}
+	return latest, nil
+}
+
+// markPipelineConfigured sets the Ready and Degraded conditions reflecting a
+// valid Pipeline configuration.
+func markPipelineConfigured(pipeline *platformv1alpha1.Pipeline) {
 	meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
 		Type:               pipelineConditionReady,
 		Status:             metav1.ConditionTrue,
@@ -123,10 +607,6 @@ func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		Message:            "No errors detected.",
 		ObservedGeneration: pipeline.Generation,
 	})
-	if err := r.Status().Patch(ctx, pipeline, patch); err != nil {
-		return ctrl.Result{}, err
-	}
-	return ctrl.Result{}, nil
 }
 
 // setPipelinePhase patches status.phase and a progressing condition.
@@ -168,6 +648,27 @@ func (r *PipelineReconciler) setPipelineDegraded(ctx context.Context, pipeline *
 func (r *PipelineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&platformv1alpha1.Pipeline{}).
+		Watches(&platformv1alpha1.PipelineRun{}, handler.EnqueueRequestsFromMapFunc(runToPipeline)).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(buildJobToPipeline)).
 		Named("pipeline").
 		Complete(r)
 }
+
+// runToPipeline maps a PipelineRun event to its Pipeline. Runs created by the
+// API server may not carry an owner reference yet, so spec.pipelineRef is used.
+func runToPipeline(_ context.Context, obj client.Object) []reconcile.Request {
+	run, ok := obj.(*platformv1alpha1.PipelineRun)
+	if !ok || run.Spec.PipelineRef == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: run.Spec.PipelineRef, Namespace: run.Namespace}}}
+}
+
+// buildJobToPipeline maps a build Job event to its Pipeline via the pipeline label.
+func buildJobToPipeline(_ context.Context, obj client.Object) []reconcile.Request {
+	name := obj.GetLabels()[pipelineLabel]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: obj.GetNamespace()}}}
+}
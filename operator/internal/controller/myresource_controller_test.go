@@ -17,13 +17,20 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,6 +49,7 @@ var _ = Describe("Pipeline Controller", func() {
 
 	pipelineNSN := types.NamespacedName{Name: pipelineName, Namespace: namespace}
 	appNSN := types.NamespacedName{Name: appName, Namespace: namespace}
+	jobNSN := types.NamespacedName{Name: pipelineName + "-build-1", Namespace: namespace}
 
 	// ─── helpers ──────────────────────────────────────────────────────────────
 
@@ -49,10 +57,12 @@ var _ = Describe("Pipeline Controller", func() {
 		return &platformv1alpha1.Pipeline{
 			ObjectMeta: metav1.ObjectMeta{Name: pipelineName, Namespace: namespace},
 			Spec: platformv1alpha1.PipelineSpec{
-				AppRef:    appRef,
-				Registry:  "ghcr.io/myorg",
-				ImageName: "test-app",
-				Suspended: suspended,
+				AppRef:      appRef,
+				Registry:    "ghcr.io/myorg",
+				ImageName:   "test-app",
+				BuildArgs:   []platformv1alpha1.BuildArg{{Name: "NODE_ENV", Value: "production"}},
+				Suspended:   suspended,
+				RunOnCreate: true,
 			},
 		}
 	}
@@ -73,6 +83,50 @@ var _ = Describe("Pipeline Controller", func() {
 		return err
 	}
 
+	listRuns := func() []platformv1alpha1.PipelineRun {
+		runs := &platformv1alpha1.PipelineRunList{}
+		Expect(k8sClient.List(ctx, runs, client.InNamespace(namespace))).To(Succeed())
+		return runs.Items
+	}
+
+	completeJob := func(name string) {
+		job := &batchv1.Job{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, job)).To(Succeed())
+		start := metav1.Now()
+		job.Status.StartTime = &start
+		job.Status.CompletionTime = &start
+		job.Status.Succeeded = 1
+		job.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue},
+			{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+		}
+		Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+	}
+
+	cleanup := func() {
+		By("deleting the Pipeline")
+		p := &platformv1alpha1.Pipeline{}
+		if err := k8sClient.Get(ctx, pipelineNSN, p); err == nil {
+			// Remove finalizer so deletion proceeds in envtest.
+			patch := client.MergeFrom(p.DeepCopy())
+			p.Finalizers = nil
+			_ = k8sClient.Patch(ctx, p, patch)
+			_ = k8sClient.Delete(ctx, p)
+		}
+		By("deleting the build Job")
+		job := &batchv1.Job{}
+		if err := k8sClient.Get(ctx, jobNSN, job); err == nil {
+			_ = k8sClient.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		}
+		By("deleting the PipelineRuns")
+		Expect(k8sClient.DeleteAllOf(ctx, &platformv1alpha1.PipelineRun{}, client.InNamespace(namespace))).To(Succeed())
+		By("deleting the App")
+		a := &platformv1alpha1.App{}
+		if err := k8sClient.Get(ctx, appNSN, a); err == nil {
+			_ = k8sClient.Delete(ctx, a)
+		}
+	}
+
 	// ─── test cases ───────────────────────────────────────────────────────────
 
 	Context("When the referenced App exists", func() {
@@ -92,20 +146,9 @@ var _ = Describe("Pipeline Controller", func() {
 			}
 		})
 
-		AfterEach(func() {
-			By("deleting the Pipeline")
-			p := &platformv1alpha1.Pipeline{}
-			if err := k8sClient.Get(ctx, pipelineNSN, p); err == nil {
-				Expect(k8sClient.Delete(ctx, p)).To(Succeed())
-			}
-			By("deleting the App")
-			a := &platformv1alpha1.App{}
-			if err := k8sClient.Get(ctx, appNSN, a); err == nil {
-				Expect(k8sClient.Delete(ctx, a)).To(Succeed())
-			}
-		})
+		AfterEach(cleanup)
 
-		It("should set Ready condition and Pending phase", func() {
+		It("should start a build run and set Running phase", func() {
 			By("running the reconciler")
 			Expect(reconcileOnce()).To(Succeed())
 
@@ -113,12 +156,218 @@ var _ = Describe("Pipeline Controller", func() {
 			pipeline := &platformv1alpha1.Pipeline{}
 			Expect(k8sClient.Get(ctx, pipelineNSN, pipeline)).To(Succeed())
 
-			Expect(pipeline.Status.Phase).To(Equal(platformv1alpha1.PipelinePhasePending))
+			Expect(pipeline.Status.Phase).To(Equal(platformv1alpha1.PipelinePhaseRunning))
+			Expect(pipeline.Status.RunNumber).To(Equal(int64(1)))
+			Expect(pipeline.Status.Image).To(Equal("ghcr.io/myorg/test-app:build-1"))
+			Expect(pipeline.Status.CurrentStage).To(Equal(buildStageClone))
+			Expect(pipeline.Status.Stages).To(HaveLen(2))
 
 			cond := meta.FindStatusCondition(pipeline.Status.Conditions, pipelineConditionReady)
 			Expect(cond).NotTo(BeNil())
 			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
 			Expect(cond.Reason).To(Equal("Configured"))
+
+			By("checking the build Job")
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, jobNSN, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.InitContainers).To(HaveLen(1))
+			Expect(job.Spec.Template.Spec.InitContainers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "GIT_REPO", Value: "https://github.com/example/app"}))
+			Expect(job.Spec.Template.Spec.Containers[0].Args).To(ContainElements(
+				"--dockerfile=/workspace/Dockerfile",
+				"--destination=ghcr.io/myorg/test-app:build-1",
+				"--build-arg=NODE_ENV=production",
+			))
+
+			By("checking the recorded PipelineRun")
+			runs := listRuns()
+			Expect(runs).To(HaveLen(1))
+			Expect(runs[0].Spec.Trigger).To(Equal(platformv1alpha1.PipelineTriggerInitial))
+			Expect(runs[0].Status.Phase).To(Equal(platformv1alpha1.PipelinePhaseRunning))
+			Expect(runs[0].Status.JobName).To(Equal(jobNSN.Name))
+			Expect(pipeline.Status.ActiveRun).To(Equal(runs[0].Name))
+		})
+
+		It("should mark the run Succeeded and write the image back to the App", func() {
+			Expect(reconcileOnce()).To(Succeed())
+
+			By("simulating a completed build Job")
+			completeJob(jobNSN.Name)
+
+			By("reconciling again")
+			Expect(reconcileOnce()).To(Succeed())
+
+			pipeline := &platformv1alpha1.Pipeline{}
+			Expect(k8sClient.Get(ctx, pipelineNSN, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(platformv1alpha1.PipelinePhaseSucceeded))
+
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Spec.Image).To(Equal("ghcr.io/myorg/test-app:build-1"))
+			Expect(app.Status.LastBuildAt).NotTo(BeNil())
+
+			runs := listRuns()
+			Expect(runs).To(HaveLen(1))
+			Expect(runs[0].Status.Phase).To(Equal(platformv1alpha1.PipelinePhaseSucceeded))
+			Expect(runs[0].Status.FinishedAt).NotTo(BeNil())
+			Expect(runs[0].Status.Image).To(Equal("ghcr.io/myorg/test-app:build-1"))
+		})
+
+		It("should record a Manual run when run-requested-at changes", func() {
+			Expect(reconcileOnce()).To(Succeed())
+			completeJob(jobNSN.Name)
+			Expect(reconcileOnce()).To(Succeed())
+
+			By("requesting a new run")
+			pipeline := &platformv1alpha1.Pipeline{}
+			Expect(k8sClient.Get(ctx, pipelineNSN, pipeline)).To(Succeed())
+			patch := client.MergeFrom(pipeline.DeepCopy())
+			pipeline.Annotations = map[string]string{pipelineRunRequestAnnotation: "2026-01-01T00:00:00Z"}
+			Expect(k8sClient.Patch(ctx, pipeline, patch)).To(Succeed())
+
+			Expect(reconcileOnce()).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, pipelineNSN, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(platformv1alpha1.PipelinePhaseRunning))
+			Expect(pipeline.Status.RunNumber).To(Equal(int64(2)))
+			Expect(pipeline.Status.ObservedRunRequest).To(Equal("2026-01-01T00:00:00Z"))
+
+			runs := listRuns()
+			Expect(runs).To(HaveLen(2))
+			triggers := []platformv1alpha1.PipelineTrigger{runs[0].Spec.Trigger, runs[1].Spec.Trigger}
+			Expect(triggers).To(ConsistOf(platformv1alpha1.PipelineTriggerInitial, platformv1alpha1.PipelineTriggerManual))
+
+			By("cleaning up the second build Job")
+			_ = k8sClient.Delete(ctx, &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: pipelineName + "-build-2", Namespace: namespace}})
+		})
+
+		It("should prune finished runs beyond runHistoryLimit", func() {
+			By("recording two finished runs")
+			for i := int64(1); i <= 2; i++ {
+				run := &platformv1alpha1.PipelineRun{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", pipelineName, i), Namespace: namespace},
+					Spec:       platformv1alpha1.PipelineRunSpec{PipelineRef: pipelineName},
+				}
+				Expect(k8sClient.Create(ctx, run)).To(Succeed())
+				run.Status.Phase = platformv1alpha1.PipelinePhaseSucceeded
+				run.Status.RunNumber = i
+				Expect(k8sClient.Status().Update(ctx, run)).To(Succeed())
+			}
+
+			By("limiting the history to one run")
+			pipeline := &platformv1alpha1.Pipeline{}
+			Expect(k8sClient.Get(ctx, pipelineNSN, pipeline)).To(Succeed())
+			patch := client.MergeFrom(pipeline.DeepCopy())
+			limit := int32(1)
+			pipeline.Spec.RunHistoryLimit = &limit
+			Expect(k8sClient.Patch(ctx, pipeline, patch)).To(Succeed())
+
+			Expect(reconcileOnce()).To(Succeed())
+
+			runs := listRuns()
+			Expect(runs).To(HaveLen(1))
+			Expect(runs[0].Name).To(Equal(pipelineName + "-2"))
+		})
+
+		It("should not adopt a build Job created for another run", func() {
+			By("leaving a Job under the first run's Job name")
+			stale := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      jobNSN.Name,
+					Namespace: namespace,
+					Labels:    map[string]string{pipelineRunLabel: "someone-else"},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyNever,
+						Containers:    []corev1.Container{{Name: "c", Image: "busybox"}},
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, stale)).To(Succeed())
+
+			Expect(reconcileOnce()).To(Succeed())
+
+			runs := listRuns()
+			Expect(runs).To(HaveLen(1))
+			Expect(runs[0].Status.Phase).To(Equal(platformv1alpha1.PipelinePhaseFailed))
+			pipeline := &platformv1alpha1.Pipeline{}
+			Expect(k8sClient.Get(ctx, pipelineNSN, pipeline)).To(Succeed())
+			Expect(pipeline.Status.ActiveRun).To(BeEmpty())
+			cond := meta.FindStatusCondition(pipeline.Status.Conditions, pipelineConditionProgressing)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal("BuildJobConflict"))
+		})
+
+		It("should recover a Running run that no ActiveRun points at", func() {
+			Expect(reconcileOnce()).To(Succeed())
+
+			By("losing the Pipeline's pointer to the run")
+			pipeline := &platformv1alpha1.Pipeline{}
+			Expect(k8sClient.Get(ctx, pipelineNSN, pipeline)).To(Succeed())
+			patch := client.MergeFrom(pipeline.DeepCopy())
+			pipeline.Status.ActiveRun = ""
+			Expect(k8sClient.Status().Patch(ctx, pipeline, patch)).To(Succeed())
+
+			completeJob(jobNSN.Name)
+			Expect(reconcileOnce()).To(Succeed())
+			Expect(reconcileOnce()).To(Succeed())
+
+			runs := listRuns()
+			Expect(runs).To(HaveLen(1))
+			Expect(runs[0].Status.Phase).To(Equal(platformv1alpha1.PipelinePhaseSucceeded))
+		})
+
+		It("should mark the run Failed when the build Job fails", func() {
+			Expect(reconcileOnce()).To(Succeed())
+
+			By("simulating a failed build Job")
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, jobNSN, job)).To(Succeed())
+			start := metav1.Now()
+			job.Status.StartTime = &start
+			job.Status.Failed = 1
+			job.Status.Conditions = []batchv1.JobCondition{
+				{Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded",
+					Message: "Job has reached the specified backoff limit"},
+			}
+			Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+
+			By("reconciling again")
+			Expect(reconcileOnce()).To(Succeed())
+
+			pipeline := &platformv1alpha1.Pipeline{}
+			Expect(k8sClient.Get(ctx, pipelineNSN, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(platformv1alpha1.PipelinePhaseFailed))
+			cond := meta.FindStatusCondition(pipeline.Status.Conditions, pipelineConditionProgressing)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal("BuildFailed"))
+
+			app := &platformv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, appNSN, app)).To(Succeed())
+			Expect(app.Spec.Image).To(BeEmpty())
+		})
+	})
+
+	Context("When the Pipeline does not opt into runOnCreate", func() {
+		BeforeEach(func() {
+			Expect(k8sClient.Create(ctx, makeApp())).To(Succeed())
+			pipeline := makePipeline(appName, false)
+			pipeline.Spec.RunOnCreate = false
+			Expect(k8sClient.Create(ctx, pipeline)).To(Succeed())
+		})
+
+		AfterEach(cleanup)
+
+		It("should not start a build until one is requested", func() {
+			Expect(reconcileOnce()).To(Succeed())
+
+			pipeline := &platformv1alpha1.Pipeline{}
+			Expect(k8sClient.Get(ctx, pipelineNSN, pipeline)).To(Succeed())
+			Expect(pipeline.Status.RunNumber).To(BeZero())
+			Expect(listRuns()).To(BeEmpty())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, jobNSN, &batchv1.Job{}))).To(BeTrue())
 		})
 	})
 
@@ -132,12 +381,7 @@ var _ = Describe("Pipeline Controller", func() {
 			}
 		})
 
-		AfterEach(func() {
-			p := &platformv1alpha1.Pipeline{}
-			if err := k8sClient.Get(ctx, pipelineNSN, p); err == nil {
-				Expect(k8sClient.Delete(ctx, p)).To(Succeed())
-			}
-		})
+		AfterEach(cleanup)
 
 		It("should set Degraded phase with AppNotFound condition", func() {
 			By("running the reconciler")
@@ -166,12 +410,7 @@ var _ = Describe("Pipeline Controller", func() {
 			}
 		})
 
-		AfterEach(func() {
-			p := &platformv1alpha1.Pipeline{}
-			if err := k8sClient.Get(ctx, pipelineNSN, p); err == nil {
-				Expect(k8sClient.Delete(ctx, p)).To(Succeed())
-			}
-		})
+		AfterEach(cleanup)
 
 		It("should set Suspended phase", func() {
 			By("running the reconciler")
@@ -185,3 +424,35 @@ var _ = Describe("Pipeline Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("Build log chunking", func() {
+	It("should split at line boundaries without exceeding the chunk size", func() {
+		out := []byte("[build] step 1\n[build] step 2\n[build] step 3\n")
+		chunks := chunkBuildLog(out, 20)
+		Expect(chunks).To(HaveLen(3))
+		for _, c := range chunks {
+			Expect(len(c)).To(BeNumerically("<=", 20))
+			Expect(c).To(HaveSuffix("\n"))
+		}
+		Expect(bytes.Join(chunks, nil)).To(Equal(out))
+	})
+
+	It("should not split a multi-byte character in a long line", func() {
+		out := []byte(strings.Repeat("é", 10))
+		chunks := chunkBuildLog(out, 5)
+		for _, c := range chunks {
+			Expect(len(c)).To(BeNumerically("<=", 5))
+			Expect(utf8.Valid(c)).To(BeTrue())
+		}
+		Expect(bytes.Join(chunks, nil)).To(Equal(out))
+	})
+
+	It("should keep the tail of oversized logs", func() {
+		line := bytes.Repeat([]byte("x"), 1023)
+		out := bytes.Repeat(append(line, '\n'), maxBuildLogBytes/1024+10)
+		got := truncateBuildLog(out)
+		Expect(got).To(HavePrefix("[flowcd] log truncated"))
+		Expect(got).To(HaveSuffix(string(line) + "\n"))
+		Expect(len(got)).To(BeNumerically("<", maxBuildLogBytes+100))
+	})
+})
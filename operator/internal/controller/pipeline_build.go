@@ -0,0 +1,264 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// pipelineRunRequestAnnotation requests a new run whenever its value changes.
+	pipelineRunRequestAnnotation = "platform.flowcd.io/run-requested-at"
+
+	pipelineLabel = "platform.flowcd.io/pipeline"
+	runLabel      = "platform.flowcd.io/run"
+
+	buildStageClone = "clone"
+	buildStageBuild = "build"
+
+	defaultGitImage     = "alpine/git:2.47.2"
+	defaultBuilderImage = "gcr.io/kaniko-project/executor:v1.23.2"
+
+	defaultBuildTimeoutSeconds = int64(600)
+	buildJobTTLSeconds         = int32(3600)
+
+	buildWorkspacePath = "/workspace"
+)
+
+// cloneScript fetches a single ref into the workspace and records the
+// resolved commit SHA as the container's termination message. The repository
+// URL and ref are passed through the environment so they are never
+// interpreted by the shell.
+const cloneScript = `set -e
+git init -q ` + buildWorkspacePath + `
+cd ` + buildWorkspacePath + `
+git remote add origin "$GIT_REPO"
+git fetch -q --depth 1 origin "$GIT_REF"
+git checkout -q FETCH_HEAD
+git rev-parse HEAD > /dev/termination-log`
+
+// buildLabels returns the label set applied to build Jobs and their pods.
+// It intentionally omits app.kubernetes.io/name so build pods are never
+// selected by the App's Service.
+func buildLabels(pipeline *platformv1alpha1.Pipeline, run int64) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/component":  "build",
+		"app.kubernetes.io/managed-by": "flowcd-operator",
+		pipelineLabel:                  pipeline.Name,
+		runLabel:                       strconv.FormatInt(run, 10),
+	}
+}
+
+// buildJobName returns the name of the Job executing the given run.
+func buildJobName(pipeline *platformv1alpha1.Pipeline, run int64) string {
+	return fmt.Sprintf("%s-build-%d", pipeline.Name, run)
+}
+
+// buildImageRef returns the image reference a run pushes to.
+func buildImageRef(pipeline *platformv1alpha1.Pipeline, run int64) string {
+	registry := strings.TrimSuffix(pipeline.Spec.Registry, "/")
+	return fmt.Sprintf("%s/%s:build-%d", registry, pipeline.Spec.ImageName, run)
+}
+
+// buildJob assembles the Job that clones ref (the App's branch when empty)
+// from the App's repository and builds and pushes its image. The clone runs as
+// an init container so each stage gets its own container status to derive
+// durations from.
+func buildJob(pipeline *platformv1alpha1.Pipeline, app *platformv1alpha1.App, run int64, ref, image string) *batchv1.Job {
+	if ref == "" {
+		ref = app.Spec.Branch
+	}
+	if ref == "" {
+		ref = "main"
+	}
+	dockerfile := pipeline.Spec.DockerfilePath
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	timeout := pipeline.Spec.TimeoutSeconds
+	if timeout == 0 {
+		timeout = defaultBuildTimeoutSeconds
+	}
+
+	args := []string{
+		"--context=dir://" + buildWorkspacePath,
+		"--dockerfile=" + path.Join(buildWorkspacePath, dockerfile),
+		"--destination=" + image,
+	}
+	for _, a := range pipeline.Spec.BuildArgs {
+		args = append(args, fmt.Sprintf("--build-arg=%s=%s", a.Name, a.Value))
+	}
+
+	workspace := corev1.VolumeMount{Name: "workspace", MountPath: buildWorkspacePath}
+	volumes := []corev1.Volume{
+		{Name: "workspace", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}
+	builderMounts := []corev1.VolumeMount{workspace}
+	if pipeline.Spec.RegistrySecret != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: "registry-credentials",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: pipeline.Spec.RegistrySecret,
+					Items:      []corev1.KeyToPath{{Key: corev1.DockerConfigJsonKey, Path: "config.json"}},
+				},
+			},
+		})
+		builderMounts = append(builderMounts, corev1.VolumeMount{
+			Name:      "registry-credentials",
+			MountPath: "/kaniko/.docker",
+			ReadOnly:  true,
+		})
+	}
+
+	backoffLimit := int32(0)
+	ttl := buildJobTTLSeconds
+	labels := buildLabels(pipeline, run)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      buildJobName(pipeline, run),
+			Namespace: pipeline.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			ActiveDeadlineSeconds:   &timeout,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					InitContainers: []corev1.Container{
+						{
+							Name:    buildStageClone,
+							Image:   defaultGitImage,
+							Command: []string{"/bin/sh", "-c", cloneScript},
+							Env: []corev1.EnvVar{
+								{Name: "GIT_REPO", Value: app.Spec.RepoUrl},
+								{Name: "GIT_REF", Value: ref},
+							},
+							VolumeMounts: []corev1.VolumeMount{workspace},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:         buildStageBuild,
+							Image:        defaultBuilderImage,
+							Args:         args,
+							VolumeMounts: builderMounts,
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
+
+// buildStages derives per-stage status from the build pod's container
+// statuses. A nil pod yields every stage as Pending.
+func buildStages(pod *corev1.Pod, now time.Time) []platformv1alpha1.PipelineStageStatus {
+	stages := []platformv1alpha1.PipelineStageStatus{
+		{Name: buildStageClone, Phase: platformv1alpha1.PipelinePhasePending},
+		{Name: buildStageBuild, Phase: platformv1alpha1.PipelinePhasePending},
+	}
+	if pod == nil {
+		return stages
+	}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.Name == buildStageClone {
+			stages[0] = stageFromContainer(cs, now)
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == buildStageBuild {
+			stages[1] = stageFromContainer(cs, now)
+		}
+	}
+	return stages
+}
+
+// stageFromContainer maps a single container status onto a stage status.
+func stageFromContainer(cs corev1.ContainerStatus, now time.Time) platformv1alpha1.PipelineStageStatus {
+	stage := platformv1alpha1.PipelineStageStatus{Name: cs.Name, Phase: platformv1alpha1.PipelinePhasePending}
+	switch {
+	case cs.State.Terminated != nil:
+		t := cs.State.Terminated
+		stage.Phase = platformv1alpha1.PipelinePhaseSucceeded
+		if t.ExitCode != 0 {
+			stage.Phase = platformv1alpha1.PipelinePhaseFailed
+		}
+		stage.Duration = int64(t.FinishedAt.Sub(t.StartedAt.Time).Seconds())
+	case cs.State.Running != nil:
+		stage.Phase = platformv1alpha1.PipelinePhaseRunning
+		stage.Duration = int64(now.Sub(cs.State.Running.StartedAt.Time).Seconds())
+	}
+	return stage
+}
+
+// buildCommitSHA returns the commit recorded by a successful clone stage, if any.
+func buildCommitSHA(pod *corev1.Pod) string {
+	if pod == nil {
+		return ""
+	}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.Name == buildStageClone && cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0 {
+			return strings.TrimSpace(cs.State.Terminated.Message)
+		}
+	}
+	return ""
+}
+
+// runActor returns who or what started a run, for recording on the App.
+func runActor(run *platformv1alpha1.PipelineRun) string {
+	if run.Spec.TriggeredBy != "" {
+		return run.Spec.TriggeredBy
+	}
+	return strings.ToLower(string(run.Spec.Trigger))
+}
+
+// currentStage returns the name of the first stage that has not finished.
+func currentStage(stages []platformv1alpha1.PipelineStageStatus) string {
+	for _, s := range stages {
+		if s.Phase == platformv1alpha1.PipelinePhasePending || s.Phase == platformv1alpha1.PipelinePhaseRunning {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// jobHasCondition reports whether the Job carries the given condition with
+// status True.
+func jobHasCondition(job *batchv1.Job, condType batchv1.JobConditionType) (bool, string) {
+	for _, c := range job.Status.Conditions {
+		if c.Type == condType && c.Status == corev1.ConditionTrue {
+			return true, c.Message
+		}
+	}
+	return false, ""
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+const (
+	// pipelineRunLabel names the PipelineRun a build log ConfigMap belongs to.
+	pipelineRunLabel = "platform.flowcd.io/pipelinerun"
+	// buildLogChunkLabel holds the zero-based index of a build log chunk.
+	buildLogChunkLabel = "platform.flowcd.io/chunk"
+	// buildLogKey is the ConfigMap data key holding a chunk of build output.
+	buildLogKey = "log"
+
+	// buildLogChunkBytes keeps each ConfigMap comfortably below the 1 MiB
+	// object size limit.
+	buildLogChunkBytes = 512 * 1024
+	// maxBuildLogBytes caps the output kept per run; the tail is retained
+	// because that is where build failures are reported.
+	maxBuildLogBytes = 8 * 1024 * 1024
+)
+
+// buildLogConfigMapName returns the name of the ConfigMap holding chunk i of
+// a run's build output.
+func buildLogConfigMapName(run *platformv1alpha1.PipelineRun, i int) string {
+	return fmt.Sprintf("%s-logs-%d", run.Name, i)
+}
+
+// collectBuildLogs reads the output of every build stage container, prefixing
+// each line with its stage name. A stage whose logs cannot be read contributes
+// a single explanatory line.
+func (r *PipelineReconciler) collectBuildLogs(ctx context.Context, pod *corev1.Pod) []byte {
+	var buf bytes.Buffer
+	for _, stage := range []string{buildStageClone, buildStageBuild} {
+		raw, err := r.PodLogs.Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: stage}).DoRaw(ctx)
+		if err != nil {
+			fmt.Fprintf(&buf, "[%s] logs unavailable: %v\n", stage, err)
+			continue
+		}
+		for _, line := range bytes.SplitAfter(raw, []byte("\n")) {
+			if len(line) == 0 {
+				continue
+			}
+			fmt.Fprintf(&buf, "[%s] %s", stage, line)
+			if line[len(line)-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+		}
+	}
+	return truncateBuildLog(buf.Bytes())
+}
+
+// truncateBuildLog drops whole lines from the start of out until it fits in
+// maxBuildLogBytes, noting how much was dropped.
+func truncateBuildLog(out []byte) []byte {
+	if len(out) <= maxBuildLogBytes {
+		return out
+	}
+	cut := len(out) - maxBuildLogBytes
+	if i := bytes.IndexByte(out[cut:], '\n'); i >= 0 {
+		cut += i + 1
+	} else {
+		for cut < len(out) && !utf8.RuneStart(out[cut]) {
+			cut++
+		}
+	}
+	note := fmt.Sprintf("[flowcd] log truncated: first %d bytes omitted\n", cut)
+	return append([]byte(note), out[cut:]...)
+}
+
+// chunkBuildLog splits out into pieces of at most size bytes, breaking at
+// line boundaries where possible and never inside a UTF-8 character.
+func chunkBuildLog(out []byte, size int) [][]byte {
+	var chunks [][]byte
+	for len(out) > 0 {
+		n := min(size, len(out))
+		if n < len(out) {
+			if i := bytes.LastIndexByte(out[:n], '\n'); i > 0 {
+				n = i + 1
+			} else {
+				// A line longer than size: back up to the start of the
+				// character that straddles the limit.
+				for n > 1 && !utf8.RuneStart(out[n]) {
+					n--
+				}
+			}
+		}
+		chunks = append(chunks, out[:n])
+		out = out[n:]
+	}
+	return chunks
+}
+
+// storeBuildLogs persists a run's build output as ConfigMaps owned by the
+// PipelineRun, so the logs outlive the build Job and are garbage collected
+// with the run. It returns the number of chunks written.
+func (r *PipelineReconciler) storeBuildLogs(ctx context.Context, pipeline *platformv1alpha1.Pipeline, run *platformv1alpha1.PipelineRun, pod *corev1.Pod) (int32, error) {
+	chunks := chunkBuildLog(r.collectBuildLogs(ctx, pod), buildLogChunkBytes)
+	for i, chunk := range chunks {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      buildLogConfigMapName(run, i),
+				Namespace: run.Namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/component":  "build-logs",
+					"app.kubernetes.io/managed-by": "flowcd-operator",
+					pipelineLabel:                  pipeline.Name,
+					pipelineRunLabel:               run.Name,
+					buildLogChunkLabel:             strconv.Itoa(i),
+				},
+			},
+			Data: map[string]string{buildLogKey: string(chunk)},
+		}
+		if err := controllerutil.SetControllerReference(run, cm, r.Scheme); err != nil {
+			return 0, fmt.Errorf("set owner reference on build logs: %w", err)
+		}
+
+		existing := &corev1.ConfigMap{}
+		err := r.Get(ctx, types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, existing)
+		if apierrors.IsNotFound(err) {
+			if err := r.Create(ctx, cm); err != nil {
+				return 0, fmt.Errorf("create build log ConfigMap: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		patch := client.MergeFrom(existing.DeepCopy())
+		existing.Data = cm.Data
+		if err := r.Patch(ctx, existing, patch); err != nil {
+			return 0, fmt.Errorf("update build log ConfigMap: %w", err)
+		}
+	}
+	return int32(len(chunks)), nil
+}
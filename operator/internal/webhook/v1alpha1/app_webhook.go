@@ -4,24 +4,31 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"slices"
+	"sort"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+	"github.com/nimi-io/FlowCD/operator/internal/controller"
 )
 
 var appWebhookLog = logf.Log.WithName("app-webhook")
 
 // SetupAppWebhookWithManager registers the defaulting and validating webhooks
-// for the App kind.
-func SetupAppWebhookWithManager(mgr ctrl.Manager) error {
+// for the App kind. baseDomain is the operator's base domain for default
+// hosts, if any.
+func SetupAppWebhookWithManager(mgr ctrl.Manager, baseDomain string) error {
 	return ctrl.NewWebhookManagedBy(mgr, &platformv1alpha1.App{}).
 		WithDefaulter(&AppDefaulter{}).
-		WithValidator(&AppValidator{}).
+		WithValidator(&AppValidator{BaseDomain: baseDomain, Client: mgr.GetClient()}).
 		Complete()
 }
 
@@ -45,28 +52,108 @@ func (d *AppDefaulter) Default(_ context.Context, app *platformv1alpha1.App) err
 		one := int32(1)
 		app.Spec.Replicas = &one
 	}
+	if app.Spec.Strategy != nil && app.Spec.Strategy.Type == "" {
+		app.Spec.Strategy.Type = platformv1alpha1.AppStrategyRollingUpdate
+	}
+	if tls := app.Spec.TLS; tls != nil && tls.Issuer != nil && tls.Issuer.Kind == "" {
+		tls.Issuer.Kind = platformv1alpha1.AppIssuerKindClusterIssuer
+	}
+	if app.Spec.Resources == nil {
+		app.Spec.Resources = &platformv1alpha1.AppResources{}
+	}
+	defaultResourceRequests(app.Spec.Resources)
 	return nil
 }
 
+// defaultRequests are the container requests applied when an App does not
+// set them.
+var defaultRequests = corev1.ResourceList{
+	corev1.ResourceCPU:    resource.MustParse("100m"),
+	corev1.ResourceMemory: resource.MustParse("128Mi"),
+}
+
+// defaultResourceRequests fills in missing cpu and memory requests, never
+// exceeding the corresponding limit.
+func defaultResourceRequests(res *platformv1alpha1.AppResources) {
+	if res.Requests == nil {
+		res.Requests = corev1.ResourceList{}
+	}
+	for name, def := range defaultRequests {
+		if _, ok := res.Requests[name]; ok {
+			continue
+		}
+		if limit, ok := res.Limits[name]; ok && limit.Cmp(def) < 0 {
+			def = limit
+		}
+		res.Requests[name] = def
+	}
+}
+
 // ─── Validator ───────────────────────────────────────────────────────────────
 
 // AppValidator validates App resources on create and update.
 // +kubebuilder:webhook:path=/validate-platform-flowcd-io-v1alpha1-app,mutating=false,failurePolicy=fail,sideEffects=None,groups=platform.flowcd.io,resources=apps,verbs=create;update,versions=v1alpha1,name=vapp.kb.io,admissionReviewVersions=v1
-type AppValidator struct{}
+type AppValidator struct {
+	// BaseDomain gives every App a default host, so Apps need no custom
+	// domain to be reachable.
+	BaseDomain string
+	// Client looks up other Apps so that no two Apps claim the same domain.
+	// Without it that check is skipped.
+	Client client.Reader
+}
 
 var _ admission.Validator[*platformv1alpha1.App] = &AppValidator{}
 
-func (v *AppValidator) ValidateCreate(_ context.Context, app *platformv1alpha1.App) (admission.Warnings, error) {
+func (v *AppValidator) ValidateCreate(ctx context.Context, app *platformv1alpha1.App) (admission.Warnings, error) {
 	appWebhookLog.Info("Validating App create", "name", app.Name)
-	return nil, validateApp(app)
+	return volumeWarnings(app), v.validate(ctx, app)
 }
 
-func (v *AppValidator) ValidateUpdate(_ context.Context, oldApp, newApp *platformv1alpha1.App) (admission.Warnings, error) {
+func (v *AppValidator) ValidateUpdate(ctx context.Context, oldApp, newApp *platformv1alpha1.App) (admission.Warnings, error) {
 	appWebhookLog.Info("Validating App update", "name", newApp.Name)
 	if oldApp.Spec.RepoUrl != "" && newApp.Spec.RepoUrl != oldApp.Spec.RepoUrl {
 		return nil, fmt.Errorf("spec.repoUrl is immutable")
 	}
-	return nil, validateApp(newApp)
+	return volumeWarnings(newApp), v.validate(ctx, newApp)
+}
+
+func (v *AppValidator) validate(ctx context.Context, app *platformv1alpha1.App) error {
+	if err := validateApp(app, v.BaseDomain); err != nil {
+		return err
+	}
+	return v.validateUniqueDomains(ctx, app)
+}
+
+// validateUniqueDomains refuses domains already served by another App, either
+// listed in its spec.domains or as its default host. The activator and the
+// routing objects cannot tell two such Apps apart.
+func (v *AppValidator) validateUniqueDomains(ctx context.Context, app *platformv1alpha1.App) error {
+	if v.Client == nil || len(app.Spec.Domains) == 0 {
+		return nil
+	}
+	apps := &platformv1alpha1.AppList{}
+	if err := v.Client.List(ctx, apps); err != nil {
+		return fmt.Errorf("list Apps to check spec.domains: %w", err)
+	}
+	var errs []string
+	for _, other := range apps.Items {
+		if other.Name == app.Name && other.Namespace == app.Namespace {
+			continue
+		}
+		claimed := other.Spec.Domains
+		if host := controller.DefaultHost(&other, v.BaseDomain); host != "" {
+			claimed = append(slices.Clone(claimed), host)
+		}
+		for _, d := range app.Spec.Domains {
+			if slices.Contains(claimed, d) {
+				errs = append(errs, fmt.Sprintf("spec.domains: %q is already used by App %s/%s", d, other.Namespace, other.Name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("App %q failed validation: %s", app.Name, strings.Join(errs, "; "))
+	}
+	return nil
 }
 
 func (v *AppValidator) ValidateDelete(_ context.Context, _ *platformv1alpha1.App) (admission.Warnings, error) {
@@ -75,7 +162,7 @@ func (v *AppValidator) ValidateDelete(_ context.Context, _ *platformv1alpha1.App
 
 // ─── shared validation logic ─────────────────────────────────────────────────
 
-func validateApp(app *platformv1alpha1.App) error {
+func validateApp(app *platformv1alpha1.App, baseDomain string) error {
 	var errs []string
 	if app.Spec.RepoUrl != "" {
 		if _, err := url.ParseRequestURI(app.Spec.RepoUrl); err != nil {
@@ -90,8 +177,266 @@ func validateApp(app *platformv1alpha1.App) error {
 	if app.Spec.Replicas != nil && *app.Spec.Replicas < 0 {
 		errs = append(errs, "spec.replicas must be >= 0")
 	}
+	if as := app.Spec.Autoscaling; as != nil {
+		if as.MaxReplicas < 1 {
+			errs = append(errs, "spec.autoscaling.maxReplicas must be >= 1")
+		}
+		if as.MinReplicas != nil && *as.MinReplicas > as.MaxReplicas {
+			errs = append(errs, "spec.autoscaling.minReplicas must not exceed spec.autoscaling.maxReplicas")
+		}
+	}
+	if idle := app.Spec.Idle; idle != nil {
+		if idle.AfterMinutes < 1 {
+			errs = append(errs, "spec.idle.afterMinutes must be >= 1")
+		}
+		if len(app.Spec.Domains) == 0 && baseDomain == "" {
+			errs = append(errs, "spec.idle requires at least one entry in spec.domains")
+		}
+	}
+	if tls := app.Spec.TLS; tls != nil {
+		if (tls.Issuer == nil) == (tls.SecretName == "") {
+			errs = append(errs, "spec.tls must set exactly one of issuer and secretName")
+		}
+		if tls.Issuer != nil && tls.Issuer.Name == "" {
+			errs = append(errs, "spec.tls.issuer.name is required")
+		}
+		if len(app.Spec.Domains) == 0 {
+			errs = append(errs, "spec.tls requires at least one entry in spec.domains")
+		}
+	}
+	if routing := app.Spec.Routing; routing != nil {
+		errs = append(errs, validateRouting(app, routing)...)
+	}
+	if res := app.Spec.Resources; res != nil {
+		errs = append(errs, validateResources(res)...)
+	}
+	if p := app.Spec.Promotion; p != nil && p.From.Name == app.Name &&
+		(p.From.Namespace == "" || p.From.Namespace == app.Namespace) {
+		errs = append(errs, "spec.promotion.from must name another App")
+	}
+	errs = append(errs, validateContainers(app)...)
+	errs = append(errs, validateVolumes(app)...)
+	if hc := app.Spec.HealthCheck; hc != nil {
+		if hc.Path != "" && !strings.HasPrefix(hc.Path, "/") {
+			errs = append(errs, "spec.healthCheck.path must start with /")
+		}
+		if hc.Path == "" && len(hc.Headers) > 0 {
+			errs = append(errs, "spec.healthCheck.headers requires spec.healthCheck.path")
+		}
+		if p := hc.Liveness; p != nil && p.SuccessThreshold != nil && *p.SuccessThreshold != 1 {
+			errs = append(errs, "spec.healthCheck.liveness.successThreshold must be 1")
+		}
+		if p := hc.Startup; p != nil && p.SuccessThreshold != nil && *p.SuccessThreshold != 1 {
+			errs = append(errs, "spec.healthCheck.startup.successThreshold must be 1")
+		}
+	}
+	if s := app.Spec.Strategy; s != nil && s.Type == platformv1alpha1.AppStrategyCanary {
+		if s.Canary == nil || len(s.Canary.Steps) == 0 {
+			errs = append(errs, "spec.strategy.canary.steps is required for the Canary strategy")
+		} else {
+			prev := int32(0)
+			for i, step := range s.Canary.Steps {
+				if step.Weight < 1 || step.Weight > 100 {
+					errs = append(errs, fmt.Sprintf("spec.strategy.canary.steps[%d].weight must be between 1 and 100", i))
+				} else if step.Weight <= prev {
+					errs = append(errs, fmt.Sprintf("spec.strategy.canary.steps[%d].weight must be greater than the previous step's", i))
+				}
+				prev = step.Weight
+			}
+		}
+	}
 	if len(errs) > 0 {
 		return fmt.Errorf("App %q failed validation: %s", app.Name, strings.Join(errs, "; "))
 	}
 	return nil
 }
+
+// validateResources allows only cpu and memory and requires every request to
+// fit within its limit.
+func validateResources(res *platformv1alpha1.AppResources) []string {
+	var errs []string
+	for field, list := range map[string]corev1.ResourceList{"requests": res.Requests, "limits": res.Limits} {
+		for name, q := range list {
+			if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+				errs = append(errs, fmt.Sprintf("spec.resources.%s: unsupported resource %q (only cpu and memory)", field, name))
+			} else if q.Sign() < 0 {
+				errs = append(errs, fmt.Sprintf("spec.resources.%s.%s must not be negative", field, name))
+			}
+		}
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		req, hasReq := res.Requests[name]
+		limit, hasLimit := res.Limits[name]
+		if hasReq && hasLimit && req.Cmp(limit) > 0 {
+			errs = append(errs, fmt.Sprintf("spec.resources.requests.%s (%s) must not exceed spec.resources.limits.%s (%s)", name, req.String(), name, limit.String()))
+		}
+	}
+	sort.Strings(errs)
+	return errs
+}
+
+// validateVolumes checks that every volume has at most one source, that
+// claims request a size and that no container mounts two volumes at the
+// same path.
+func validateVolumes(app *platformv1alpha1.App) []string {
+	var errs []string
+	paths := map[string]string{}
+	for i, v := range app.Spec.Volumes {
+		sources := 0
+		for _, set := range []bool{v.EmptyDir != nil, v.PersistentVolumeClaim != nil, v.ConfigMap != nil, v.Secret != nil} {
+			if set {
+				sources++
+			}
+		}
+		if sources > 1 {
+			errs = append(errs, fmt.Sprintf("spec.volumes[%d]: only one of emptyDir, persistentVolumeClaim, configMap and secret may be set", i))
+		}
+		if pvc := v.PersistentVolumeClaim; pvc != nil && pvc.Size.Sign() <= 0 {
+			errs = append(errs, fmt.Sprintf("spec.volumes[%d].persistentVolumeClaim.size must be positive", i))
+		}
+		var items []platformv1alpha1.AppKeyToPath
+		if v.ConfigMap != nil {
+			items = v.ConfigMap.Items
+		} else if v.Secret != nil {
+			items = v.Secret.Items
+		}
+		for _, item := range items {
+			if strings.HasPrefix(item.Path, "/") || slices.Contains(strings.Split(item.Path, "/"), "..") {
+				errs = append(errs, fmt.Sprintf("spec.volumes[%d].items: path %q must be relative and must not contain '..'", i, item.Path))
+			}
+		}
+		if v.MountPath == "" {
+			continue
+		}
+		if other, ok := paths[v.MountPath]; ok {
+			errs = append(errs, fmt.Sprintf("spec.volumes[%d].mountPath %q is already used by volume %q", i, v.MountPath, other))
+		}
+		paths[v.MountPath] = v.Name
+	}
+	for field, containers := range map[string][]platformv1alpha1.AppContainer{
+		"sidecars":       app.Spec.Sidecars,
+		"initContainers": app.Spec.InitContainers,
+	} {
+		for i, c := range containers {
+			paths := map[string]string{}
+			for _, m := range c.VolumeMounts {
+				if other, ok := paths[m.MountPath]; ok {
+					errs = append(errs, fmt.Sprintf("spec.%s[%d].volumeMounts: mountPath %q is already used by volume %q", field, i, m.MountPath, other))
+				}
+				paths[m.MountPath] = m.Name
+			}
+		}
+	}
+	sort.Strings(errs)
+	return errs
+}
+
+// volumeWarnings flags ReadWriteOnce claims that pods on different nodes
+// would have to share.
+func volumeWarnings(app *platformv1alpha1.App) admission.Warnings {
+	shared := (app.Spec.Replicas != nil && *app.Spec.Replicas > 1) || app.Spec.Autoscaling != nil ||
+		(app.Spec.Strategy != nil && app.Spec.Strategy.Type != "" && app.Spec.Strategy.Type != platformv1alpha1.AppStrategyRollingUpdate)
+	if !shared {
+		return nil
+	}
+	var warnings admission.Warnings
+	for i, v := range app.Spec.Volumes {
+		pvc := v.PersistentVolumeClaim
+		if pvc == nil || slices.Contains(pvc.AccessModes, corev1.ReadWriteMany) || slices.Contains(pvc.AccessModes, corev1.ReadOnlyMany) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("spec.volumes[%d]: a ReadWriteOnce claim can only be mounted on one node, but this App may run several pods", i))
+	}
+	return warnings
+}
+
+// validateRouting checks that paths name the App's own domains and that
+// redirects do not shadow them.
+func validateRouting(app *platformv1alpha1.App, routing *platformv1alpha1.AppRouting) []string {
+	var errs []string
+	for i, p := range routing.Paths {
+		if !strings.HasPrefix(p.Path, "/") {
+			errs = append(errs, fmt.Sprintf("spec.routing.paths[%d].path must start with /", i))
+		}
+		for _, d := range p.Domains {
+			if !slices.Contains(app.Spec.Domains, d) {
+				errs = append(errs, fmt.Sprintf("spec.routing.paths[%d].domains: %q is not in spec.domains", i, d))
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for i, rd := range routing.Redirects {
+		for field, host := range []string{rd.From, rd.To} {
+			if msgs := validation.IsDNS1123Subdomain(host); len(msgs) > 0 {
+				errs = append(errs, fmt.Sprintf("spec.routing.redirects[%d].%s: %q invalid: %s", i, []string{"from", "to"}[field], host, strings.Join(msgs, ", ")))
+			}
+		}
+		if slices.Contains(app.Spec.Domains, rd.From) {
+			errs = append(errs, fmt.Sprintf("spec.routing.redirects[%d].from %q is also in spec.domains", i, rd.From))
+		}
+		if seen[rd.From] {
+			errs = append(errs, fmt.Sprintf("spec.routing.redirects[%d].from %q is redirected twice", i, rd.From))
+		}
+		seen[rd.From] = true
+	}
+	if gw := routing.Gateway; gw != nil && routing.Backend == platformv1alpha1.AppRoutingGateway &&
+		routing.ForceHTTPS && gw.HTTPSectionName == "" {
+		errs = append(errs, "spec.routing.forceHTTPS requires spec.routing.gateway.httpSectionName")
+	}
+	return errs
+}
+
+// validateContainers checks that container names are unique within the pod,
+// that mounts name declared volumes and that hook Job names stay valid.
+func validateContainers(app *platformv1alpha1.App) []string {
+	var errs []string
+	volumes := map[string]bool{}
+	for _, v := range app.Spec.Volumes {
+		volumes[v.Name] = true
+	}
+	names := map[string]bool{app.Name: true}
+	// The main container listens on 8080 when spec.port is unset.
+	port := app.Spec.Port
+	if port == 0 {
+		port = 8080
+	}
+	ports := map[int32]bool{port: true}
+	for field, containers := range map[string][]platformv1alpha1.AppContainer{
+		"sidecars":       app.Spec.Sidecars,
+		"initContainers": app.Spec.InitContainers,
+	} {
+		for i, c := range containers {
+			if names[c.Name] {
+				errs = append(errs, fmt.Sprintf("spec.%s[%d].name %q is already used by another container", field, i, c.Name))
+			}
+			names[c.Name] = true
+			for _, m := range c.VolumeMounts {
+				if !volumes[m.Name] {
+					errs = append(errs, fmt.Sprintf("spec.%s[%d].volumeMounts: %q is not in spec.volumes", field, i, m.Name))
+				}
+			}
+			if field != "sidecars" {
+				continue
+			}
+			for _, p := range c.Ports {
+				if ports[p.ContainerPort] {
+					errs = append(errs, fmt.Sprintf("spec.sidecars[%d].ports: port %d is already used in the pod", i, p.ContainerPort))
+				}
+				ports[p.ContainerPort] = true
+			}
+		}
+	}
+	if hooks := app.Spec.Hooks; hooks != nil {
+		for stage, list := range map[string][]platformv1alpha1.AppHook{"pre": hooks.PreDeploy, "post": hooks.PostDeploy} {
+			for i, h := range list {
+				// Jobs are named <app>-<stage>-<hook>-<8 hex digits>.
+				name := fmt.Sprintf("%s-%s-%s-00000000", app.Name, stage, h.Name)
+				if len(name) > validation.DNS1123LabelMaxLength {
+					errs = append(errs, fmt.Sprintf("spec.hooks.%sDeploy[%d].name %q is too long for an App named %q", stage, i, h.Name, app.Name))
+				}
+			}
+		}
+	}
+	sort.Strings(errs)
+	return errs
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	platformv1alpha1 "github.com/nimi-io/FlowCD/operator/api/v1alpha1"
+)
+
+func newValidator(t *testing.T, apps ...client.Object) *AppValidator {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := platformv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return &AppValidator{
+		BaseDomain: "apps.example.com",
+		Client:     fake.NewClientBuilder().WithScheme(scheme).WithObjects(apps...).Build(),
+	}
+}
+
+func testApp(namespace, name string, domains ...string) *platformv1alpha1.App {
+	return &platformv1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: platformv1alpha1.AppSpec{
+			RepoUrl: "https://github.com/acme/" + name,
+			Domains: domains,
+		},
+	}
+}
+
+func TestValidateRejectsDuplicateDomains(t *testing.T) {
+	existing := testApp("default", "web", "web.example.com")
+	v := newValidator(t, existing)
+
+	_, err := v.ValidateCreate(context.Background(), testApp("staging", "web", "web.example.com"))
+	if err == nil || !strings.Contains(err.Error(), "already used by App default/web") {
+		t.Errorf("create with a taken domain: err = %v, want a duplicate domain error", err)
+	}
+	_, err = v.ValidateCreate(context.Background(), testApp("staging", "web", "web.default.apps.example.com"))
+	if err == nil {
+		t.Error("create with another App's default host succeeded")
+	}
+	if _, err := v.ValidateCreate(context.Background(), testApp("staging", "web", "staging.example.com")); err != nil {
+		t.Errorf("create with a free domain: %v", err)
+	}
+
+	// An App does not conflict with itself on update.
+	updated := existing.DeepCopy()
+	updated.Spec.Domains = append(updated.Spec.Domains, "www.example.com")
+	if _, err := v.ValidateUpdate(context.Background(), existing, updated); err != nil {
+		t.Errorf("update keeping its own domain: %v", err)
+	}
+}
+
+func TestValidateSidecarPortClashesWithDefaultPort(t *testing.T) {
+	app := testApp("default", "web")
+	app.Spec.Sidecars = []platformv1alpha1.AppContainer{{
+		Name:  "proxy",
+		Image: "envoyproxy/envoy:v1.30",
+		Ports: []platformv1alpha1.AppContainerPort{{ContainerPort: 8080}},
+	}}
+	if errs := validateContainers(app); len(errs) == 0 {
+		t.Error("sidecar on the default app port was accepted with spec.port unset")
+	}
+	app.Spec.Port = 3000
+	if errs := validateContainers(app); len(errs) != 0 {
+		t.Errorf("sidecar on 8080 with spec.port 3000: %v", errs)
+	}
+}